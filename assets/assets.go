@@ -1,4 +1,5 @@
 //go:generate -command asset go run asset.go
+//go:generate asset -var bsd_0 0bsd.txt
 //go:generate asset afl_3.0.txt
 //go:generate asset agpl_3.0.txt
 //go:generate asset apache_2.0.txt
@@ -14,6 +15,8 @@
 //go:generate asset lgpl_2.1.txt
 //go:generate asset lgpl_3.0.txt
 //go:generate asset mit.txt
+//go:generate asset mit_de.txt
+//go:generate asset mit_fr.txt
 //go:generate asset mpl_2.0.txt
 //go:generate asset ms_pl.txt
 //go:generate asset ms_rl.txt
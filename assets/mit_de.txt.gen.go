@@ -0,0 +1,7 @@
+// AUTOMATICALLY GENERATED FILE. DO NOT EDIT.
+
+package assets
+
+var mit_de = txt(asset{Name: "mit_de.txt", Content: "" +
+	"---\ntitle: MIT License (German)\nspdx-id: MIT\nlanguage: de\nsource: http://opensource.org/licenses/MIT\n\ndescription: An unofficial German translation of the MIT license. The English text remains the legally binding one.\n\nhow: Create a text file (typically named LICENSE or LICENSE.txt) in the root of your source code and copy the text of the license into the file. Replace [year] with the current year and [fullname] with the name (or names) of the copyright holders.\n\nrequired:\n  - include-copyright\n\npermitted:\n  - commercial-use\n  - modifications\n  - distribution\n  - sublicense\n  - private-use\n\nforbidden:\n  - no-liability\n\n---\n\nDie MIT Lizenz (MIT)\n\nCopyright (c) [year] [fullname]\n\nHiermit wird unentgeltlich jeder Person, die eine Kopie der Software und der\nzugeh\u00f6rigen Dokumentationen (die \"Software\") erh\u00e4lt, die Erlaubnis erteilt,\nsie uneingeschr\u00e4nkt zu nutzen, inklusive und ohne Ausnahme mit dem Recht, sie\nzu verwenden, zu kopieren, zu ver\u00e4ndern, zusammenzuf\u00fcgen, zu ver\u00f6ffentlichen,\nzu verbreiten, zu unterlizenzieren und/oder zu verkaufen, und Personen, denen\ndiese Software \u00fcberlassen wird, diese Rechte zu verschaffen, unter den\nfolgenden Bedingungen:\n\nDer obige Urheberrechtsvermerk und dieser Erlaubnisvermerk sind in allen\nKopien oder Teilkopien der Software beizulegen.\n\nDIE SOFTWARE WIRD OHNE JEDE AUSDR\u00dcCKLICHE ODER IMPLIZIERTE GARANTIE\nBEREITGESTELLT, EINSCHLIESSLICH DER GARANTIE ZUR BENUTZUNG F\u00dcR DEN\nVORGESEHENEN ODER EINEM BESTIMMTEN ZWECK SOWIE JEGLICHER RECHTSVERLETZUNG,\nJEDOCH NICHT DARAUF BESCHR\u00c4NKT. IN KEINEM FALL SIND DIE AUTOREN ODER\nCOPYRIGHTINHABER F\u00dcR JEGLICHEN SCHADEN ODER SONSTIGE ANSPR\u00dcCHE HAFTBAR ZU\nMACHEN, OB INFOLGE DER ERF\u00dcLLUNG EINES VERTRAGES, EINES DELIKTES ODER ANDERS\nIM ZUSAMMENHANG MIT DER SOFTWARE ODER SONSTIGER VERWENDUNG DER SOFTWARE\nENTSTANDEN.\n" +
+	"", etag: `"SqBZ04Rai/s="`})
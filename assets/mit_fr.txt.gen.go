@@ -0,0 +1,7 @@
+// AUTOMATICALLY GENERATED FILE. DO NOT EDIT.
+
+package assets
+
+var mit_fr = txt(asset{Name: "mit_fr.txt", Content: "" +
+	"---\ntitle: MIT License (French)\nspdx-id: MIT\nlanguage: fr\nsource: http://opensource.org/licenses/MIT\n\ndescription: An unofficial French translation of the MIT license. The English text remains the legally binding one.\n\nhow: Create a text file (typically named LICENSE or LICENSE.txt) in the root of your source code and copy the text of the license into the file. Replace [year] with the current year and [fullname] with the name (or names) of the copyright holders.\n\nrequired:\n  - include-copyright\n\npermitted:\n  - commercial-use\n  - modifications\n  - distribution\n  - sublicense\n  - private-use\n\nforbidden:\n  - no-liability\n\n---\n\nLicence MIT\n\nCopyright (c) [year] [fullname]\n\nL'autorisation est accord\u00e9e, gracieusement, \u00e0 toute personne acqu\u00e9rant une\ncopie de ce logiciel et des fichiers de documentation associ\u00e9s (le\n\u00ab Logiciel \u00bb), de commercialiser le Logiciel sans restriction, notamment les\ndroits d'utiliser, de copier, de modifier, de fusionner, de publier, de\ndistribuer, de sous-licencier et/ou de vendre des copies du Logiciel, ainsi que\nd'autoriser les personnes auxquelles le Logiciel est fourni \u00e0 le faire, sous\nr\u00e9serve des conditions suivantes :\n\nLa d\u00e9claration de copyright ci-dessus et la pr\u00e9sente autorisation doivent \u00eatre\nincluses dans toutes copies ou parties substantielles du Logiciel.\n\nLE LOGICIEL EST FOURNI \u00ab TEL QUEL \u00bb, SANS GARANTIE D'AUCUNE SORTE, EXPLICITE\nOU IMPLICITE, NOTAMMENT SANS GARANTIE DE QUALIT\u00c9 MARCHANDE, D'AD\u00c9QUATION \u00c0 UN\nUSAGE PARTICULIER ET D'ABSENCE DE CONTREFA\u00c7ON. EN AUCUN CAS, LES AUTEURS OU\nTITULAIRES DU DROIT D'AUTEUR NE SERONT RESPONSABLES DE TOUT DOMMAGE,\nR\u00c9CLAMATION OU AUTRE RESPONSABILIT\u00c9, QUE CE SOIT DANS LE CADRE D'UN CONTRAT,\nD'UN D\u00c9LIT OU AUTRE, EN PROVENANCE DE, CONS\u00c9CUTIF \u00c0 OU EN RELATION AVEC LE\nLOGICIEL OU SON UTILISATION, OU AVEC D'AUTRES \u00c9L\u00c9MENTS DU LOGICIEL.\n" +
+	"", etag: `"LlvNQbZubeE="`})
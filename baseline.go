@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+)
+
+// Baseline maps package import paths to their license hash, see License.Hash,
+// to detect license changes between scans. Packages without license have an
+// empty hash.
+type Baseline map[string]string
+
+// loadBaseline reads a JSON baseline file.
+func loadBaseline(path string) (Baseline, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	b := Baseline{}
+	err = json.Unmarshal(data, &b)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse baseline %s: %s", path, err)
+	}
+	return b, nil
+}
+
+// makeBaseline returns the baseline of licenses.
+func makeBaseline(licenses []License) Baseline {
+	b := Baseline{}
+	for _, l := range licenses {
+		b[l.Package] = l.Hash
+	}
+	return b
+}
+
+// writeBaseline writes b as JSON at path.
+func writeBaseline(path string, b Baseline) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// applyBaseline sets the Changed field of licenses recorded in b with a
+// different hash, and returns their import paths, sorted.
+func applyBaseline(licenses []License, b Baseline) []string {
+	changed := []string{}
+	for i := range licenses {
+		l := &licenses[i]
+		hash, ok := b[l.Package]
+		l.Changed = ok && hash != l.Hash
+		if l.Changed {
+			changed = append(changed, l.Package)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestBaseline(t *testing.T) {
+	dir, err := ioutil.TempDir("", "licenses")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "baseline.json")
+
+	licenses := []License{
+		{Package: "colors/red", Hash: "aaa"},
+		{Package: "colors/blue", Hash: "bbb"},
+		{Package: "colors/green"},
+	}
+	err = writeBaseline(path, makeBaseline(licenses))
+	if err != nil {
+		t.Fatal(err)
+	}
+	baseline, err := loadBaseline(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scanned := []License{
+		{Package: "colors/red", Hash: "aaa"},
+		{Package: "colors/blue", Hash: "ccc"},
+		{Package: "colors/green", Hash: "ddd"},
+		{Package: "colors/yellow", Hash: "eee"},
+	}
+	changed := applyBaseline(scanned, baseline)
+	if !reflect.DeepEqual(changed, []string{"colors/blue", "colors/green"}) {
+		t.Fatalf("unexpected changed packages: %v", changed)
+	}
+	if scanned[0].Changed || !scanned[1].Changed || scanned[3].Changed {
+		t.Fatalf("unexpected changed licenses: %+v", scanned)
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"debug/buildinfo"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"unicode"
+)
+
+// errSourceUnavailable reports binary dependencies missing from the module
+// cache.
+const errSourceUnavailable = "source unavailable"
+
+// moduleCachePath returns the module cache directory, from GOMODCACHE or the
+// first GOPATH entry, as the go command does, without running it.
+func moduleCachePath() string {
+	if dir := os.Getenv("GOMODCACHE"); dir != "" {
+		return dir
+	}
+	gopath := filepath.SplitList(os.Getenv("GOPATH"))
+	if len(gopath) > 0 && gopath[0] != "" {
+		return filepath.Join(gopath[0], "pkg", "mod")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, "go", "pkg", "mod")
+}
+
+// escapeModulePath escapes module paths and versions like the module cache
+// does, upper case letters becoming "!" followed by their lower case.
+func escapeModulePath(path string) string {
+	escaped := strings.Builder{}
+	for _, r := range path {
+		if unicode.IsUpper(r) {
+			escaped.WriteByte('!')
+			r = unicode.ToLower(r)
+		}
+		escaped.WriteRune(r)
+	}
+	return escaped.String()
+}
+
+// cachedModuleDir returns the directory of module path at version in module
+// cache modcache, or an empty string if it is not there.
+func cachedModuleDir(modcache, path, version string) string {
+	if modcache == "" || version == "" {
+		return ""
+	}
+	dir := filepath.Join(modcache, filepath.FromSlash(escapeModulePath(path))+
+		"@"+escapeModulePath(version))
+	fi, err := os.Stat(dir)
+	if err != nil || !fi.IsDir() {
+		return ""
+	}
+	return dir
+}
+
+// binaryModules returns the dependencies recorded in the build information of
+// a Go binary, located in module cache modcache. Dependencies absent from the
+// cache, or replaced by local directories, are reported with
+// errSourceUnavailable.
+func binaryModules(info *debug.BuildInfo, modcache string) []ManifestModule {
+	modules := []ManifestModule{}
+	for _, dep := range info.Deps {
+		m := ManifestModule{
+			Path:    dep.Path,
+			Version: dep.Version,
+			Dir:     cachedModuleDir(modcache, dep.Path, dep.Version),
+		}
+		available := m.Dir != ""
+		if r := dep.Replace; r != nil {
+			m.Replace = &ManifestModule{
+				Path:    r.Path,
+				Version: r.Version,
+				Dir:     cachedModuleDir(modcache, r.Path, r.Version),
+			}
+			available = m.Replace.Dir != ""
+		}
+		if !available {
+			m.Error = errSourceUnavailable
+		}
+		modules = append(modules, m)
+	}
+	return modules
+}
+
+// listBinaryLicenses returns the licenses of the module dependencies of the
+// Go binary at path, found in the module cache, see binaryModules.
+func listBinaryLicenses(path string, opts Options) ([]License, error) {
+	info, err := buildinfo.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return listModulesLicenses(binaryModules(info, moduleCachePath()), opts)
+}
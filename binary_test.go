@@ -0,0 +1,48 @@
+package main
+
+import (
+	"path/filepath"
+	"runtime/debug"
+	"testing"
+)
+
+func TestEscapeModulePath(t *testing.T) {
+	if got := escapeModulePath("github.com/BurntSushi/toml"); got != "github.com/!burnt!sushi/toml" {
+		t.Fatalf("unexpected escaped path: %s", got)
+	}
+}
+
+func TestBinaryLicenses(t *testing.T) {
+	modcache, err := filepath.Abs(filepath.Join("testdata", "modcache"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := &debug.BuildInfo{
+		Deps: []*debug.Module{
+			{Path: "example.com/lib", Version: "v1.2.0"},
+			{Path: "example.com/gone", Version: "v0.1.0"},
+			{Path: "example.com/fork", Version: "v1.0.0",
+				Replace: &debug.Module{Path: "../fork"}},
+		},
+	}
+	modules := binaryModules(info, modcache)
+	licenses, err := listModulesLicenses(modules, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(licenses) != 3 {
+		t.Fatalf("unexpected licenses: %+v", licenses)
+	}
+	if l := licenses[0]; l.Title() != "MIT License" || l.Version != "v1.2.0" ||
+		l.Path != "example.com/lib@v1.2.0/LICENSE" {
+		t.Fatalf("unexpected cached module license: %+v", l)
+	}
+	for _, l := range licenses[1:] {
+		if l.Err != errSourceUnavailable {
+			t.Fatalf("unexpected unavailable module license: %+v", l)
+		}
+	}
+	if licenses[2].Replaced != "../fork" {
+		t.Fatalf("unexpected replaced module license: %+v", licenses[2])
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+// License obligation categories, from the least to the most restrictive.
+const (
+	CategoryPublicDomain   = "public-domain"
+	CategoryPermissive     = "permissive"
+	CategoryWeakCopyleft   = "weak-copyleft"
+	CategoryStrongCopyleft = "strong-copyleft"
+	CategoryUnknown        = "unknown"
+)
+
+// categories maps templates SPDX identifiers to their obligation category.
+var categories = map[string]string{
+	"0BSD":               CategoryPermissive,
+	"AFL-3.0":            CategoryPermissive,
+	"AGPL-3.0":           CategoryStrongCopyleft,
+	"Apache-2.0":         CategoryPermissive,
+	"Artistic-2.0":       CategoryPermissive,
+	"BSD-2-Clause":       CategoryPermissive,
+	"BSD-3-Clause":       CategoryPermissive,
+	"BSD-3-Clause-Clear": CategoryPermissive,
+	"CC0-1.0":            CategoryPublicDomain,
+	"EPL-1.0":            CategoryWeakCopyleft,
+	"GPL-2.0":            CategoryStrongCopyleft,
+	"GPL-3.0":            CategoryStrongCopyleft,
+	"ISC":                CategoryPermissive,
+	"LGPL-2.1":           CategoryWeakCopyleft,
+	"LGPL-3.0":           CategoryWeakCopyleft,
+	"MIT":                CategoryPermissive,
+	"MPL-2.0":            CategoryWeakCopyleft,
+	"MS-PL":              CategoryPermissive,
+	"MS-RL":              CategoryWeakCopyleft,
+	"OFL-1.1":            CategoryWeakCopyleft,
+	"OSL-3.0":            CategoryStrongCopyleft,
+	"Unlicense":          CategoryPublicDomain,
+	"WTFPL":              CategoryPermissive,
+}
+
+// obligationNotes summarizes, by SPDX identifier, the active obligations of
+// licenses which are neither permissive nor public domain.
+var obligationNotes = map[string]string{
+	"AGPL-3.0": "disclose source, including to network users; keep notices; license derivatives under AGPL-3.0",
+	"EPL-1.0":  "disclose source of modifications; keep notices",
+	"GPL-2.0":  "disclose source of distributed binaries; keep notices; license derivatives under GPL-2.0",
+	"GPL-3.0":  "disclose source of distributed binaries; keep notices; license derivatives under GPL-3.0",
+	"LGPL-2.1": "disclose source of library modifications; allow relinking; keep notices",
+	"LGPL-3.0": "disclose source of library modifications; allow relinking; keep notices",
+	"MPL-2.0":  "disclose source of modified files; keep notices",
+	"MS-RL":    "disclose source of modified files; keep notices",
+	"OFL-1.1":  "keep notices; do not sell fonts alone; rename modified fonts",
+	"OSL-3.0":  "disclose source, including to network users; keep notices; license derivatives under OSL-3.0",
+}
+
+// unknownObligations is the obligation note of licenses without category.
+const unknownObligations = "review license manually"
+
+// categoryRanks orders categories by restrictiveness. Unknown ranks first as
+// it hides the most risk.
+var categoryRanks = map[string]int{
+	CategoryPublicDomain:   0,
+	CategoryPermissive:     1,
+	CategoryWeakCopyleft:   2,
+	CategoryStrongCopyleft: 3,
+	CategoryUnknown:        4,
+}
+
+// templateCategory returns the obligation category of t.
+func templateCategory(t *Template) string {
+	if t == nil {
+		return CategoryUnknown
+	}
+	if c, ok := categories[t.SPDX]; ok {
+		return c
+	}
+	return CategoryUnknown
+}
+
+// categorize returns the most restrictive obligation category of l templates,
+// or the least restrictive one for alternative licenses, or CategoryUnknown
+// if it has none or is not an exact or approximate match, see setStatus.
+func categorize(l *License) string {
+	templates := l.Templates()
+	if len(templates) == 0 || !l.identified() {
+		return CategoryUnknown
+	}
+	category := templateCategory(templates[0])
+	for _, t := range templates[1:] {
+		c := templateCategory(t)
+		if l.Alternatives && categoryRanks[c] < categoryRanks[category] ||
+			!l.Alternatives && categoryRanks[c] > categoryRanks[category] {
+			category = c
+		}
+	}
+	return category
+}
+
+// obligations returns the obligation notes of l templates, in order and
+// without duplicates. Licenses of unknown category get unknownObligations.
+// Alternative licenses get the notes of their least restrictive templates.
+func obligations(l *License) []string {
+	notes := []string{}
+	seen := map[string]bool{}
+	add := func(note string) {
+		if !seen[note] {
+			seen[note] = true
+			notes = append(notes, note)
+		}
+	}
+	templates := l.Templates()
+	if len(templates) == 0 || !l.identified() {
+		return []string{unknownObligations}
+	}
+	category := categorize(l)
+	for _, t := range templates {
+		if l.Alternatives && templateCategory(t) != category {
+			// Only the least restrictive alternatives are binding.
+			continue
+		}
+		switch templateCategory(t) {
+		case CategoryUnknown:
+			add(unknownObligations)
+		case CategoryPublicDomain, CategoryPermissive:
+		default:
+			if note, ok := obligationNotes[t.SPDX]; ok {
+				add(note)
+			}
+		}
+	}
+	return notes
+}
+
+// filterObligations returns licenses imposing active obligations, that is
+// neither permissive nor public domain, with their obligation notes set.
+func filterObligations(licenses []License) []License {
+	kept := []License{}
+	for _, l := range licenses {
+		if l.Category == CategoryPermissive || l.Category == CategoryPublicDomain {
+			continue
+		}
+		l.Obligations = obligations(&l)
+		kept = append(kept, l)
+	}
+	return kept
+}
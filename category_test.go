@@ -0,0 +1,80 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCategorize(t *testing.T) {
+	templates, err := loadTemplates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, templ := range templates {
+		// Templates without SPDX identifier, like No License, grant no rights.
+		if templ.SPDX != "" && templateCategory(templ) == CategoryUnknown {
+			t.Errorf("%s has no category", templ.Title)
+		}
+	}
+	mit := &Template{Title: "MIT License", SPDX: "MIT"}
+	lgpl := &Template{Title: "GNU Lesser General Public License v2.1", SPDX: "LGPL-2.1"}
+	cc0 := &Template{Title: "Creative Commons Zero v1.0 Universal", SPDX: "CC0-1.0"}
+	tests := []struct {
+		License  License
+		Category string
+	}{
+		{License{}, CategoryUnknown},
+		{License{Template: mit, Status: StatusExact}, CategoryPermissive},
+		{License{Template: mit, Status: StatusLowConfidence}, CategoryUnknown},
+		{License{Template: &Template{Title: "Custom"}, Status: StatusExact}, CategoryUnknown},
+		{License{Template: cc0, Status: StatusApproximate, Files: []LicenseFile{
+			{MatchResult: MatchResult{Template: cc0}},
+			{MatchResult: MatchResult{Template: lgpl}},
+			{MatchResult: MatchResult{Template: mit}},
+		}}, CategoryWeakCopyleft},
+	}
+	for i, test := range tests {
+		if got := categorize(&test.License); got != test.Category {
+			t.Errorf("%d: expected %s, got %s", i, test.Category, got)
+		}
+	}
+}
+
+func TestFilterObligations(t *testing.T) {
+	mit := &Template{Title: "MIT License", SPDX: "MIT"}
+	gpl := &Template{Title: "GNU General Public License v3.0", SPDX: "GPL-3.0"}
+	mpl := &Template{Title: "Mozilla Public License 2.0", SPDX: "MPL-2.0"}
+	licenses := []License{
+		{Package: "a", Template: mit, Score: 1},
+		{Package: "b", Template: gpl, Score: 1},
+		{Package: "c"},
+		{Package: "d", Template: mpl, Score: 1, Files: []LicenseFile{
+			{MatchResult: MatchResult{Template: mpl}},
+			{MatchResult: MatchResult{Template: mit}},
+			{MatchResult: MatchResult{Template: mpl}},
+		}},
+		// Low confidence matches do not tell obligations.
+		{Package: "e", Template: gpl, Score: 0.5},
+	}
+	setStatus(licenses, 0.9, exactScore)
+	kept := filterObligations(licenses)
+	got := []string{}
+	for _, l := range kept {
+		got = append(got, l.Package+": "+strings.Join(l.Obligations, " | "))
+	}
+	expected := []string{
+		"b: " + obligationNotes["GPL-3.0"],
+		"c: " + unknownObligations,
+		"d: " + obligationNotes["MPL-2.0"],
+		"e: " + unknownObligations,
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("unexpected obligations:\n%s", strings.Join(got, "\n"))
+	}
+	for spdx, category := range categories {
+		restrictive := category != CategoryPermissive && category != CategoryPublicDomain
+		if _, ok := obligationNotes[spdx]; ok != restrictive {
+			t.Errorf("%s obligation notes mismatch its %s category", spdx, category)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ANSI escape sequences used to colorize the terminal table.
+const (
+	colorGreen  = "\x1b[32m"
+	colorYellow = "\x1b[33m"
+	colorRed    = "\x1b[31m"
+	colorReset  = "\x1b[0m"
+)
+
+// isTerminal returns true if f looks like an interactive terminal.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// useColor tells whether the table is colorized for -color mode, one of
+// "auto", "always" or "never". In "auto" mode, the table is colorized if
+// stdout is a terminal, no report file is written and NO_COLOR is not set.
+func useColor(mode string, report bool) (bool, error) {
+	switch mode {
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	case "auto", "":
+		_, noColor := os.LookupEnv("NO_COLOR")
+		return !noColor && !report && isTerminal(os.Stdout), nil
+	}
+	return false, fmt.Errorf("unknown color mode: %s", mode)
+}
+
+// licenseColor returns the escape sequence colorizing l: green for exact
+// matches, yellow for approximate ones and red for the others and policy
+// violations.
+func licenseColor(l *License) string {
+	if l.Violation != "" {
+		return colorRed
+	}
+	switch l.Status {
+	case StatusExact:
+		return colorGreen
+	case StatusApproximate:
+		return colorYellow
+	}
+	return colorRed
+}
+
+// colorize wraps the first line of license with color. Following lines, like
+// differing words, are left as is. License is the last table column, so the
+// escape sequences do not disturb the alignment.
+func colorize(license, color string) string {
+	first, rest := license, ""
+	if i := strings.Index(license, "\n"); i >= 0 {
+		first, rest = license[:i], license[i:]
+	}
+	return color + first + colorReset + rest
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestUseColor(t *testing.T) {
+	for _, test := range []struct {
+		Mode   string
+		Report bool
+		Color  bool
+	}{
+		{"always", true, true},
+		{"never", false, false},
+		// Test output is not a terminal.
+		{"auto", false, false},
+	} {
+		color, err := useColor(test.Mode, test.Report)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if color != test.Color {
+			t.Errorf("unexpected color for %s: %v", test.Mode, color)
+		}
+	}
+	if _, err := useColor("sometimes", false); err == nil {
+		t.Fatal("invalid color mode was accepted")
+	}
+}
+
+func TestColorTable(t *testing.T) {
+	mit := &Template{Title: "MIT License"}
+	licenses := []License{
+		{Package: "a", Status: StatusExact, Template: mit, Score: 1},
+		{Package: "b", Status: StatusApproximate, Template: mit, Score: 0.9,
+			ExtraWords: []string{"extra"}, Trimmed: true},
+		{Package: "c", Status: StatusMissing},
+		{Package: "d", Status: StatusExact, Template: mit, Score: 1,
+			Violation: "denied"},
+	}
+	w := &bytes.Buffer{}
+	err := printTable(w, licenses, ReportOptions{Words: true, Color: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "a  " + colorGreen + "MIT License" + colorReset + "\n" +
+		"b  " + colorYellow + "MIT License (90%) [trimmed]" + colorReset + "\n" +
+		"   +words: extra\n" +
+		"c  " + colorRed + "?" + colorReset + "\n" +
+		"d  " + colorRed + "MIT License [violation: denied]" + colorReset + "\n"
+	if w.String() != expected {
+		t.Fatalf("unexpected table:\n%q\n%q", w.String(), expected)
+	}
+}
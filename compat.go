@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// incompatibilities lists pairs of licenses whose works cannot be combined
+// and distributed together, by SPDX identifiers in lexical order, with the
+// reason why. Templates do not tell GPL "only" versions from "or later" ones,
+// so GPL versions are taken as "only", which is the conservative reading.
+var incompatibilities = map[[2]string]string{
+	{"AFL-3.0", "AGPL-3.0"}:   "AFL-3.0 patent termination terms are restrictions AGPL-3.0 forbids",
+	{"AFL-3.0", "GPL-2.0"}:    "AFL-3.0 patent termination terms are restrictions GPL-2.0 forbids",
+	{"AFL-3.0", "GPL-3.0"}:    "AFL-3.0 patent termination terms are restrictions GPL-3.0 forbids",
+	{"AGPL-3.0", "EPL-1.0"}:   "EPL-1.0 copyleft and choice of law conflict with AGPL-3.0",
+	{"AGPL-3.0", "GPL-2.0"}:   "GPL-2.0 only works cannot be relicensed under AGPL-3.0",
+	{"AGPL-3.0", "MS-PL"}:     "MS-PL requires distributing source under MS-PL only",
+	{"AGPL-3.0", "MS-RL"}:     "MS-RL requires distributing source under MS-RL only",
+	{"AGPL-3.0", "OSL-3.0"}:   "OSL-3.0 copyleft requires derivatives under OSL-3.0",
+	{"Apache-2.0", "GPL-2.0"}: "Apache-2.0 patent termination and indemnity terms are restrictions GPL-2.0 forbids",
+	{"EPL-1.0", "GPL-2.0"}:    "EPL-1.0 copyleft and choice of law conflict with GPL-2.0",
+	{"EPL-1.0", "GPL-3.0"}:    "EPL-1.0 copyleft and choice of law conflict with GPL-3.0",
+	{"GPL-2.0", "GPL-3.0"}:    "GPL-2.0 only and GPL-3.0 both require derivatives under themselves",
+	{"GPL-2.0", "LGPL-3.0"}:   "LGPL-3.0 works can only be combined under GPL-3.0 or later",
+	{"GPL-2.0", "MS-PL"}:      "MS-PL requires distributing source under MS-PL only",
+	{"GPL-2.0", "MS-RL"}:      "MS-RL requires distributing source under MS-RL only",
+	{"GPL-2.0", "OSL-3.0"}:    "OSL-3.0 copyleft requires derivatives under OSL-3.0",
+	{"GPL-3.0", "MS-PL"}:      "MS-PL requires distributing source under MS-PL only",
+	{"GPL-3.0", "MS-RL"}:      "MS-RL requires distributing source under MS-RL only",
+	{"GPL-3.0", "OSL-3.0"}:    "OSL-3.0 copyleft requires derivatives under OSL-3.0",
+}
+
+// incompatibility returns the reason why licenses with SPDX identifiers a and
+// b cannot be distributed together, or an empty string if they can, as far as
+// incompatibilities tells.
+func incompatibility(a, b string) string {
+	if a > b {
+		a, b = b, a
+	}
+	return incompatibilities[[2]string{a, b}]
+}
+
+// Conflict is a pair of incompatible licenses, see incompatibilities, and the
+// packages using them.
+type Conflict struct {
+	// Licenses are the SPDX expressions of the conflicting licenses, see
+	// License.Expression, in lexical order.
+	Licenses [2]string
+	// Packages are the packages of each license.
+	Packages [2][]string
+	Reason   string
+}
+
+// licenseChoices returns the sets of SPDX identifiers whose terms apply to l,
+// one of which is picked for alternative licenses. It returns nil if l has a
+// template without SPDX identifier, as its compatibility is unknown.
+func licenseChoices(l *License) [][]string {
+	ids := []string{}
+	for _, t := range l.Templates() {
+		if t == nil || t.SPDX == "" {
+			return nil
+		}
+		ids = append(ids, t.SPDX)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	if !l.Alternatives {
+		return [][]string{ids}
+	}
+	choices := [][]string{}
+	for _, id := range ids {
+		choices = append(choices, []string{id})
+	}
+	return choices
+}
+
+// conflictReason returns why licenses with choices a and b, see
+// licenseChoices, cannot be distributed together, that is whatever the
+// alternatives picked, or an empty string if they can.
+func conflictReason(a, b [][]string) string {
+	first := ""
+	for _, ca := range a {
+		for _, cb := range b {
+			reason := ""
+			for _, ia := range ca {
+				for _, ib := range cb {
+					if reason == "" {
+						reason = incompatibility(ia, ib)
+					}
+				}
+			}
+			if reason == "" {
+				return ""
+			}
+			if first == "" {
+				first = reason
+			}
+		}
+	}
+	return first
+}
+
+// checkCompatibility returns the pairs of incompatible licenses of licenses,
+// ordered by license expressions. Licenses without SPDX identifier, failed
+// ones and matches which are neither exact nor approximate, see setStatus, are
+// ignored.
+func checkCompatibility(licenses []License) []Conflict {
+	type group struct {
+		choices  [][]string
+		packages []string
+	}
+	groups := map[string]*group{}
+	for i := range licenses {
+		l := &licenses[i]
+		if !l.identified() {
+			continue
+		}
+		choices := licenseChoices(l)
+		if choices == nil {
+			continue
+		}
+		expr := l.Expression()
+		g := groups[expr]
+		if g == nil {
+			g = &group{choices: choices}
+			groups[expr] = g
+		}
+		g.packages = append(g.packages, l.Package)
+		g.packages = append(g.packages, l.Similar...)
+	}
+	exprs := []string{}
+	for expr := range groups {
+		exprs = append(exprs, expr)
+	}
+	sort.Strings(exprs)
+	conflicts := []Conflict{}
+	for i, a := range exprs {
+		for _, b := range exprs[i+1:] {
+			reason := conflictReason(groups[a].choices, groups[b].choices)
+			if reason == "" {
+				continue
+			}
+			conflicts = append(conflicts, Conflict{
+				Licenses: [2]string{a, b},
+				Packages: [2][]string{groups[a].packages, groups[b].packages},
+				Reason:   reason,
+			})
+		}
+	}
+	return conflicts
+}
+
+// printConflicts writes conflicts to w, one per line, like "GPL-2.0 (a, b)
+// and Apache-2.0 (c): reason".
+func printConflicts(w io.Writer, conflicts []Conflict) error {
+	for _, c := range conflicts {
+		_, err := fmt.Fprintf(w, "incompatible licenses: %s (%s) and %s (%s): %s\n",
+			c.Licenses[0], strings.Join(c.Packages[0], ", "),
+			c.Licenses[1], strings.Join(c.Packages[1], ", "), c.Reason)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestIncompatibilitiesOrder(t *testing.T) {
+	for pair := range incompatibilities {
+		if pair[0] >= pair[1] {
+			t.Errorf("pair is not in lexical order: %v", pair)
+		}
+	}
+	if incompatibility("GPL-2.0", "Apache-2.0") == "" ||
+		incompatibility("Apache-2.0", "GPL-2.0") == "" {
+		t.Fatal("Apache-2.0 and GPL-2.0 should be incompatible")
+	}
+	if incompatibility("Apache-2.0", "GPL-3.0") != "" {
+		t.Fatal("Apache-2.0 and GPL-3.0 should be compatible")
+	}
+}
+
+func TestCheckCompatibility(t *testing.T) {
+	templates, err := loadTemplates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	find := func(spdx string) *Template {
+		tmpl, err := findTemplate(templates, spdx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tmpl
+	}
+	apache, gpl2, gpl3, mit := find("Apache-2.0"), find("GPL-2.0"), find("GPL-3.0"), find("MIT")
+	licenses := []License{
+		{Package: "a", Template: apache, Score: 1},
+		{Package: "b", Template: gpl2, Score: 1, Similar: []string{"c"}},
+		{Package: "d", Template: mit, Score: 1},
+		// Alternatives conflict only if all of them do.
+		{Package: "e", Template: apache, Score: 1, Alternatives: true, Files: []LicenseFile{
+			{MatchResult: MatchResult{Template: apache}},
+			{MatchResult: MatchResult{Template: mit}},
+		}},
+		{Package: "f", Template: apache, Score: 1, Files: []LicenseFile{
+			{MatchResult: MatchResult{Template: apache}},
+			{MatchResult: MatchResult{Template: mit}},
+		}},
+		{Package: "g"},
+		{Package: "h", Template: gpl3, Score: 1},
+		// Low confidence and failed matches are not known to be GPL-2.0.
+		{Package: "i", Template: gpl2, Score: 0.5},
+		{Package: "j", Template: gpl2, Score: 1, Err: "binary license file"},
+	}
+	setStatus(licenses, 0.9, exactScore)
+	conflicts := checkCompatibility(licenses)
+	expected := []Conflict{
+		{
+			Licenses: [2]string{"Apache-2.0", "GPL-2.0"},
+			Packages: [2][]string{{"a"}, {"b", "c"}},
+			Reason:   incompatibility("Apache-2.0", "GPL-2.0"),
+		},
+		{
+			Licenses: [2]string{"Apache-2.0 AND MIT", "GPL-2.0"},
+			Packages: [2][]string{{"f"}, {"b", "c"}},
+			Reason:   incompatibility("Apache-2.0", "GPL-2.0"),
+		},
+		{
+			Licenses: [2]string{"GPL-2.0", "GPL-3.0"},
+			Packages: [2][]string{{"b", "c"}, {"h"}},
+			Reason:   incompatibility("GPL-2.0", "GPL-3.0"),
+		},
+	}
+	if !reflect.DeepEqual(conflicts, expected) {
+		t.Fatalf("unexpected conflicts:\n%+v\nexpected:\n%+v", conflicts, expected)
+	}
+
+	out := &bytes.Buffer{}
+	err = printConflicts(out, conflicts[:1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	wanted := "incompatible licenses: Apache-2.0 (a) and GPL-2.0 (b, c): " +
+		incompatibility("Apache-2.0", "GPL-2.0") + "\n"
+	if out.String() != wanted {
+		t.Fatalf("unexpected output: %q", out.String())
+	}
+	if c := checkCompatibility(licenses[2:4]); len(c) != 0 {
+		t.Fatalf("unexpected conflicts: %+v", c)
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// declaredScore is the score of licenses inferred from a go.mod declaration
+// alone, when the module has no recognized license file.
+const declaredScore = 0.95
+
+// reDeclared matches go.mod comments declaring the module license, like
+// "// license: MIT" or "// SPDX-License-Identifier: Apache-2.0 OR MIT".
+var reDeclared = regexp.MustCompile(`(?i)^//\s*(?:license|spdx-license-identifier)\s*:\s*(.+?)\s*$`)
+
+// readDeclaredLicense returns the SPDX expression declared in the comments
+// preceding the module directive of go.mod data, or an empty string.
+func readDeclaredLicense(data []byte) string {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "//") {
+			break
+		}
+		if m := reDeclared.FindStringSubmatch(line); m != nil {
+			return m[1]
+		}
+	}
+	return ""
+}
+
+// findDeclaredLicense returns the license expression declared in the go.mod
+// file of module directory dir, if any.
+func findDeclaredLicense(dir string) (string, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return readDeclaredLicense(data), nil
+}
+
+var reExpressionID = regexp.MustCompile(`[A-Za-z0-9.+-]+`)
+
+// normalizeSPDX returns a comparable form of SPDX identifier id, ignoring
+// case and "-only", "-or-later" or "+" suffixes.
+func normalizeSPDX(id string) string {
+	id = strings.ToLower(id)
+	id = strings.TrimSuffix(id, "+")
+	id = strings.TrimSuffix(id, "-only")
+	return strings.TrimSuffix(id, "-or-later")
+}
+
+// expressionIDs returns the normalized license identifiers of SPDX
+// expression expr, without operators and exceptions.
+func expressionIDs(expr string) []string {
+	ids := []string{}
+	exception := false
+	for _, token := range reExpressionID.FindAllString(expr, -1) {
+		switch strings.ToUpper(token) {
+		case "AND", "OR":
+			exception = false
+			continue
+		case "WITH":
+			exception = true
+			continue
+		}
+		if !exception {
+			ids = append(ids, normalizeSPDX(token))
+		}
+	}
+	return ids
+}
+
+// applyDeclared cross-checks l with the license expression declared in the
+// go.mod file of its module, see findDeclaredLicense. Licenses without
+// template get the declared one if it is a single known identifier.
+// Licenses whose templates are not all part of the declaration are marked
+// with DeclaredMismatch.
+func applyDeclared(l *License, declared string, templates []*Template) {
+	if declared == "" {
+		return
+	}
+	l.Declared = declared
+	ids := expressionIDs(declared)
+	if l.Template == nil {
+		if l.Err != "" || len(ids) != 1 {
+			return
+		}
+		for _, t := range templates {
+			if t.SPDX != "" && t.Language == "" && normalizeSPDX(t.SPDX) == ids[0] {
+				l.Template = t
+				l.Score = declaredScore
+				l.TextScore = declaredScore
+				l.DeclaredOnly = true
+				return
+			}
+		}
+		return
+	}
+	for _, t := range l.Templates() {
+		found := false
+		for _, id := range ids {
+			if t != nil && t.SPDX != "" && normalizeSPDX(t.SPDX) == id {
+				found = true
+				break
+			}
+		}
+		if !found {
+			l.DeclaredMismatch = true
+			return
+		}
+	}
+}
+
+// applyDeclared cross-checks l, the license of package info, with the
+// license declared by its module go.mod file. Declarations are cached by
+// module directory.
+func (m *matcher) applyDeclared(l *License, info *PkgInfo) error {
+	dir := moduleDir(info)
+	if dir == "" {
+		return nil
+	}
+	declared, ok := m.declared[dir]
+	if !ok {
+		var err error
+		declared, err = findDeclaredLicense(dir)
+		if err != nil {
+			return err
+		}
+		m.declared[dir] = declared
+	}
+	applyDeclared(l, declared, m.templates)
+	return nil
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadDeclaredLicense(t *testing.T) {
+	for _, test := range []struct {
+		Data     string
+		Declared string
+	}{
+		{"// license: MIT\nmodule example.com/a\n", "MIT"},
+		{"// Some module.\n//  SPDX-License-Identifier:  Apache-2.0 OR MIT \n" +
+			"\nmodule example.com/a\n", "Apache-2.0 OR MIT"},
+		// Only comments preceding the module directive are declarations.
+		{"module example.com/a\n\n// license: MIT\n", ""},
+		{"module example.com/a\n", ""},
+	} {
+		declared := readDeclaredLicense([]byte(test.Data))
+		if declared != test.Declared {
+			t.Errorf("unexpected declaration in %q: %q", test.Data, declared)
+		}
+	}
+}
+
+func TestExpressionIDs(t *testing.T) {
+	ids := expressionIDs("(GPL-2.0-or-later WITH Classpath-exception-2.0 OR MIT) AND BSD-3-Clause")
+	expected := []string{"gpl-2.0", "mit", "bsd-3-clause"}
+	if !reflect.DeepEqual(ids, expected) {
+		t.Fatalf("unexpected identifiers: %v", ids)
+	}
+}
+
+func TestDeclaredLicense(t *testing.T) {
+	templates, err := loadTemplates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache, err := filepath.Abs(filepath.Join("testdata", "modcache", "example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := newMatcher(templates, nil, Options{})
+	scan := func(name string) License {
+		info := &PkgInfo{
+			Name:       name,
+			Dir:        filepath.Join(cache, name+"@v1.0.0"),
+			ImportPath: "example.com/" + name,
+		}
+		l, _, err := m.scanPackage(info, map[string][]LicenseFile{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = m.applyDeclared(&l, info)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return l
+	}
+
+	// Declared Apache-2.0 but MIT license file.
+	l := scan("declared")
+	if l.Title() != "MIT License" || l.Declared != "Apache-2.0" ||
+		!l.DeclaredMismatch || l.DeclaredOnly {
+		t.Fatalf("unexpected mismatching license: %+v", l)
+	}
+
+	// Declared MIT without license file.
+	l = scan("bare")
+	if l.Title() != "MIT License" || l.Score != declaredScore || !l.DeclaredOnly ||
+		l.DeclaredMismatch {
+		t.Fatalf("unexpected declared license: %+v", l)
+	}
+	if status := l.classify(0.9, exactScore); status != StatusApproximate {
+		t.Fatalf("unexpected declared license status: %s", status)
+	}
+}
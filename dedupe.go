@@ -0,0 +1,73 @@
+package main
+
+import (
+	"io/ioutil"
+	"strings"
+)
+
+// readLicenseWords returns the word set of the license file of l, see
+// makeWordSet.
+func readLicenseWords(l *License) (map[string]int, error) {
+	data, err := ioutil.ReadFile(l.file)
+	if err != nil {
+		return nil, err
+	}
+	return makeWordSet(decodeLicenseData(data)), nil
+}
+
+// isDedupable returns true if l is a single identified license file, whose
+// text can be compared to others.
+func isDedupable(l *License) bool {
+	return l.file != "" && l.Template != nil && l.Err == "" && !l.Header &&
+		!l.Override && !l.DeclaredOnly && len(l.Files) <= 1
+}
+
+// dedupeLicenses collapses licenses matching the same template whose texts
+// are similar above threshold into the first of them, listing the others in
+// Similar. Texts are compared with each other like licenses with templates,
+// see compareWords, so licenses differing only by personalized names or
+// years end up in one entry. Other licenses are left unchanged.
+func dedupeLicenses(licenses []License, threshold float64) ([]License, error) {
+	type cluster struct {
+		index int
+		words *Template
+	}
+	clusters := map[*Template][]cluster{}
+	kept := []License{}
+	for _, l := range licenses {
+		if !isDedupable(&l) {
+			kept = append(kept, l)
+			continue
+		}
+		words, err := readLicenseWords(&l)
+		if err != nil {
+			return nil, err
+		}
+		found := false
+		for _, c := range clusters[l.Template] {
+			score, _, _, _ := compareWords(words, nil, c.words, 0, diceScore)
+			if score >= threshold {
+				rep := &kept[c.index]
+				rep.Similar = append(rep.Similar, l.Package)
+				rep.Similar = append(rep.Similar, l.Similar...)
+				rep.merge(l)
+				found = true
+				break
+			}
+		}
+		if !found {
+			clusters[l.Template] = append(clusters[l.Template], cluster{
+				index: len(kept),
+				words: &Template{words: words},
+			})
+			kept = append(kept, l)
+		}
+	}
+	return kept, nil
+}
+
+// packages returns the package of l followed by the ones collapsed into it,
+// see dedupeLicenses.
+func (l *License) packages() string {
+	return strings.Join(append([]string{l.Package}, l.Similar...), ", ")
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDedupeLicenses(t *testing.T) {
+	gopath, err := filepath.Abs("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	licenses, err := listLicenses(&GoEnv{GOPATH: gopath},
+		[]string{"colors/ivory", "colors/pink", "colors/red", "colors/silver"}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	deduped, err := dedupeLicenses(licenses, 0.95)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// colors/pink has trailing notes, colors/silver another license.
+	packages := []string{}
+	for _, l := range deduped {
+		packages = append(packages, l.packages())
+	}
+	expected := []string{"colors/ivory, colors/red", "colors/pink", "colors/silver"}
+	if !reflect.DeepEqual(packages, expected) {
+		t.Fatalf("unexpected deduplicated licenses: %q", packages)
+	}
+	deduped, err = dedupeLicenses(licenses, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deduped) != len(licenses) {
+		t.Fatalf("unexpected deduplicated licenses: %+v", deduped)
+	}
+}
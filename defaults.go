@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+const (
+	// defaultsEnv is the environment variable holding default flags.
+	defaultsEnv = "LICENSES_FLAGS"
+	// defaultsFile is the file of the working directory holding default
+	// flags, one or more per line.
+	defaultsFile = ".licenses.conf"
+)
+
+// splitArgs splits s into arguments separated by spaces, like a shell does.
+// Single and double quotes group words, and backslashes escape the next
+// character, except within single quotes.
+func splitArgs(s string) ([]string, error) {
+	args := []string{}
+	arg := strings.Builder{}
+	inArg := false
+	quote := rune(0)
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			arg.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inArg = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				arg.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, fmt.Errorf("unterminated quote or escape")
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args, nil
+}
+
+// readDefaultsFile returns the default flags of the file at path, see
+// splitArgs, ignoring empty lines and "#" comment lines. A missing file has no
+// flags.
+func readDefaultsFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	args := []string{}
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words, err := splitArgs(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %s", path, n, err)
+		}
+		args = append(args, words...)
+	}
+	return args, scanner.Err()
+}
+
+// defaultFlags lists the flags defaults can set: the output format, the
+// confidence threshold, with -strict, and the license policy. Others, like -go
+// or -update-lock, would run commands or write files unbeknownst to users.
+var defaultFlags = map[string]bool{
+	"allow":  true,
+	"deny":   true,
+	"format": true,
+	"strict": true,
+}
+
+// defaultedFlag wraps the value of a repeatable flag set by defaults, so
+// setting it again, from later defaults or the command line, replaces the
+// default values instead of accumulating with them.
+type defaultedFlag struct {
+	*stringsFlag
+	defaulted bool
+}
+
+func (f *defaultedFlag) Set(value string) error {
+	if f.defaulted {
+		*f.stringsFlag = nil
+		f.defaulted = false
+	}
+	return f.stringsFlag.Set(value)
+}
+
+// parseDefaults parses the default flags args of source in fs. Unlike
+// command line arguments, they must all be flags of defaultFlags, and errors
+// are returned, prefixed with source, instead of printing the usage.
+func parseDefaults(fs *flag.FlagSet, args []string, source string) error {
+	defaults := flag.NewFlagSet(fs.Name(), flag.ContinueOnError)
+	defaults.Usage = func() {}
+	defaults.SetOutput(ioutil.Discard)
+	fs.VisitAll(func(f *flag.Flag) {
+		if defaultFlags[f.Name] {
+			defaults.Var(f.Value, f.Name, f.Usage)
+		}
+	})
+	err := defaults.Parse(args)
+	if err != nil {
+		return fmt.Errorf("%s: %s", source, err)
+	}
+	if defaults.NArg() > 0 {
+		return fmt.Errorf("%s: unexpected argument: %s", source, defaults.Arg(0))
+	}
+	defaults.Visit(func(f *flag.Flag) {
+		switch v := f.Value.(type) {
+		case *stringsFlag:
+			fs.Lookup(f.Name).Value = &defaultedFlag{stringsFlag: v, defaulted: true}
+		case *defaultedFlag:
+			v.defaulted = true
+		}
+	})
+	return nil
+}
+
+// loadDefaults parses the default flags of defaultsFile, then of defaultsEnv,
+// in fs. Flags parsed afterwards, like command line ones, override them,
+// repeatable flags included.
+func loadDefaults(fs *flag.FlagSet) error {
+	args, err := readDefaultsFile(defaultsFile)
+	if err != nil {
+		return err
+	}
+	err = parseDefaults(fs, args, defaultsFile)
+	if err != nil {
+		return err
+	}
+	args, err = splitArgs(os.Getenv(defaultsEnv))
+	if err != nil {
+		return fmt.Errorf("%s: %s", defaultsEnv, err)
+	}
+	return parseDefaults(fs, args, defaultsEnv)
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSplitArgs(t *testing.T) {
+	tests := map[string][]string{
+		"":                           {},
+		"  -strict\t-top 3 ":         {"-strict", "-top", "3"},
+		`-deny "GPL 3" -allow='MIT'`: {"-deny", "GPL 3", "-allow=MIT"},
+		`-root a\ b 'c\d' ""`:        {"-root", "a b", `c\d`, ""},
+	}
+	for s, expected := range tests {
+		args, err := splitArgs(s)
+		if err != nil {
+			t.Errorf("%q: %s", s, err)
+			continue
+		}
+		if !reflect.DeepEqual(args, expected) {
+			t.Errorf("%q: expected %q, got %q", s, expected, args)
+		}
+	}
+	for _, s := range []string{`-deny "GPL`, `-deny GPL\`} {
+		if _, err := splitArgs(s); err == nil {
+			t.Errorf("%q: error expected", s)
+		}
+	}
+}
+
+func TestLoadDefaults(t *testing.T) {
+	dir, err := ioutil.TempDir("", "licenses-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+	conf := "# team defaults\n-format markdown -strict\n\n-allow MIT\n"
+	err = ioutil.WriteFile(filepath.Join(dir, defaultsFile), []byte(conf), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(defaultsEnv, "-format json -allow Apache-2.0")
+
+	fs := flag.NewFlagSet("licenses", flag.ExitOnError)
+	format := fs.String("format", "table", "")
+	strict := fs.Bool("strict", false, "")
+	var allow stringsFlag
+	fs.Var(&allow, "allow", "")
+	if err := loadDefaults(fs); err != nil {
+		t.Fatal(err)
+	}
+	// Environment defaults replace the file ones.
+	if !reflect.DeepEqual([]string(allow), []string{"Apache-2.0"}) {
+		t.Fatalf("unexpected default allow: %v", allow)
+	}
+	// Command line values replace the defaults.
+	if err := fs.Parse([]string{"-allow", "BSD-3-Clause", "-allow", "ISC", "pkg"}); err != nil {
+		t.Fatal(err)
+	}
+	if *format != "json" || !*strict || fs.Arg(0) != "pkg" ||
+		!reflect.DeepEqual([]string(allow), []string{"BSD-3-Clause", "ISC"}) {
+		t.Fatalf("unexpected flags: %s %v %v %v", *format, *strict, allow, fs.Args())
+	}
+
+	for _, env := range []string{"-unknown", "-format", "pkg", "-format 'json",
+		"-update-lock"} {
+		t.Setenv(defaultsEnv, env)
+		fs := flag.NewFlagSet("licenses", flag.ExitOnError)
+		fs.String("format", "table", "")
+		fs.Bool("update-lock", false, "")
+		fs.Bool("strict", false, "")
+		fs.Var(&stringsFlag{}, "allow", "")
+		if err := loadDefaults(fs); err == nil {
+			t.Errorf("%q: error expected", env)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged words displayed around changes in
+// unified word diffs.
+const diffContext = 3
+
+// wordSequence returns the words of a word set, see makeWordSet, ordered by
+// first position.
+func wordSequence(words map[string]int) []string {
+	seq := make([]Word, 0, len(words))
+	for w, pos := range words {
+		seq = append(seq, Word{Text: w, Pos: pos})
+	}
+	return sortAndReturnWords(seq)
+}
+
+// diffOp is an edit of a word diff: ' ' for common words, '-' for template
+// words missing from the license and '+' for extra license words.
+type diffOp struct {
+	Op   byte
+	Word string
+}
+
+// diffWords returns the edits turning a into b, keeping their longest common
+// subsequence.
+func diffWords(a, b []string) []diffOp {
+	// lcs[i][j] is the longest common subsequence length of a[i:] and b[j:].
+	lcs := make([][]int32, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	ops := []diffOp{}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case j == len(b) || i < len(a) && lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	return ops
+}
+
+// hunkRange formats a unified diff hunk range, from the number of words
+// before the hunk and the number of words in it.
+func hunkRange(before, n int) string {
+	if n == 0 {
+		return fmt.Sprintf("%d,0", before)
+	}
+	return fmt.Sprintf("%d,%d", before+1, n)
+}
+
+// unifiedWordDiff returns a unified diff like view of template t and license
+// word set words, both taken as word sequences ordered by first position.
+// Hunks start with "@@ -template +license @@" word ranges, followed by lines
+// of consecutive unchanged, missing or extra words, prefixed with ' ', '-'
+// and '+'. It returns nothing if they have the same words in the same order.
+func unifiedWordDiff(t *Template, words map[string]int) []string {
+	ops := diffWords(wordSequence(t.Words()), wordSequence(words))
+	// Select the edits within diffContext words of a change.
+	shown := make([]bool, len(ops))
+	for i, op := range ops {
+		if op.Op == ' ' {
+			continue
+		}
+		lo, hi := i-diffContext, i+diffContext
+		for k := lo; k <= hi; k++ {
+			if k >= 0 && k < len(ops) {
+				shown[k] = true
+			}
+		}
+	}
+	lines := []string{}
+	before := map[byte]int{'-': 0, '+': 0}
+	for i := 0; i < len(ops); {
+		if !shown[i] {
+			countWords(before, ops[i])
+			i++
+			continue
+		}
+		end := i
+		for end < len(ops) && shown[end] {
+			end++
+		}
+		hunk := ops[i:end]
+		inside := map[byte]int{'-': 0, '+': 0}
+		for _, op := range hunk {
+			countWords(inside, op)
+		}
+		lines = append(lines, fmt.Sprintf("@@ -%s +%s @@",
+			hunkRange(before['-'], inside['-']), hunkRange(before['+'], inside['+'])))
+		for k := 0; k < len(hunk); {
+			run := []string{}
+			op := hunk[k].Op
+			for ; k < len(hunk) && hunk[k].Op == op; k++ {
+				run = append(run, hunk[k].Word)
+			}
+			lines = append(lines, string(op)+strings.Join(run, " "))
+		}
+		before['-'] += inside['-']
+		before['+'] += inside['+']
+		i = end
+	}
+	return lines
+}
+
+// countWords increments the template and license word counts, keyed by '-'
+// and '+', of words covered by op.
+func countWords(counts map[byte]int, op diffOp) {
+	if op.Op != '+' {
+		counts['-']++
+	}
+	if op.Op != '-' {
+		counts['+']++
+	}
+}
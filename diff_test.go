@@ -0,0 +1,64 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffWords(t *testing.T) {
+	ops := diffWords(strings.Fields("a b c d"), strings.Fields("a x c d e"))
+	got := []string{}
+	for _, op := range ops {
+		got = append(got, string(op.Op)+op.Word)
+	}
+	expected := []string{" a", "-b", "+x", " c", " d", "+e"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("unexpected edits: %q", got)
+	}
+}
+
+func TestUnifiedWordDiff(t *testing.T) {
+	sequence := func(s string) map[string]int {
+		words := map[string]int{}
+		for i, w := range strings.Fields(s) {
+			words[w] = i
+		}
+		return words
+	}
+	tpl := &Template{words: sequence("w1 w2 w3 w4 w5 w6 w7 w8 w9 w10 w11 w12")}
+	diff := unifiedWordDiff(tpl, sequence("w1 extra w2 w3 w4 w5 w6 w7 w8 w9 w10 w12"))
+	expected := []string{
+		"@@ -1,4 +1,5 @@",
+		" w1",
+		"+extra",
+		" w2 w3 w4",
+		"@@ -8,5 +9,4 @@",
+		" w8 w9 w10",
+		"-w11",
+		" w12",
+	}
+	if !reflect.DeepEqual(diff, expected) {
+		t.Fatalf("unexpected diff:\n%s", strings.Join(diff, "\n"))
+	}
+	if diff := unifiedWordDiff(tpl, tpl.Words()); len(diff) != 0 {
+		t.Fatalf("unexpected diff of identical words: %q", diff)
+	}
+}
+
+func TestLicenseDiff(t *testing.T) {
+	gopath, err := filepath.Abs("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	licenses, err := listLicenses(&GoEnv{GOPATH: gopath}, []string{"colors/red"},
+		Options{Diff: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(licenses) != 1 || len(licenses[0].Diff) == 0 ||
+		licenses[0].Diff[1] != "-the mit license" {
+		t.Fatalf("unexpected license diff: %+v", licenses)
+	}
+}
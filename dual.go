@@ -0,0 +1,156 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// alternativeScore is the minimum match score of license files supplementing
+// the best named one in a directory, like LICENSE-APACHE next to LICENSE-MIT
+// in dual licensed projects.
+const alternativeScore = 0.8
+
+var (
+	// reDualName matches license file names suffixed with a license
+	// identifier, like LICENSE-MIT or COPYING.APACHE, which name alternatives.
+	reDualName = regexp.MustCompile(`(?i)^(?:licen[sc]e|copying)[-_.]([a-z0-9.-]+)$`)
+	// reDualText matches statements of dual licensing, like "Licensed under
+	// either of ... at your option.", but not the "(at your option) any later
+	// version" or "you may at your option offer" clauses of GPL texts.
+	reDualText = regexp.MustCompile(
+		`(?i)dual[- ]licen[sc]ed|licen[sc]ed under either|at your option(?:$|[.,;])`)
+)
+
+// sourceExtensions lists the extensions of source files, which are never
+// license file candidates even if named like license.go.
+var sourceExtensions = map[string]bool{
+	".c": true, ".cc": true, ".cpp": true, ".go": true, ".h": true,
+	".java": true, ".js": true, ".py": true, ".rb": true, ".rs": true,
+	".s": true, ".sh": true, ".ts": true,
+}
+
+// findAlternatives returns the other license file candidates sitting next to
+// the license file at path, relative to root, ordered by decreasing name
+// score, see scoreLicenseName. Names scoring below minScore and source files,
+// see sourceExtensions, are ignored. It returns nothing for license
+// directories.
+func findAlternatives(root, path string, minScore float64) ([]string, error) {
+	fpath := filepath.Join(root, path)
+	fi, err := os.Stat(fpath)
+	if err != nil || fi.IsDir() {
+		return nil, err
+	}
+	fis, err := ioutil.ReadDir(filepath.Dir(fpath))
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, fi := range fis {
+		name := fi.Name()
+		if !fi.Mode().IsRegular() || name == filepath.Base(path) ||
+			sourceExtensions[strings.ToLower(filepath.Ext(name))] {
+			continue
+		}
+		if score := scoreLicenseName(name); score > 0 && score >= minScore {
+			names = append(names, name)
+		}
+	}
+	sort.SliceStable(names, func(i, j int) bool {
+		return scoreLicenseName(names[i]) > scoreLicenseName(names[j])
+	})
+	paths := []string{}
+	for _, name := range names {
+		paths = append(paths, filepath.Join(filepath.Dir(path), name))
+	}
+	return paths, nil
+}
+
+// pickAlternatives returns the first of files, the best named license file,
+// followed by the others matching another template than the previous ones
+// with a score of alternativeScore at least. files is left unchanged.
+func pickAlternatives(files []LicenseFile) []LicenseFile {
+	if len(files) == 0 {
+		return files
+	}
+	kept := []LicenseFile{files[0]}
+	seen := map[*Template]bool{files[0].Template: true}
+	for _, f := range files[1:] {
+		if f.Err != "" || f.Template == nil || f.Score < alternativeScore ||
+			seen[f.Template] {
+			continue
+		}
+		seen[f.Template] = true
+		kept = append(kept, f)
+	}
+	return kept
+}
+
+// isDualName returns true if name is a license file name suffixed with a
+// license identifier, see reDualName, rather than a text file extension.
+func isDualName(name string) bool {
+	m := reDualName.FindStringSubmatch(name)
+	if m == nil {
+		return false
+	}
+	switch strings.ToLower(m[1]) {
+	case "md", "markdown", "txt", "rst":
+		return false
+	}
+	return true
+}
+
+// isDualLicensed returns true if files, relative to root, are alternative
+// licenses rather than licenses applying together. That is if they are all
+// named after their license, like LICENSE-MIT and LICENSE-APACHE, or if one of
+// them states dual licensing, like "dual licensed" or "at your option".
+func isDualLicensed(root string, files []LicenseFile) (bool, error) {
+	named := true
+	for _, f := range files {
+		named = named && isDualName(filepath.Base(f.Path))
+	}
+	if named {
+		return true, nil
+	}
+	for _, f := range files {
+		data, err := readLicenseText(filepath.Join(root, f.Path))
+		if err != nil {
+			return false, err
+		}
+		text := strings.Join(strings.Fields(string(decodeLicenseData(data))), " ")
+		if reDualText.MatchString(text) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Expression returns the SPDX expression of l, joining the identifiers of
+// its license files with OR for alternatives and AND otherwise. Templates
+// without identifier are represented by their titles.
+func (l *License) Expression() string {
+	ids := []string{}
+	for _, t := range l.Templates() {
+		switch {
+		case t == nil:
+			ids = append(ids, "?")
+		case t.SPDX != "":
+			ids = append(ids, t.SPDX)
+		default:
+			ids = append(ids, t.Title)
+		}
+	}
+	return strings.Join(ids, l.operator())
+}
+
+// operator returns the operator joining l license files in titles and
+// expressions.
+func (l *License) operator() string {
+	if l.Alternatives {
+		return " OR "
+	}
+	return " AND "
+}
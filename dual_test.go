@@ -0,0 +1,141 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFindAlternatives(t *testing.T) {
+	root := filepath.Join("testdata", "src")
+	paths, err := findAlternatives(root, filepath.Join("colors", "blue", "LICENSE"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{filepath.Join("colors", "blue", "COPYING")}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Fatalf("unexpected alternatives: %v", paths)
+	}
+}
+
+func TestDualLicensed(t *testing.T) {
+	dir, err := ioutil.TempDir("", "licenses-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"LICENSE":        "MIT license text",
+		"COPYING":        "Apache license text",
+		"license.go":     "package license",
+		"LICENSE-MIT":    "MIT license text",
+		"LICENSE-APACHE": "Apache license text",
+		"LICENSE.txt":    "This project is dual-licensed under MIT or Apache-2.0.",
+		"LICENSE.md":     "Licensed under either of Apache-2.0 or MIT\nat your option.",
+		"COPYING.txt":    "or (at your option) any later version, you may at your option offer",
+	}
+	for name, content := range files {
+		err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	paths, err := findAlternatives(dir, "LICENSE", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range paths {
+		if path == "license.go" {
+			t.Fatalf("source files are not alternatives: %v", paths)
+		}
+	}
+	tests := []struct {
+		Paths []string
+		Dual  bool
+	}{
+		{[]string{"LICENSE", "COPYING"}, false},
+		{[]string{"LICENSE-MIT", "LICENSE-APACHE"}, true},
+		{[]string{"LICENSE", "LICENSE-APACHE"}, false},
+		{[]string{"LICENSE.txt", "COPYING"}, true},
+		{[]string{"LICENSE.md", "COPYING"}, true},
+		{[]string{"COPYING.txt", "LICENSE"}, false},
+	}
+	for _, test := range tests {
+		files := []LicenseFile{}
+		for _, path := range test.Paths {
+			files = append(files, LicenseFile{Path: path})
+		}
+		dual, err := isDualLicensed(dir, files)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dual != test.Dual {
+			t.Errorf("%v: expected dual=%v, got %v", test.Paths, test.Dual, dual)
+		}
+	}
+}
+
+func TestPickAlternatives(t *testing.T) {
+	mit := &Template{Title: "MIT License", SPDX: "MIT"}
+	apache := &Template{Title: "Apache License 2.0", SPDX: "Apache-2.0"}
+	file := func(path string, tpl *Template, score float64) LicenseFile {
+		return LicenseFile{
+			Path:        path,
+			MatchResult: MatchResult{Template: tpl, Score: score},
+		}
+	}
+	files := []LicenseFile{
+		file("LICENSE", mit, 0.7),
+		// Same license in another file.
+		file("LICENSE.md", mit, 1),
+		// Not a license text, like license.go.
+		file("license.go", apache, 0.2),
+		file("LICENSE-APACHE", apache, 0.99),
+	}
+	kept := pickAlternatives(files)
+	if len(kept) != 2 || kept[0].Path != "LICENSE" || kept[1].Path != "LICENSE-APACHE" {
+		t.Fatalf("unexpected alternatives: %+v", kept)
+	}
+	// Rejected files are not overwritten, files being cached and hashed.
+	for i, path := range []string{"LICENSE", "LICENSE.md", "license.go", "LICENSE-APACHE"} {
+		if files[i].Path != path {
+			t.Fatalf("files were modified: %+v", files)
+		}
+	}
+
+	l := License{Alternatives: true}
+	l.setFiles(kept)
+	if l.Title() != "MIT License OR Apache License 2.0" ||
+		l.Expression() != "MIT OR Apache-2.0" || l.Score != 0.7 {
+		t.Fatalf("unexpected alternative license: %+v", l)
+	}
+}
+
+func TestAlternativesPolicy(t *testing.T) {
+	mit := &Template{Title: "MIT License", SPDX: "MIT"}
+	gpl := &Template{Title: "GNU General Public License v3.0", SPDX: "GPL-3.0"}
+	files := []LicenseFile{
+		{Path: "LICENSE-GPL", MatchResult: MatchResult{Template: gpl, Score: 1}},
+		{Path: "LICENSE-MIT", MatchResult: MatchResult{Template: mit, Score: 1}},
+	}
+	p := &Policy{Deny: []string{"GPL-3.0"}}
+	for _, alternatives := range []bool{true, false} {
+		l := License{Package: "a", Alternatives: alternatives, Status: StatusExact}
+		l.setFiles(files)
+		violation := p.Check(&l)
+		if alternatives && violation != "" ||
+			!alternatives && violation != "GNU General Public License v3.0 is denied" {
+			t.Fatalf("unexpected violation with alternatives=%v: %q",
+				alternatives, violation)
+		}
+		category := CategoryStrongCopyleft
+		if alternatives {
+			category = CategoryPermissive
+		}
+		if c := categorize(&l); c != category {
+			t.Fatalf("unexpected category with alternatives=%v: %s", alternatives, c)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// embedPatterns returns the patterns of the //go:embed directives of Go
+// source file fpath, with quoted patterns unquoted.
+func embedPatterns(fpath string) ([]string, error) {
+	f, err := os.Open(fpath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	patterns := []string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "//go:embed") {
+			continue
+		}
+		args := strings.TrimPrefix(line, "//go:embed")
+		if args == "" || (args[0] != ' ' && args[0] != '\t') {
+			continue
+		}
+		patterns = append(patterns, splitEmbedArgs(args)...)
+	}
+	return patterns, scanner.Err()
+}
+
+// splitEmbedArgs splits //go:embed arguments on spaces, unquoting patterns
+// written as Go string literals.
+func splitEmbedArgs(args string) []string {
+	patterns := []string{}
+	for args = strings.TrimSpace(args); args != ""; args = strings.TrimSpace(args) {
+		if quote := args[0]; quote == '"' || quote == '`' {
+			end := 1
+			for ; end < len(args) && args[end] != quote; end++ {
+				if quote == '"' && args[end] == '\\' {
+					end++
+				}
+			}
+			if end >= len(args) {
+				break
+			}
+			p, err := strconv.Unquote(args[:end+1])
+			if err == nil {
+				patterns = append(patterns, p)
+			}
+			args = args[end+1:]
+			continue
+		}
+		end := strings.IndexAny(args, " \t")
+		if end < 0 {
+			end = len(args)
+		}
+		patterns = append(patterns, args[:end])
+		args = args[end:]
+	}
+	return patterns
+}
+
+// matchesEmbed returns true if embed pattern includes the file at slash
+// separated path rel, either directly or through one of its parent
+// directories. Files starting with "." or "_" are only included through
+// directories with "all:" patterns.
+func matchesEmbed(pattern, rel string) bool {
+	all := strings.HasPrefix(pattern, "all:")
+	pattern = strings.TrimPrefix(pattern, "all:")
+	if ok, _ := path.Match(pattern, rel); ok {
+		return true
+	}
+	if !all {
+		for _, elem := range strings.Split(rel, "/") {
+			if strings.HasPrefix(elem, ".") || strings.HasPrefix(elem, "_") {
+				return false
+			}
+		}
+	}
+	for dir := path.Dir(rel); dir != "."; dir = path.Dir(dir) {
+		if ok, _ := path.Match(pattern, dir); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// isEmbedded returns true if the license file at fpath is embedded in
+// package directory dir with //go:embed directives of its non-test Go files.
+// Embedded files cannot be above the package directory.
+func isEmbedded(dir, fpath string) (bool, error) {
+	if dir == "" || !isBeneath(fpath, dir) {
+		return false, nil
+	}
+	rel, err := filepath.Rel(dir, fpath)
+	if err != nil {
+		return false, err
+	}
+	rel = filepath.ToSlash(rel)
+	sources, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return false, err
+	}
+	for _, source := range sources {
+		if strings.HasSuffix(source, "_test.go") {
+			continue
+		}
+		patterns, err := embedPatterns(source)
+		if err != nil {
+			return false, err
+		}
+		for _, pattern := range patterns {
+			if matchesEmbed(pattern, rel) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
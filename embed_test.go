@@ -0,0 +1,50 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSplitEmbedArgs(t *testing.T) {
+	patterns := splitEmbedArgs(` LICENSE "with space.txt" ` + "`raw`" + ` "esc\"aped" all:docs`)
+	expected := []string{"LICENSE", "with space.txt", "raw", `esc"aped`, "all:docs"}
+	if !reflect.DeepEqual(patterns, expected) {
+		t.Fatalf("unexpected patterns: %q", patterns)
+	}
+}
+
+func TestMatchesEmbed(t *testing.T) {
+	for _, test := range []struct {
+		Pattern string
+		Path    string
+		Matches bool
+	}{
+		{"LICENSE", "LICENSE", true},
+		{"LICEN*", "LICENSE", true},
+		{"COPYING", "LICENSE", false},
+		{"legal", "legal/LICENSE", true},
+		{"legal", "legal/.LICENSE", false},
+		{"all:legal", "legal/.LICENSE", true},
+		{"*.md", "LICENSE", false},
+	} {
+		if matchesEmbed(test.Pattern, test.Path) != test.Matches {
+			t.Errorf("unexpected match of %s with %s", test.Path, test.Pattern)
+		}
+	}
+}
+
+func TestEmbeddedLicense(t *testing.T) {
+	gopath, err := filepath.Abs("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	licenses, err := listLicenses(&GoEnv{GOPATH: gopath},
+		[]string{"colors/red", "colors/salmon"}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(licenses) != 2 || licenses[0].Embedded || !licenses[1].Embedded {
+		t.Fatalf("unexpected embedded licenses: %+v", licenses)
+	}
+}
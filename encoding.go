@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Byte order marks of Unicode encoded texts.
+var (
+	bomUTF8    = []byte{0xef, 0xbb, 0xbf}
+	bomUTF16LE = []byte{0xff, 0xfe}
+	bomUTF16BE = []byte{0xfe, 0xff}
+)
+
+// decodeLicenseData returns license data as UTF-8, without byte order mark.
+// UTF-16 texts, like some Windows authored license files, are recognized by
+// their byte order mark and transcoded. Other data is returned as is.
+func decodeLicenseData(data []byte) []byte {
+	var order binary.ByteOrder
+	switch {
+	case bytes.HasPrefix(data, bomUTF8):
+		return data[len(bomUTF8):]
+	case bytes.HasPrefix(data, bomUTF16LE):
+		order = binary.LittleEndian
+	case bytes.HasPrefix(data, bomUTF16BE):
+		order = binary.BigEndian
+	default:
+		return data
+	}
+	data = data[2:]
+	units := make([]uint16, 0, len(data)/2)
+	for ; len(data) >= 2; data = data[2:] {
+		units = append(units, order.Uint16(data))
+	}
+	decoded := make([]byte, 0, len(units))
+	buf := make([]byte, utf8.UTFMax)
+	for _, r := range utf16.Decode(units) {
+		n := utf8.EncodeRune(buf, r)
+		decoded = append(decoded, buf[:n]...)
+	}
+	return decoded
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestDecodeLicenseData(t *testing.T) {
+	for _, test := range []struct {
+		Data     string
+		Expected string
+	}{
+		{"plain é", "plain é"},
+		{"\xef\xbb\xbfbom é", "bom é"},
+		{"\xff\xfel\x00e\x00\xe9\x00", "leé"},
+		{"\xfe\xff\x00b\x00e\x00\xe9", "beé"},
+		// Odd trailing byte of truncated UTF-16.
+		{"\xff\xfeo\x00k\x00!", "ok"},
+	} {
+		decoded := string(decodeLicenseData([]byte(test.Data)))
+		if decoded != test.Expected {
+			t.Errorf("unexpected decoding of %q: %q", test.Data, decoded)
+		}
+	}
+}
+
+func TestEncodedLicenses(t *testing.T) {
+	// MIT licenses with CRLF line endings, UTF-8 with BOM and UTF-16LE.
+	err := compareTestLicenses([]string{"colors/ivory", "colors/linen"}, []testResult{
+		{Package: "colors/ivory", License: "MIT License", Score: 100},
+		{Package: "colors/linen", License: "MIT License", Score: 100},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
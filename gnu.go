@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+)
+
+// preambleScore is the score of licenses inferred from a GNU notice preamble
+// alone, like "This program is free software...". Unlike source headers, the
+// notice stands in the license file, so it is trusted more.
+const preambleScore = 0.9
+
+// gnuVersionDelta is the score delta below which a GNU notice preamble
+// overrides the best matching GNU license text, telling apart family members
+// whose texts are very similar.
+const gnuVersionDelta = 0.05
+
+// rePreamble matches the GNU notice preamble, on lowercased words separated
+// by single spaces. It captures the license variant and version numbers.
+var rePreamble = regexp.MustCompile(`is free software you can redistribute it ` +
+	`and or modify it under the terms of the gnu (?:(lesser|library|affero) )?` +
+	`general public license(?: [\p{L}\p{N}]+){0,8}? version (\d+)(?: (\d+))?`)
+
+// gnuLicenses maps GNU preamble variants and versions to SPDX identifiers.
+var gnuLicenses = map[string]string{
+	"/2":        "GPL-2.0",
+	"/3":        "GPL-3.0",
+	"lesser/2":  "LGPL-2.1",
+	"library/2": "LGPL-2.1",
+	"lesser/3":  "LGPL-3.0",
+	"affero/3":  "AGPL-3.0",
+}
+
+// findGNUPreamble returns the SPDX identifier of the GNU license referenced
+// by a notice preamble in data, or an empty string if there is none.
+func findGNUPreamble(data []byte) string {
+	words := reWords.FindAll(bytes.ToLower(data), -1)
+	text := string(bytes.Join(words, []byte(" ")))
+	m := rePreamble.FindStringSubmatch(text)
+	if m == nil {
+		return ""
+	}
+	return gnuLicenses[m[1]+"/"+m[2]]
+}
+
+// isGNUTemplate returns true if t belongs to the GNU license family.
+func isGNUTemplate(t *Template) bool {
+	if t == nil {
+		return false
+	}
+	for _, spdx := range gnuLicenses {
+		if strings.EqualFold(t.SPDX, spdx) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPreamble adjusts r, the best match of data, with the GNU license
+// referenced by a notice preamble of data, if any. Notices alone do not
+// match license texts, so they get preambleScore. Notices along a license
+// text pick the GNU family member they reference, when its text matches
+// within gnuVersionDelta of the best one.
+func matchPreamble(r MatchResult, data []byte, templates []*Template,
+	titleWeight float64, algorithm string) MatchResult {
+
+	spdx := findGNUPreamble(data)
+	if spdx == "" {
+		return r
+	}
+	t, err := findTemplate(templates, spdx)
+	if err != nil || r.Template == t && r.Score >= preambleScore {
+		return r
+	}
+	m := matchTemplate(data, t, titleWeight, algorithm)
+	if isGNUTemplate(r.Template) && r.Template != t &&
+		r.Score-m.Score <= gnuVersionDelta {
+		m.Second, m.SecondScore = r.Template, r.Score
+		return m
+	}
+	if r.Score >= preambleScore {
+		return r
+	}
+	m.Score = preambleScore
+	m.Preamble = true
+	m.ExtraWords, m.MissingWords = nil, nil
+	if r.Template != t {
+		m.Second, m.SecondScore = r.Template, r.Score
+	} else {
+		m.Second, m.SecondScore = r.Second, r.SecondScore
+	}
+	return m
+}
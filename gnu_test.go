@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestGNUPreamble(t *testing.T) {
+	err := compareTestLicenses([]string{"colors/coral", "colors/gold", "colors/khaki"},
+		[]testResult{
+			{Package: "colors/coral", License: "GNU Affero General Public License v3.0",
+				Score: 90},
+			{Package: "colors/gold", License: "GNU General Public License v2.0",
+				Score: 100},
+			{Package: "colors/khaki", License: "GNU General Public License v2.0",
+				Score: 90},
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		Text string
+		SPDX string
+	}{
+		{"This library is free software; you can redistribute it and/or modify it " +
+			"under the terms of the GNU Lesser General Public License as published by " +
+			"the Free Software Foundation; either version 2.1 of the License", "LGPL-2.1"},
+		{"# This program is free software: you can redistribute it and/or modify\n" +
+			"# it under the terms of the GNU General Public License as published by\n" +
+			"# the Free Software Foundation, either version 3 of the License", "GPL-3.0"},
+		{"This program is free software; you can redistribute it and/or modify it " +
+			"under the terms of the GNU General Public License version 2 as " +
+			"published by the Free Software Foundation.", "GPL-2.0"},
+		{"This program is free software; you can redistribute it and/or modify it " +
+			"under the terms of the GNU General Public License version 7", ""},
+		{"Permission is hereby granted, free of charge", ""},
+	}
+	for _, test := range tests {
+		if got := findGNUPreamble([]byte(test.Text)); got != test.SPDX {
+			t.Errorf("expected %q, got %q for: %s", test.SPDX, got, test.Text)
+		}
+	}
+}
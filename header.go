@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// headerScore is the score of licenses inferred from source file headers.
+// Headers only reference a license, so the match is less trustworthy than a
+// full license text.
+const headerScore = 0.8
+
+// noticeScore is the score of licenses inferred from headers holding the
+// notice a license defines to apply it to a file in place of its text.
+const noticeScore = 0.95
+
+// headerPattern recognizes a license reference in source file headers.
+type headerPattern struct {
+	re   *regexp.Regexp
+	spdx string
+	// score is the score of matched licenses, headerScore if zero.
+	score float64
+}
+
+var headerPatterns = []headerPattern{
+	{
+		re:   regexp.MustCompile(`spdx-license-identifier:\s*apache-2\.0\b`),
+		spdx: "Apache-2.0",
+	},
+	{
+		// Apache-2.0 boilerplate notice, see the license appendix.
+		re: regexp.MustCompile(`licensed under the apache license, version 2\.0` +
+			`.*apache\.org/licenses/license-2\.0`),
+		spdx: "Apache-2.0",
+	},
+	{
+		re:   regexp.MustCompile(`spdx-license-identifier:\s*mpl-2\.0\b`),
+		spdx: "MPL-2.0",
+	},
+	{
+		// MPL-2.0 Exhibit A notice, which the license accepts in source
+		// files in place of its text.
+		re: regexp.MustCompile(`this source code form is subject to the terms of the ` +
+			`mozilla public license,? (v\.|version) ?2\.0`),
+		spdx:  "MPL-2.0",
+		score: noticeScore,
+	},
+}
+
+// readHeader returns the comments preceding the package clause of Go source
+// data, lowercased, without comment markers and joined on a single line.
+func readHeader(data []byte) string {
+	lines := []string{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "package ") {
+			break
+		}
+		line = strings.TrimLeft(line, "/*")
+		line = strings.TrimSuffix(line, "*/")
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, strings.ToLower(line))
+		}
+	}
+	return strings.Join(lines, " ")
+}
+
+// findLicenseHeader looks for license headers in non-test Go files of dir. It
+// returns the first file with a recognized header and the matching pattern,
+// or an empty string and nil if there is none.
+func findLicenseHeader(dir string) (string, *headerPattern, error) {
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return "", nil, err
+	}
+	names := []string{}
+	for _, fi := range fis {
+		name := fi.Name()
+		if fi.Mode().IsRegular() && strings.HasSuffix(name, ".go") &&
+			!strings.HasSuffix(name, "_test.go") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return "", nil, err
+		}
+		header := readHeader(data)
+		for i, p := range headerPatterns {
+			if p.re.MatchString(header) {
+				return path, &headerPatterns[i], nil
+			}
+		}
+	}
+	return "", nil, nil
+}
+
+// leadingComment returns the lines of the first comment block of Go source
+// data, without comment markers. Blocks end at blank lines, so a license
+// notice separated from the package documentation is returned alone.
+func leadingComment(data []byte) []string {
+	lines := []string{}
+	inBlock := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case inBlock:
+			if i := strings.Index(line, "*/"); i >= 0 {
+				line, inBlock = line[:i], false
+			}
+			lines = append(lines, strings.TrimSpace(strings.TrimLeft(line, "*")))
+		case strings.HasPrefix(line, "//"):
+			lines = append(lines, strings.TrimSpace(strings.TrimPrefix(line, "//")))
+		case strings.HasPrefix(line, "/*"):
+			line = strings.TrimPrefix(line, "/*")
+			if i := strings.Index(line, "*/"); i >= 0 {
+				line = line[:i]
+			} else {
+				inBlock = true
+			}
+			lines = append(lines, strings.TrimSpace(line))
+		case line == "" && len(lines) == 0:
+		default:
+			return lines
+		}
+	}
+	return lines
+}
+
+// docSource is the License.Source of licenses matched in doc.go files.
+const docSource = "doc.go"
+
+// docMinScore is the minimum score of doc.go license notices. Package
+// documentation matches no template that well, so it is not mistaken for a
+// license.
+const docMinScore = 0.8
+
+// matchDocNotice matches the leading comment block of the doc.go file of
+// package info against templates, where single file packages often put a
+// short license notice. It sets l license from it and returns
+// true if the match scores at least docMinScore.
+func matchDocNotice(l *License, info *PkgInfo, templates []*Template,
+	opts Options) (bool, error) {
+
+	path := filepath.Join(info.Dir, docSource)
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	lines := leadingComment(data)
+	if len(lines) == 0 {
+		return false, nil
+	}
+	r := MatchLicense(templates, []byte(strings.Join(lines, "\n")), opts)
+	if r.Template == nil || r.Score < docMinScore {
+		return false, nil
+	}
+	l.Path, err = filepath.Rel(licenseBase(info), path)
+	if err != nil {
+		return false, err
+	}
+	l.Template = r.Template
+	l.Score = r.Score
+	l.TextScore = r.Score
+	l.ExtraWords = r.ExtraWords
+	l.MissingWords = r.MissingWords
+	l.Common = r.Common
+	l.LicenseWords = r.LicenseWords
+	l.TemplateWords = r.TemplateWords
+	l.Second = r.Second
+	l.SecondScore = r.SecondScore
+	l.Source = docSource
+	return true, nil
+}
+
+// matchHeader sets l template from a license header of package info source
+// files, with a headerScore score unless the header pattern sets another, if
+// any is recognized.
+func matchHeader(l *License, info *PkgInfo, templates []*Template) error {
+	path, p, err := findLicenseHeader(info.Dir)
+	if err != nil || path == "" {
+		return err
+	}
+	t, err := findTemplate(templates, p.spdx)
+	if err != nil {
+		return nil
+	}
+	l.Path, err = filepath.Rel(licenseBase(info), path)
+	if err != nil {
+		return err
+	}
+	score := p.score
+	if score == 0 {
+		score = headerScore
+	}
+	l.Template = t
+	l.Score = score
+	l.TextScore = score
+	l.Header = true
+	return nil
+}
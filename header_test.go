@@ -0,0 +1,104 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestApacheHeader(t *testing.T) {
+	err := compareTestLicenses([]string{"colors/lime"}, []testResult{
+		{Package: "colors/lime", License: "Apache License 2.0", Score: 80},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	gopath, err := filepath.Abs("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	licenses, err := listLicenses(&GoEnv{GOPATH: gopath}, []string{"colors/lime"},
+		Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := licenses[0]
+	if !l.Header || l.Path != filepath.Join("colors", "lime", "lime.go") {
+		t.Fatalf("license header expected, got %+v", l)
+	}
+}
+
+func TestMPLNotice(t *testing.T) {
+	err := compareTestLicenses([]string{"colors/mint"}, []testResult{
+		{Package: "colors/mint", License: "Mozilla Public License 2.0", Score: 95},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, header := range []string{
+		"spdx-license-identifier: mpl-2.0",
+		"this source code form is subject to the terms of the mozilla public license, version 2.0.",
+	} {
+		matched := false
+		for _, p := range headerPatterns {
+			matched = matched || (p.re.MatchString(header) && p.spdx == "MPL-2.0")
+		}
+		if !matched {
+			t.Errorf("MPL-2.0 header not recognized: %q", header)
+		}
+	}
+}
+
+func TestSPDXHeader(t *testing.T) {
+	header := readHeader([]byte("/*\n * SPDX-License-Identifier: Apache-2.0\n */\n\n" +
+		"package foo\n// SPDX-License-Identifier: MIT\n"))
+	if header != "spdx-license-identifier: apache-2.0" {
+		t.Fatalf("unexpected header: %q", header)
+	}
+	if !headerPatterns[0].re.MatchString(header) {
+		t.Fatalf("SPDX header not recognized: %q", header)
+	}
+}
+
+func TestLeadingComment(t *testing.T) {
+	tests := []struct {
+		Source string
+		Lines  []string
+	}{
+		{"\n// Copyright\n//\n// MIT\n\n// Package foo\npackage foo\n",
+			[]string{"Copyright", "", "MIT"}},
+		{"/*\n * Copyright\n * MIT\n */\npackage foo\n",
+			[]string{"", "Copyright", "MIT", ""}},
+		{"/* MIT */\n// Package foo\npackage foo\n", []string{"MIT", "Package foo"}},
+		{"package foo\n// MIT\n", []string{}},
+	}
+	for _, test := range tests {
+		lines := leadingComment([]byte(test.Source))
+		if !reflect.DeepEqual(lines, test.Lines) {
+			t.Errorf("%q: expected %q, got %q", test.Source, test.Lines, lines)
+		}
+	}
+}
+
+func TestDocNotice(t *testing.T) {
+	err := compareTestLicenses([]string{"colors/cobalt"}, []testResult{
+		{Package: "colors/cobalt", License: "MIT License", Score: 98, Missing: 2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	gopath, err := filepath.Abs("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	licenses, err := listLicenses(&GoEnv{GOPATH: gopath}, []string{"colors/cobalt"},
+		Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := licenses[0]
+	if l.Source != docSource || l.Header ||
+		l.Path != filepath.Join("colors", "cobalt", "doc.go") {
+		t.Fatalf("doc.go license expected, got %+v", l)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// LicenseCommit is a commit which touched a license file.
+type LicenseCommit struct {
+	Hash    string
+	Date    string
+	Subject string
+}
+
+// licenseCommits returns the commits of the git repository holding path which
+// touched the license file or directory at path, most recent first. Renames
+// of license files are followed.
+func licenseCommits(path string) ([]LicenseCommit, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, fmt.Errorf("could not find git executable in PATH")
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	dir, name := path, "."
+	args := []string{"log", "--format=%H%x09%ad%x09%s", "--date=short"}
+	if !fi.IsDir() {
+		// --follow only supports a single file.
+		dir, name = filepath.Split(path)
+		args = append(args, "--follow")
+	}
+	out, err := runIn(dir, "git", append(args, "--", name)...)
+	if err != nil {
+		return nil, err
+	}
+	commits := []LicenseCommit{}
+	for _, line := range strings.Split(out, "\n") {
+		parts := strings.SplitN(line, "\t", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		commits = append(commits, LicenseCommit{
+			Hash:    parts[0],
+			Date:    parts[1],
+			Subject: parts[2],
+		})
+	}
+	return commits, nil
+}
+
+// printLicenseHistory writes the commits which touched the license of
+// package pkg to w, one per line, most recent first.
+func printLicenseHistory(env *GoEnv, w io.Writer, pkg string) error {
+	path, err := locateLicense(env, pkg)
+	if err != nil {
+		return err
+	}
+	commits, err := licenseCommits(path)
+	if err != nil {
+		return err
+	}
+	if len(commits) == 0 {
+		return fmt.Errorf("%s is not tracked by git", path)
+	}
+	for _, c := range commits {
+		hash := c.Hash
+		if len(hash) > 12 {
+			hash = hash[:12]
+		}
+		_, err := fmt.Fprintf(w, "%s  %s  %s\n", c.Date, hash, c.Subject)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestLicenseCommits(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+	dir, err := ioutil.TempDir("", "licenses")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	git := func(args ...string) {
+		args = append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"},
+			args...)
+		_, err := runIn(dir, "git", args...)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	write := func(name, content string) {
+		err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	git("init", "-q")
+	write("COPYING", "MIT License\n")
+	git("add", "COPYING")
+	git("commit", "-q", "-m", "Add MIT license")
+	write("red.go", "package red\n")
+	git("add", "red.go")
+	git("commit", "-q", "-m", "Add red")
+	git("mv", "COPYING", "LICENSE")
+	git("commit", "-q", "-m", "Rename license")
+	write("LICENSE", "Apache License\n")
+	git("commit", "-q", "-a", "-m", "Relicense under Apache")
+
+	commits, err := licenseCommits(filepath.Join(dir, "LICENSE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	subjects := []string{}
+	for _, c := range commits {
+		if len(c.Hash) != 40 || len(c.Date) != len("2006-01-02") {
+			t.Fatalf("unexpected commit: %+v", c)
+		}
+		subjects = append(subjects, c.Subject)
+	}
+	if len(subjects) != 3 || subjects[0] != "Relicense under Apache" ||
+		subjects[1] != "Rename license" || subjects[2] != "Add MIT license" {
+		t.Fatalf("unexpected license history: %v", subjects)
+	}
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"bytes"
+	"html"
+	"regexp"
+)
+
+// reHTMLEntity matches named, decimal and hexadecimal HTML character
+// references, like "&copy;", "&#169;" or "&#xA9;".
+var reHTMLEntity = regexp.MustCompile(`&(?:[a-zA-Z]+\d*|#\d+|#[xX][0-9a-fA-F]+);`)
+
+// decodeHTMLText returns data with HTML comment delimiters removed, keeping
+// the commented out text, and HTML character references decoded, so escaped
+// licenses, like "&copy; 2020 ... &amp; ...", have the words of plain ones.
+func decodeHTMLText(data []byte) []byte {
+	data = bytes.Replace(data, []byte("<!--"), []byte(" "), -1)
+	data = bytes.Replace(data, []byte("-->"), []byte(" "), -1)
+	return reHTMLEntity.ReplaceAllFunc(data, func(entity []byte) []byte {
+		return []byte(html.UnescapeString(string(entity)))
+	})
+}
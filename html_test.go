@@ -0,0 +1,23 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestDecodeHTMLText(t *testing.T) {
+	got := string(decodeHTMLText([]byte(
+		"<!-- Copyright &copy; M&eacute;zard &amp; co, &#169;&#xA9; AT&T &copy -->")))
+	if got != "  Copyright © Mézard & co, ©© AT&T &copy  " {
+		t.Fatalf("unexpected decoded text: %q", got)
+	}
+}
+
+func TestHTMLEscapedLicense(t *testing.T) {
+	// colors/mauve is an MIT license, commented out and HTML escaped.
+	err := compareTestLicenses([]string{"colors/mauve"}, []testResult{
+		{Package: "colors/mauve", License: "MIT License", Score: 98, Missing: 2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// includeMaxSize is the size above which license files are not considered
+// references to other files, but license texts mentioning paths.
+const includeMaxSize = 1024
+
+// reIncludePath matches relative slash separated paths in license files, like
+// "./docs/LICENSE.txt" or "docs/LICENSE.txt", quoted or not.
+var reIncludePath = regexp.MustCompile("(?:^|[\\s\"'(<`])" +
+	"((?:\\.{1,2}/|[\\w-][\\w.-]*/)[^\\s\"'()<>`]+)")
+
+// includeCandidates returns the relative paths referenced by data, like "The
+// full license text is in ./docs/LICENSE.txt.", in order. URLs are ignored.
+func includeCandidates(data []byte) []string {
+	paths := []string{}
+	for _, m := range reIncludePath.FindAllSubmatch(data, -1) {
+		p := strings.TrimRight(string(m[1]), ".,;:!?")
+		if strings.Contains(p, "://") || strings.HasSuffix(p, "/") {
+			continue
+		}
+		paths = append(paths, p)
+	}
+	return paths
+}
+
+// findInclude returns the path, relative to root, of the file referenced by
+// the license file at path, relative to root as well, if it is small enough
+// to be a reference rather than a license text. References are relative to
+// the license file directory and must point to a regular file beneath it.
+// It returns an empty string if there is none.
+func findInclude(root, path string) (string, error) {
+	fpath := filepath.Join(root, path)
+	fi, err := os.Stat(fpath)
+	if err != nil {
+		return "", err
+	}
+	if !fi.Mode().IsRegular() || fi.Size() > includeMaxSize {
+		return "", nil
+	}
+	data, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Dir(fpath)
+	for _, p := range includeCandidates(decodeLicenseData(data)) {
+		target := filepath.Join(dir, filepath.FromSlash(p))
+		rel, err := filepath.Rel(dir, target)
+		if err != nil || rel == "." || rel == ".." ||
+			strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		fi, err := os.Stat(target)
+		if err != nil || !fi.Mode().IsRegular() || target == fpath {
+			continue
+		}
+		return filepath.Join(filepath.Dir(path), rel), nil
+	}
+	return "", nil
+}
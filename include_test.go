@@ -0,0 +1,98 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestIncludeCandidates(t *testing.T) {
+	tests := []struct {
+		Text  string
+		Paths []string
+	}{
+		{"The full license text is in ./docs/LICENSE.txt.", []string{"./docs/LICENSE.txt"}},
+		{"See \"legal/COPYING\", or `../LICENSE`", []string{"legal/COPYING", "../LICENSE"}},
+		{"See https://example.com/LICENSE for details", []string{}},
+		{"Licensed under the MIT license, see LICENSE.", []string{}},
+		{"and/or modify it", []string{"and/or"}},
+	}
+	for _, test := range tests {
+		paths := includeCandidates([]byte(test.Text))
+		if !reflect.DeepEqual(paths, test.Paths) {
+			t.Errorf("%q: expected %v, got %v", test.Text, test.Paths, paths)
+		}
+	}
+}
+
+func TestFindInclude(t *testing.T) {
+	root, err := ioutil.TempDir("", "licenses-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	files := map[string]string{
+		"LICENSE.txt":         "MIT License",
+		"pkg/docs/LICENSE.md": "MIT License",
+		"pkg/a/LICENSE":       "The full license text is in ./../docs/LICENSE.md.",
+		"pkg/b/LICENSE":       "See ../../LICENSE.txt or docs/missing.txt",
+		"pkg/LICENSE":         "The full license text is in ./docs/LICENSE.md.",
+		"pkg/c/LICENSE":       "Licensed under the terms in ./LICENSE",
+	}
+	for name, content := range files {
+		fpath := filepath.Join(root, filepath.FromSlash(name))
+		err := os.MkdirAll(filepath.Dir(fpath), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ioutil.WriteFile(fpath, []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		Path     string
+		Included string
+	}{
+		{"pkg/LICENSE", filepath.Join("pkg", "docs", "LICENSE.md")},
+		// References must stay beneath the license file directory.
+		{"pkg/a/LICENSE", ""},
+		{"pkg/b/LICENSE", ""},
+		// Nor point to the license file itself.
+		{"pkg/c/LICENSE", ""},
+		{"LICENSE.txt", ""},
+	}
+	for _, test := range tests {
+		included, err := findInclude(root, filepath.FromSlash(test.Path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if included != test.Included {
+			t.Errorf("%s: expected %q, got %q", test.Path, test.Included, included)
+		}
+	}
+}
+
+func TestIncludedLicense(t *testing.T) {
+	err := compareTestLicenses([]string{"colors/azure"}, []testResult{
+		{Package: "colors/azure", License: "MIT License", Score: 98, Missing: 2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	gopath, err := filepath.Abs("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	licenses, err := listLicenses(&GoEnv{GOPATH: gopath}, []string{"colors/azure"}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wanted := filepath.Join("colors", "azure", "docs", "LICENSE.txt")
+	if len(licenses) != 1 || licenses[0].Included != wanted ||
+		licenses[0].Path != filepath.Join("colors", "azure", "LICENSE") {
+		t.Fatalf("unexpected included license: %+v", licenses)
+	}
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+)
+
+// JSONMatch holds the word counts the match score is computed from, so
+// alternative metrics can be derived offline.
+type JSONMatch struct {
+	Common        int `json:"common"`
+	LicenseWords  int `json:"license_words"`
+	TemplateWords int `json:"template_words"`
+}
+
+// JSONSecond describes the second best matching template.
+type JSONSecond struct {
+	License string  `json:"license"`
+	SPDX    string  `json:"spdx,omitempty"`
+	Score   float64 `json:"score"`
+}
+
+// JSONLicense is the JSON representation of a License.
+type JSONLicense struct {
+	Package      string      `json:"package"`
+	Version      string      `json:"version,omitempty"`
+	Projects     []string    `json:"projects,omitempty"`
+	Status       Status      `json:"status,omitempty"`
+	License      string      `json:"license,omitempty"`
+	SPDX         string      `json:"spdx,omitempty"`
+	Language     string      `json:"language,omitempty"`
+	URL          string      `json:"url,omitempty"`
+	Category     string      `json:"category,omitempty"`
+	Obligations  []string    `json:"obligations,omitempty"`
+	Score        float64     `json:"score"`
+	TextScore    float64     `json:"text_score"`
+	Path         string      `json:"path,omitempty"`
+	Notice       string      `json:"notice,omitempty"`
+	Hash         string      `json:"hash,omitempty"`
+	Error        string      `json:"error,omitempty"`
+	ExtraWords   []string    `json:"extra_words,omitempty"`
+	MissingWords []string    `json:"missing_words,omitempty"`
+	Diff         []string    `json:"diff,omitempty"`
+	Trimmed      bool        `json:"trimmed,omitempty"`
+	Header       bool        `json:"header,omitempty"`
+	Source       string      `json:"source,omitempty"`
+	Preamble     bool        `json:"preamble,omitempty"`
+	Files        []string    `json:"files,omitempty"`
+	Alternatives bool        `json:"alternatives,omitempty"`
+	Truncated    bool        `json:"truncated,omitempty"`
+	Violation    string      `json:"violation,omitempty"`
+	Trusted      bool        `json:"trusted,omitempty"`
+	Changed      bool        `json:"changed,omitempty"`
+	Replaced     string      `json:"replaced,omitempty"`
+	Override     bool        `json:"override,omitempty"`
+	Declared     string      `json:"declared,omitempty"`
+	DeclaredOnly bool        `json:"declared_only,omitempty"`
+	Mismatch     bool        `json:"declared_mismatch,omitempty"`
+	Included     string      `json:"included,omitempty"`
+	Embedded     bool        `json:"embedded,omitempty"`
+	MainModule   bool        `json:"main_module,omitempty"`
+	Similar      []string    `json:"similar,omitempty"`
+	ImportChain  []string    `json:"import_chain,omitempty"`
+	Match        *JSONMatch  `json:"match,omitempty"`
+	Second       *JSONSecond `json:"second,omitempty"`
+	// Members are the grouped packages licenses, with -group-members.
+	Members []JSONLicense `json:"members,omitempty"`
+}
+
+func makeJSONLicense(l License) JSONLicense {
+	j := JSONLicense{
+		Package:      l.Package,
+		Version:      l.Version,
+		Projects:     l.Projects,
+		Status:       l.Status,
+		Category:     l.Category,
+		Obligations:  l.Obligations,
+		Score:        l.Score,
+		TextScore:    l.TextScore,
+		Path:         l.Path,
+		Notice:       l.Notice,
+		Hash:         l.Hash,
+		Error:        l.Err,
+		ExtraWords:   l.ExtraWords,
+		MissingWords: l.MissingWords,
+		Diff:         l.Diff,
+		Trimmed:      l.Trimmed,
+		Header:       l.Header,
+		Source:       l.Source,
+		Preamble:     l.Preamble,
+		Alternatives: l.Alternatives,
+		Truncated:    l.Truncated(),
+		Violation:    l.Violation,
+		Trusted:      l.Trusted,
+		Changed:      l.Changed,
+		Replaced:     l.Replaced,
+		Override:     l.Override,
+		Declared:     l.Declared,
+		DeclaredOnly: l.DeclaredOnly,
+		Mismatch:     l.DeclaredMismatch,
+		Included:     l.Included,
+		Embedded:     l.Embedded,
+		MainModule:   l.MainModule,
+		Similar:      l.Similar,
+		ImportChain:  l.ImportChain,
+	}
+	for _, f := range l.Files {
+		j.Files = append(j.Files, f.Path)
+	}
+	if l.Template != nil {
+		j.License = l.Title()
+		j.SPDX = l.Template.SPDX
+		if l.Alternatives {
+			j.SPDX = l.Expression()
+		}
+		j.Language = l.Template.Language
+		j.URL = l.URL()
+		j.Match = &JSONMatch{
+			Common:        l.Common,
+			LicenseWords:  l.LicenseWords,
+			TemplateWords: l.TemplateWords,
+		}
+	}
+	if l.Second != nil {
+		j.Second = &JSONSecond{
+			License: l.Second.Title,
+			SPDX:    l.Second.SPDX,
+			Score:   l.SecondScore,
+		}
+	}
+	for _, m := range l.Members {
+		j.Members = append(j.Members, makeJSONLicense(m))
+	}
+	return j
+}
+
+// JSONReport is the JSON representation of a scan.
+type JSONReport struct {
+	Metadata *Metadata     `json:"metadata,omitempty"`
+	Licenses []JSONLicense `json:"licenses"`
+}
+
+// encodeJSON writes v as JSON to w, on a single line or indented if pretty
+// is true. Object fields follow the struct fields order in both cases.
+func encodeJSON(w io.Writer, v interface{}, pretty bool) error {
+	encoder := json.NewEncoder(w)
+	if pretty {
+		encoder.SetIndent("", "  ")
+	}
+	return encoder.Encode(v)
+}
+
+// writeJSON writes licenses and scan metadata, if not nil, as a JSON object
+// to w, indented if pretty is true.
+func writeJSON(w io.Writer, licenses []License, meta *Metadata, pretty bool) error {
+	out := JSONReport{
+		Metadata: meta,
+		Licenses: make([]JSONLicense, 0, len(licenses)),
+	}
+	for _, l := range licenses {
+		out.Licenses = append(out.Licenses, makeJSONLicense(l))
+	}
+	return encodeJSON(w, out, pretty)
+}
+
+// JSONTemplate is the JSON representation of a detectable license.
+type JSONTemplate struct {
+	Title    string `json:"title"`
+	SPDX     string `json:"spdx,omitempty"`
+	Language string `json:"language,omitempty"`
+	URL      string `json:"url,omitempty"`
+}
+
+// writeTemplatesJSON writes the title and SPDX identifier of every template
+// as a JSON array to w, indented if pretty is true.
+func writeTemplatesJSON(w io.Writer, templates []*Template, pretty bool) error {
+	out := make([]JSONTemplate, 0, len(templates))
+	for _, t := range templates {
+		out = append(out, JSONTemplate{
+			Title:    t.Title,
+			SPDX:     t.SPDX,
+			Language: t.Language,
+			URL:      t.URL,
+		})
+	}
+	return encodeJSON(w, out, pretty)
+}
+
+// JSONError is the JSON representation of a fatal error.
+type JSONError struct {
+	Error string `json:"error"`
+}
+
+// writeJSONError writes err as a JSON object to w, on a single line.
+func writeJSONError(w io.Writer, err error) error {
+	return encodeJSON(w, JSONError{Error: err.Error()}, false)
+}
+
+// writeJSONReport writes licenses as JSON in report file, see writeJSON.
+func writeJSONReport(report string, licenses []License, meta *Metadata,
+	pretty bool) error {
+
+	out, err := os.Create(report)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	err = writeJSON(out, licenses, meta, pretty)
+	if err != nil {
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestJSONMatch(t *testing.T) {
+	gopath, err := filepath.Abs("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	licenses, err := listLicenses(&GoEnv{GOPATH: gopath}, []string{"colors/red"},
+		Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := &bytes.Buffer{}
+	err = writeJSON(buf, licenses, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := JSONReport{}
+	err = json.Unmarshal(buf.Bytes(), &report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed := report.Licenses
+	if len(parsed) != 1 || parsed[0].Match == nil {
+		t.Fatalf("unexpected JSON output: %s", buf.String())
+	}
+	m := parsed[0].Match
+	score := 2 * float64(m.Common) / float64(m.LicenseWords+m.TemplateWords)
+	if score != parsed[0].Score || parsed[0].SPDX != "MIT" {
+		t.Fatalf("inconsistent JSON match: %s", buf.String())
+	}
+}
+
+func TestTemplatesJSON(t *testing.T) {
+	templates, err := loadTemplates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := &bytes.Buffer{}
+	err = writeTemplatesJSON(buf, templates, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed := []JSONTemplate{}
+	err = json.Unmarshal(buf.Bytes(), &parsed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed) != len(templates) {
+		t.Fatalf("%d templates expected, got %s", len(templates), buf.String())
+	}
+	found := false
+	for _, p := range parsed {
+		if p.Title == "MIT License" && p.SPDX == "MIT" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("MIT License not listed: %s", buf.String())
+	}
+}
+
+func TestJSONMetadata(t *testing.T) {
+	gopath, err := filepath.Abs("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := &GoEnv{GOPATH: gopath}
+	meta, err := collectMetadata(env, []string{"colors/red"}, nil, 0.9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.GOPATH != gopath || meta.ToolVersion == "" || meta.Confidence != 0.9 ||
+		!strings.HasPrefix(meta.GoVersion, "go version ") || meta.Timestamp.IsZero() {
+		t.Fatalf("unexpected metadata: %+v", meta)
+	}
+	buf := &bytes.Buffer{}
+	err = writeJSON(buf, nil, meta, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := map[string]json.RawMessage{}
+	err = json.Unmarshal(buf.Bytes(), &report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(report["licenses"]) != "[]" || len(report["metadata"]) == 0 {
+		t.Fatalf("unexpected JSON report: %s", buf.String())
+	}
+}
+
+func TestJSONError(t *testing.T) {
+	buf := &bytes.Buffer{}
+	err := writeJSONError(buf, fmt.Errorf(`cannot find "colors/missing"`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed := map[string]string{}
+	err = json.Unmarshal(buf.Bytes(), &parsed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed) != 1 || parsed["error"] != `cannot find "colors/missing"` {
+		t.Fatalf("unexpected JSON error: %s", buf.String())
+	}
+}
+
+func TestPrettyJSON(t *testing.T) {
+	mit := &Template{Title: "MIT License", SPDX: "MIT"}
+	licenses := []License{{Package: "colors/red", Template: mit, Score: 1}}
+	compact, pretty := &bytes.Buffer{}, &bytes.Buffer{}
+	err := writeJSON(compact, licenses, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = writeJSON(pretty, licenses, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(compact.String(), "\n") != 1 ||
+		!strings.Contains(pretty.String(), "\n  \"licenses\": [\n") {
+		t.Fatalf("unexpected JSON layouts:\n%s\n%s", compact.String(), pretty.String())
+	}
+	indented := &bytes.Buffer{}
+	err = json.Indent(indented, compact.Bytes(), "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if indented.String() != pretty.String() {
+		t.Fatalf("fields order differs:\n%s\n%s", indented.String(), pretty.String())
+	}
+}
@@ -3,17 +3,26 @@ package main
 import (
 	"bufio"
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"math"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"text/tabwriter"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/pmezard/licenses/assets"
 )
@@ -21,9 +30,133 @@ import (
 type Template struct {
 	Title    string
 	Nickname string
-	Words    map[string]int
+	SPDX     string
+	// Language is the language code of translated templates, like "de",
+	// and empty for English ones.
+	Language string
+	// URL is the canonical address of the license text, from the "source"
+	// front matter field or the SPDX license list.
+	URL string
+	// TitleLen is the number of words of the template text first line,
+	// usually the license title.
+	TitleLen int
+	// text is the template text, from which words and tail are computed on
+	// first use, see Words.
+	text  []byte
+	once  sync.Once
+	words map[string]int
+	// counts holds the number of occurrences of every word, see Counts.
+	counts map[string]int
+	tail   []string
+	// optionalText holds the optional parts of SPDX license templates, from
+	// which optional is computed, see Optional.
+	optionalText []byte
+	optional     map[string]int
+	// official is the SPDX license template of the license, if loaded, see
+	// loadSPDXTemplates.
+	official *Template
 }
 
+// Words returns the template word set, see makeWordSet. Word sets are
+// computed on first use, so templates which are never matched cost nothing,
+// and safely from concurrent matchers.
+func (t *Template) Words() map[string]int {
+	t.compute()
+	return t.words
+}
+
+// Counts returns the number of occurrences of every template word, see
+// makeWordCounts, or nil for templates built from word sets.
+func (t *Template) Counts() map[string]int {
+	t.compute()
+	return t.counts
+}
+
+// Optional returns the words of the template optional parts which are not
+// required words. They are neither missing when absent from licenses nor
+// extra when present. Only SPDX license templates have optional parts.
+func (t *Template) Optional() map[string]int {
+	t.compute()
+	return t.optional
+}
+
+// Tail returns the last words of the template text, marking the end of the
+// license.
+func (t *Template) Tail() []string {
+	t.compute()
+	return t.tail
+}
+
+func (t *Template) compute() {
+	t.once.Do(func() {
+		if t.text == nil {
+			return
+		}
+		t.words = makeWordSet(t.text)
+		t.counts = makeWordCounts(t.text)
+		t.tail = lastWords(t.text, templateTailSize)
+		t.text = nil
+		if t.optionalText != nil {
+			t.optional = makeWordSet(t.optionalText)
+			for w := range t.words {
+				delete(t.optional, w)
+			}
+			t.optionalText = nil
+		}
+	})
+}
+
+// templateTailSize is the number of words used to locate the end of a license.
+const templateTailSize = 5
+
+// minTemplateWords is the minimum number of distinct words of a template text.
+// Smaller word sets make scores meaningless.
+const minTemplateWords = 20
+
+// shortTemplates lists the titles of templates exempted from
+// minTemplateWords and from having an SPDX identifier. "No License" is a bare
+// copyright line, whose match tells files reserving all rights apart from
+// unknown licenses.
+var shortTemplates = map[string]bool{
+	"No License": true,
+}
+
+// validateTemplate returns an error if t cannot be matched meaningfully.
+func validateTemplate(t *Template) error {
+	if t.Title == "" {
+		return fmt.Errorf("missing title")
+	}
+	if shortTemplates[t.Title] {
+		return nil
+	}
+	n := 0
+	if t.text != nil {
+		n = countTextWords(t.text)
+	} else {
+		n = len(t.Words())
+	}
+	if n < minTemplateWords {
+		return fmt.Errorf("%q has %d words, at least %d expected", t.Title, n,
+			minTemplateWords)
+	}
+	return nil
+}
+
+// countTextWords returns the number of distinct words of text, about the size
+// of its word set, see makeWordSet, without cleaning it up first. It is cheap
+// enough to check templates at load time, their word sets being lazily
+// computed.
+func countTextWords(text []byte) int {
+	words := map[string]bool{}
+	for _, w := range reWords.FindAll(bytes.ToLower(text), -1) {
+		words[string(w)] = true
+	}
+	return len(words)
+}
+
+// spdxURL is the base address of SPDX license list pages.
+const spdxURL = "https://spdx.org/licenses/"
+
 func parseTemplate(content string) (*Template, error) {
 	t := Template{}
 	text := []byte{}
@@ -43,38 +176,216 @@ func parseTemplate(content string) (*Template, error) {
 					t.Title = strings.TrimSpace(line[len("title:"):])
 				} else if strings.HasPrefix(line, "nickname:") {
 					t.Nickname = strings.TrimSpace(line[len("nickname:"):])
+				} else if strings.HasPrefix(line, "spdx-id:") {
+					t.SPDX = strings.TrimSpace(line[len("spdx-id:"):])
+				} else if strings.HasPrefix(line, "language:") {
+					t.Language = strings.TrimSpace(line[len("language:"):])
+				} else if strings.HasPrefix(line, "source:") {
+					t.URL = strings.TrimSpace(line[len("source:"):])
 				}
 			}
 		} else if state == 2 {
+			if t.TitleLen == 0 && line != "" {
+				t.TitleLen = len(reWords.FindAll(cleanLicenseData([]byte(line)), -1))
+			}
 			text = append(text, scanner.Bytes()...)
 			text = append(text, []byte("\n")...)
 		}
 	}
-	t.Words = makeWordSet(text)
+	if t.URL == "" && t.SPDX != "" {
+		t.URL = spdxURL + t.SPDX + ".html"
+	}
+	t.text = text
 	return &t, scanner.Err()
 }
 
+// loadTemplates parses and validates the embedded templates, see
+// validateTemplate. Their word sets are computed on first use.
 func loadTemplates() ([]*Template, error) {
 	templates := []*Template{}
 	for _, a := range assets.Assets {
 		templ, err := parseTemplate(a.Content)
+		if err == nil {
+			err = validateTemplate(templ)
+		}
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("invalid template %s: %s", a.Name, err)
 		}
 		templates = append(templates, templ)
 	}
+	// Sort templates so ties are always broken the same way when matching.
+	sort.Stable(templatesByTitle(templates))
 	return templates, nil
 }
 
+// checkTemplates checks every template with validateTemplate.
+func checkTemplates(templates []*Template) error {
+	for _, t := range templates {
+		if err := validateTemplate(t); err != nil {
+			return fmt.Errorf("invalid template %s: %s", t.Title, err)
+		}
+	}
+	return nil
+}
+
+// duplicateTemplates returns the groups of templates with identical word
+// sets, in templates order. Only the first template of a group can be the
+// best match, the others are unreachable, which denotes a corpus problem.
+// Templates whose word set is merely included in another one remain
+// reachable, as they score better on their own text.
+func duplicateTemplates(templates []*Template) [][]*Template {
+	groups := map[string][]*Template{}
+	keys := []string{}
+	for _, t := range templates {
+		words := []string{}
+		for w := range t.Words() {
+			words = append(words, w)
+		}
+		sort.Strings(words)
+		key := strings.Join(words, " ")
+		if len(groups[key]) == 0 {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], t)
+	}
+	duplicates := [][]*Template{}
+	for _, key := range keys {
+		if len(groups[key]) > 1 {
+			duplicates = append(duplicates, groups[key])
+		}
+	}
+	return duplicates
+}
+
+// warnDuplicateTemplates writes a warning to w for every group of templates
+// with identical word sets, see duplicateTemplates.
+func warnDuplicateTemplates(w io.Writer, templates []*Template) error {
+	for _, group := range duplicateTemplates(templates) {
+		titles := []string{}
+		for _, t := range group {
+			titles = append(titles, strconv.Quote(t.Title))
+		}
+		_, err := fmt.Fprintf(w, "warning: templates %s have identical word sets, "+
+			"only %s can be matched\n", strings.Join(titles, ", "), titles[0])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type templatesByTitle []*Template
+
+func (t templatesByTitle) Len() int {
+	return len(t)
+}
+
+func (t templatesByTitle) Less(i, j int) bool {
+	return t[i].Title < t[j].Title
+}
+
+func (t templatesByTitle) Swap(i, j int) {
+	t[i], t[j] = t[j], t[i]
+}
+
+// printTemplates writes loaded templates title, nickname, SPDX identifier and
+// word set size to w.
+func printTemplates(w io.Writer, templates []*Template) error {
+	tw := tabwriter.NewWriter(w, 1, 4, 2, ' ', 0)
+	_, err := tw.Write([]byte("TITLE\tNICKNAME\tSPDX\tLANGUAGE\tWORDS\n"))
+	if err != nil {
+		return err
+	}
+	for _, t := range templates {
+		_, err = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\n", t.Title, t.Nickname, t.SPDX,
+			t.Language, len(t.Words()))
+		if err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
 var (
-	reWords     = regexp.MustCompile(`[\w']+`)
+	// Go \w only matches ASCII characters, spell out unicode letters,
+	// combining marks and digits instead.
+	reWords     = regexp.MustCompile(`[\p{L}\p{M}\p{N}_']+`)
 	reCopyright = regexp.MustCompile(
 		`(?i)\s*Copyright (?:©|\(c\)|\xC2\xA9)?\s*(?:\d{4}|\[year\]).*`)
 )
 
+// placeholder describes a template region which real licenses replace with a
+// project or organization name. Matched regions are rewritten to a fixed
+// replacement in both templates and license files.
+type placeholder struct {
+	re   *regexp.Regexp
+	repl []byte
+}
+
+var placeholders = []placeholder{
+	{
+		re:   regexp.MustCompile(`\[(?:fullname|project|owner|organization|year)\]`),
+		repl: nil,
+	},
+	{
+		re:   regexp.MustCompile(`(neither the name of)\s(?s:.{0,100}?)\s(nor the names of)`),
+		repl: []byte("$1 $2"),
+	},
+	{
+		re:   regexp.MustCompile(`(provided by)\s(?s:.{0,100}?)\s(and contributors)`),
+		repl: []byte("$1 the copyright holders $2"),
+	},
+	{
+		re:   regexp.MustCompile(`(in no event shall)\s(?s:.{0,100}?)\s(or contributors)`),
+		repl: []byte("$1 the copyright holder $2"),
+	},
+}
+
+// reEnumeration matches clause numbers starting a line, like "1.", "2.1",
+// "(a)", "b." or "iv)", so numbered and unnumbered variants of a license
+// produce the same words.
+var reEnumeration = regexp.MustCompile(
+	`(?m)^[ \t]*(?:\d+(?:\.\d+)*\.?|\((?:\d+|[a-z]|[ivxl]+)\)|(?:[a-z]|[ivxl]+)[.)])(?:[ \t]+|$)`)
+
+// reFrontMatterLine matches YAML front matter lines: keys, list items,
+// indented values, comments or blank lines.
+var reFrontMatterLine = regexp.MustCompile(`^(?:[\w-]+\s*:.*|\s+.*|-.*|#.*|)$`)
+
+// stripFrontMatter removes leading YAML front matter, delimited by "---"
+// lines like in Jekyll pages and templates, from data. Blocks with lines
+// which do not look like YAML, like text between markdown rules, are kept.
+func stripFrontMatter(data []byte) []byte {
+	text := bytes.TrimLeft(data, " \t\r\n")
+	eol := bytes.IndexByte(text, '\n')
+	if eol < 0 || string(bytes.TrimSpace(text[:eol])) != "---" {
+		return data
+	}
+	for pos := eol + 1; pos < len(text); {
+		n := bytes.IndexByte(text[pos:], '\n')
+		if n < 0 {
+			n = len(text) - pos
+		}
+		line := bytes.TrimRight(text[pos:pos+n], "\r")
+		if string(bytes.TrimSpace(line)) == "---" {
+			return text[pos+n:]
+		}
+		if !reFrontMatterLine.Match(line) {
+			break
+		}
+		pos += n + 1
+	}
+	return data
+}
+
 func cleanLicenseData(data []byte) []byte {
+	data = stripFrontMatter(data)
+	data = decodeHTMLText(data)
 	data = bytes.ToLower(data)
 	data = reCopyright.ReplaceAll(data, nil)
+	data = reEnumeration.ReplaceAll(data, nil)
+	for _, p := range placeholders {
+		data = p.re.ReplaceAll(data, p.repl)
+	}
 	return data
 }
 
@@ -94,6 +405,63 @@ func makeWordSet(data []byte) map[string]int {
 	return words
 }
 
+// makeWordCounts returns the number of occurrences of every word of data, the
+// term frequencies of its word set, see makeWordSet.
+func makeWordCounts(data []byte) map[string]int {
+	counts := map[string]int{}
+	for _, m := range reWords.FindAll(cleanLicenseData(data), -1) {
+		counts[string(m)]++
+	}
+	return counts
+}
+
+// lastWords returns the last n words of data, after cleaning.
+func lastWords(data []byte, n int) []string {
+	matches := reWords.FindAll(cleanLicenseData(data), -1)
+	if len(matches) > n {
+		matches = matches[len(matches)-n:]
+	}
+	words := []string{}
+	for _, m := range matches {
+		words = append(words, string(m))
+	}
+	return words
+}
+
+// trimTrailing returns data truncated after the last occurrence of template
+// tail words and true, or data and false if the tail was not found or nothing
+// follows it.
+func trimTrailing(data []byte, t *Template) ([]byte, bool) {
+	tail := t.Tail()
+	if len(tail) == 0 {
+		return data, false
+	}
+	lower := bytes.ToLower(data)
+	if len(lower) != len(data) {
+		return data, false
+	}
+	locs := reWords.FindAllIndex(lower, -1)
+	for i := len(locs) - len(tail); i >= 0; i-- {
+		found := true
+		for j, w := range tail {
+			loc := locs[i+j]
+			if string(lower[loc[0]:loc[1]]) != w {
+				found = false
+				break
+			}
+		}
+		if !found {
+			continue
+		}
+		last := i + len(tail)
+		if last == len(locs) {
+			return data, false
+		}
+		return data[:locs[last-1][1]], true
+	}
+	return data, false
+}
+
 type Word struct {
 	Text string
 	Pos  int
@@ -118,6 +486,24 @@ type MatchResult struct {
 	Score        float64
 	ExtraWords   []string
 	MissingWords []string
+	// Trimmed is true if text following the license was ignored.
+	Trimmed bool
+	// Preamble is true if the template was inferred from a GNU notice
+	// preamble, see matchPreamble.
+	Preamble bool
+	// Common, LicenseWords and TemplateWords are the number of words shared
+	// by the license and the template, and their respective word set sizes.
+	// Score is derived from them.
+	Common        int
+	LicenseWords  int
+	TemplateWords int
+	// Second is the second best matching template, and SecondScore its
+	// score.
+	Second      *Template
+	SecondScore float64
+	// Diff is the unified word diff of the template and the license, when
+	// Options.Diff is set, see unifiedWordDiff.
+	Diff []string
 }
 
 func sortAndReturnWords(words []Word) []string {
@@ -129,52 +515,190 @@ func sortAndReturnWords(words []Word) []string {
 	return tokens
 }
 
+// compareWords compares license words with template ones and returns their
+// similarity score between 0 and 1, the number of words they have in common,
+// along with the words appearing only in the license or only in the template.
+//
+// If titleWeight is positive, words first appearing in the template title
+// weigh titleWeight instead of 1 in the score, so the title shared by
+// licenses of the same family does not dominate it. The score itself is
+// computed by score, from the weighted word counts, or from the weighted
+// term frequency vectors if counts, the license word counts, is not nil, see
+// termProducts. License words optional in the template, see
+// Template.Optional, are left out.
+func compareWords(words, counts map[string]int, t *Template, titleWeight float64,
+	score ScoreFunc) (float64, int, []Word, []Word) {
+	templateWords := t.Words()
+	optional := t.Optional()
+	licenseWords := len(words)
+	extra := []Word{}
+	missing := []Word{}
+	common := 0
+	// Title words are counted apart and weighted at the end, so scores do
+	// not depend on the words iteration order.
+	isTitle := func(w string) bool {
+		pos, ok := templateWords[w]
+		return ok && titleWeight > 0 && pos < t.TitleLen
+	}
+	titleCommon, titleWords := 0, 0
+	for w, pos := range words {
+		_, ok := templateWords[w]
+		if ok {
+			common++
+			if isTitle(w) {
+				titleCommon++
+			}
+		} else if _, ok := optional[w]; ok {
+			licenseWords--
+		} else {
+			extra = append(extra, Word{
+				Text: w,
+				Pos:  pos,
+			})
+		}
+	}
+	for w, pos := range templateWords {
+		if isTitle(w) {
+			titleWords++
+		}
+		if _, ok := words[w]; !ok {
+			missing = append(missing, Word{
+				Text: w,
+				Pos:  pos,
+			})
+		}
+	}
+	weigh := func(n, title int) float64 {
+		return float64(n-title) + titleWeight*float64(title)
+	}
+	if counts != nil {
+		s := score(termProducts(counts, t, titleWeight, isTitle))
+		return s, common, extra, missing
+	}
+	// Title words found in the license are common ones.
+	s := score(weigh(common, titleCommon), weigh(licenseWords, titleCommon),
+		weigh(len(templateWords), titleWords))
+	return s, common, extra, missing
+}
+
+// termProducts returns the dot product of the license and t term frequency
+// vectors, from counts and t.Counts, and their squared norms, title words
+// weighing titleWeight, see compareWords. Templates without counts have every
+// word once.
+func termProducts(counts map[string]int, t *Template, titleWeight float64,
+	isTitle func(string) bool) (float64, float64, float64) {
+
+	templateWords, templateCounts := t.Words(), t.Counts()
+	optional := t.Optional()
+	frequency := func(w string) float64 {
+		if templateCounts == nil {
+			return 1
+		}
+		return float64(templateCounts[w])
+	}
+	weight := func(w string) float64 {
+		if isTitle(w) {
+			return titleWeight
+		}
+		return 1
+	}
+	dot, license, template := 0., 0., 0.
+	for w, n := range counts {
+		_, ok := templateWords[w]
+		if _, opt := optional[w]; !ok && opt {
+			continue
+		}
+		x := float64(n)
+		license += weight(w) * x * x
+		if ok {
+			dot += weight(w) * x * frequency(w)
+		}
+	}
+	for w := range templateWords {
+		y := frequency(w)
+		template += weight(w) * y * y
+	}
+	return dot, license, template
+}
+
 // matchTemplates returns the best license template matching supplied data,
 // its score between 0 and 1 and the list of words appearing in license but not
-// in the matched template.
-func matchTemplates(license []byte, templates []*Template) MatchResult {
-	bestScore := float64(-1)
-	var bestTemplate *Template
+// in the matched template. See compareWords for titleWeight, and scoreFuncs
+// for algorithm names.
+func matchTemplates(license []byte, templates []*Template, titleWeight float64,
+	algorithm string) MatchResult {
+	score := scoreFunc(algorithm)
+	counts := licenseCounts(license, algorithm)
+	bestScore, secondScore := float64(-1), float64(-1)
+	bestCommon := 0
+	var bestTemplate, secondTemplate, bestCompared *Template
 	bestExtra := []Word{}
 	bestMissing := []Word{}
 	words := makeWordSet(license)
 	for _, t := range templates {
-		extra := []Word{}
-		missing := []Word{}
-		common := 0
-		for w, pos := range words {
-			_, ok := t.Words[w]
-			if ok {
-				common++
-			} else {
-				extra = append(extra, Word{
-					Text: w,
-					Pos:  pos,
-				})
-			}
-		}
-		for w, pos := range t.Words {
-			if _, ok := words[w]; !ok {
-				missing = append(missing, Word{
-					Text: w,
-					Pos:  pos,
-				})
-			}
-		}
-		score := 2 * float64(common) / (float64(len(words)) + float64(len(t.Words)))
-		if score > bestScore {
-			bestScore = score
+		s, common, extra, missing, compared := compareTemplate(words, counts, t,
+			titleWeight, score)
+		if s > bestScore {
+			secondScore = bestScore
+			secondTemplate = bestTemplate
+			bestScore = s
+			bestCommon = common
 			bestTemplate = t
+			bestCompared = compared
 			bestMissing = missing
 			bestExtra = extra
+		} else if s > secondScore {
+			secondScore = s
+			secondTemplate = t
 		}
 	}
-	return MatchResult{
+	m := MatchResult{
 		Template:     bestTemplate,
 		Score:        bestScore,
 		ExtraWords:   sortAndReturnWords(bestExtra),
 		MissingWords: sortAndReturnWords(bestMissing),
+		Common:       bestCommon,
+		LicenseWords: len(words),
+	}
+	if secondTemplate != nil {
+		m.Second = secondTemplate
+		m.SecondScore = secondScore
+	}
+	if bestTemplate != nil {
+		m.TemplateWords = len(bestCompared.Words())
+	}
+	return m
+}
+
+// matchTemplate returns the result of matching supplied data against a single
+// template, whether it is the best one or not.
+func matchTemplate(license []byte, t *Template, titleWeight float64,
+	algorithm string) MatchResult {
+	words := makeWordSet(license)
+	score, common, extra, missing, compared := compareTemplate(words,
+		licenseCounts(license, algorithm), t, titleWeight, scoreFunc(algorithm))
+	return MatchResult{
+		Template:      t,
+		Score:         score,
+		ExtraWords:    sortAndReturnWords(extra),
+		MissingWords:  sortAndReturnWords(missing),
+		Common:        common,
+		LicenseWords:  len(words),
+		TemplateWords: len(compared.Words()),
+	}
+}
+
+// findTemplate returns the template whose title, nickname or SPDX identifier
+// matches name, ignoring case.
+func findTemplate(templates []*Template, name string) (*Template, error) {
+	for _, t := range templates {
+		if strings.EqualFold(t.Title, name) ||
+			(t.Nickname != "" && strings.EqualFold(t.Nickname, name)) ||
+			(t.SPDX != "" && strings.EqualFold(t.SPDX, name)) {
+			return t, nil
+		}
 	}
+	return nil, fmt.Errorf("unknown license template: %s", name)
 }
 
 // fixEnv returns a copy of the process environment where GOPATH is adjusted to
@@ -183,17 +707,161 @@ func fixEnv(gopath string) []string {
 	if gopath == "" {
 		return nil
 	}
+	return setEnv(os.Environ(), "GOPATH", gopath)
+}
+
+// setEnv returns a copy of environ where key is set to value.
+func setEnv(environ []string, key, value string) []string {
 	kept := []string{
-		"GOPATH=" + gopath,
+		key + "=" + value,
 	}
-	for _, env := range os.Environ() {
-		if !strings.HasPrefix(env, "GOPATH=") {
+	for _, env := range environ {
+		if !strings.HasPrefix(env, key+"=") {
 			kept = append(kept, env)
 		}
 	}
 	return kept
 }
 
+// lookupEnv returns the value of key in environ, or an empty string.
+func lookupEnv(environ []string, key string) string {
+	for _, env := range environ {
+		if strings.HasPrefix(env, key+"=") {
+			return env[len(key)+1:]
+		}
+	}
+	return ""
+}
+
+// offlineEnv lists the environment variables preventing go commands from
+// accessing the network. Modules must be in the module cache or vendored.
+var offlineEnv = [][2]string{
+	{"GOPROXY", "off"},
+	{"GOSUMDB", "off"},
+	{"GOTOOLCHAIN", "local"},
+}
+
+// isVendored returns true if the module holding dir, the current directory if
+// empty, has a vendor directory.
+func isVendored(dir string) bool {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			fi, err := os.Stat(filepath.Join(dir, "vendor"))
+			return err == nil && fi.IsDir()
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return false
+		}
+		dir = parent
+	}
+}
+
+// GoEnv describes how go commands are invoked.
+type GoEnv struct {
+	// GOPATH overrides the process GOPATH when not empty.
+	GOPATH string
+	// Mod is forwarded to go list as -mod value when not empty. Otherwise,
+	// the ambient GOFLAGS setting applies.
+	Mod string
+	// Dir is the directory go commands run in, the current one if empty.
+	Dir string
+	// Offline prevents go commands from accessing the network.
+	Offline bool
+	// Go is the go binary to run, looked up in PATH unless it is a path.
+	// It defaults to "go".
+	Go string
+}
+
+// binary returns the go binary to run.
+func (env *GoEnv) binary() string {
+	if env.Go != "" {
+		return env.Go
+	}
+	return "go"
+}
+
+// environ returns the go commands environment, nil meaning the process one.
+func (env *GoEnv) environ() []string {
+	environ := fixEnv(env.GOPATH)
+	if env.Offline {
+		if environ == nil {
+			environ = os.Environ()
+		}
+		for _, kv := range offlineEnv {
+			environ = setEnv(environ, kv[0], kv[1])
+		}
+		// Resolve modules from the module cache rather than failing on
+		// go.mod updates, unless -mod is set or modules are vendored.
+		flags := lookupEnv(environ, "GOFLAGS")
+		if env.Mod == "" && !strings.Contains(flags, "-mod=") && !isVendored(env.Dir) {
+			environ = setEnv(environ, "GOFLAGS", strings.TrimSpace(flags+" -mod=mod"))
+		}
+	}
+	return environ
+}
+
+// commandError returns an error describing the failure of cmd with output.
+func (env *GoEnv) commandError(cmd *exec.Cmd, output string) error {
+	if env.Offline && strings.Contains(output, "GOPROXY=off") {
+		return fmt.Errorf("'go %s' failed because some modules are not "+
+			"available locally in offline mode:\n%s",
+			strings.Join(cmd.Args[1:], " "), output)
+	}
+	return fmt.Errorf("'go %s' failed with:\n%s",
+		strings.Join(cmd.Args[1:], " "), output)
+}
+
+// command returns a go command running subcmd with supplied arguments, in an
+// environment honoring env settings.
+func (env *GoEnv) command(subcmd string, args ...string) *exec.Cmd {
+	cmdArgs := []string{subcmd}
+	if env.Mod != "" {
+		cmdArgs = append(cmdArgs, "-mod="+env.Mod)
+	}
+	cmdArgs = append(cmdArgs, args...)
+	cmd := exec.Command(env.binary(), cmdArgs...)
+	cmd.Env = env.environ()
+	cmd.Dir = env.Dir
+	return cmd
+}
+
+// checkGo returns an error if the go executable cannot be found.
+func (env *GoEnv) checkGo() error {
+	if env.Go != "" {
+		_, err := exec.LookPath(env.Go)
+		if err != nil {
+			return fmt.Errorf("could not find go executable %s: %s%s", env.Go, err,
+				offlineModes)
+		}
+		return nil
+	}
+	_, err := exec.LookPath("go")
+	if err != nil {
+		return fmt.Errorf("could not find go executable: Go must be installed " +
+			"and its bin directory listed in PATH to list packages" + offlineModes)
+	}
+	return nil
+}
+
+// offlineModes suggests the modes working without go in checkGo errors.
+const offlineModes = ". Without go, -manifest FILE reports modules of a " +
+	"go mod download -json manifest, -binary PATH the dependencies of a Go " +
+	"binary and -submodules the git submodules of the current repository"
+
+// isMissingPackage returns true if go list output reports missing packages or
+// packages without Go files.
+func isMissingPackage(output string) bool {
+	return strings.Contains(output, "cannot find package") ||
+		strings.Contains(output, "no buildable Go source files") ||
+		strings.Contains(output, "no Go files in") ||
+		strings.Contains(output, "can't load package")
+}
+
 type MissingError struct {
 	Err string
 }
@@ -205,21 +873,15 @@ func (err *MissingError) Error() string {
 // expandPackages takes a list of package or package expressions and invoke go
 // list to expand them to packages. In particular, it handles things like "..."
 // and ".".
-func expandPackages(gopath string, pkgs []string) ([]string, error) {
-	args := []string{"list"}
-	args = append(args, pkgs...)
-	cmd := exec.Command("go", args...)
-	cmd.Env = fixEnv(gopath)
+func expandPackages(env *GoEnv, pkgs []string) ([]string, error) {
+	cmd := env.command("list", pkgs...)
 	out, err := cmd.CombinedOutput()
 	if err != nil {
 		output := string(out)
-		if strings.Contains(output, "cannot find package") ||
-			strings.Contains(output, "no buildable Go source files") ||
-			strings.Contains(output, "can't load package") {
+		if isMissingPackage(output) {
 			return nil, &MissingError{Err: output}
 		}
-		return nil, fmt.Errorf("'go %s' failed with:\n%s",
-			strings.Join(args, " "), output)
+		return nil, env.commandError(cmd, output)
 	}
 	names := []string{}
 	for _, s := range strings.Split(string(out), "\n") {
@@ -231,25 +893,23 @@ func expandPackages(gopath string, pkgs []string) ([]string, error) {
 	return names, nil
 }
 
-func listPackagesAndDeps(gopath string, pkgs []string) ([]string, error) {
-	pkgs, err := expandPackages(gopath, pkgs)
+// listPackagesAndDeps returns the packages matching supplied package
+// expressions, and the sorted list of these packages and their dependencies.
+func listPackagesAndDeps(env *GoEnv, pkgs []string) ([]string, []string, error) {
+	pkgs, err := expandPackages(env, pkgs)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	args := []string{"list", "-f", "{{range .Deps}}{{.}}|{{end}}"}
+	args := []string{"-f", "{{range .Deps}}{{.}}|{{end}}"}
 	args = append(args, pkgs...)
-	cmd := exec.Command("go", args...)
-	cmd.Env = fixEnv(gopath)
+	cmd := env.command("list", args...)
 	out, err := cmd.CombinedOutput()
 	if err != nil {
 		output := string(out)
-		if strings.Contains(output, "cannot find package") ||
-			strings.Contains(output, "no buildable Go source files") ||
-			strings.Contains(output, "can't load package") {
-			return nil, &MissingError{Err: output}
+		if isMissingPackage(output) {
+			return nil, nil, &MissingError{Err: output}
 		}
-		return nil, fmt.Errorf("'go %s' failed with:\n%s",
-			strings.Join(args, " "), output)
+		return nil, nil, env.commandError(cmd, output)
 	}
 	deps := []string{}
 	seen := map[string]bool{}
@@ -267,11 +927,102 @@ func listPackagesAndDeps(gopath string, pkgs []string) ([]string, error) {
 		}
 	}
 	sort.Strings(deps)
-	return deps, nil
+	return pkgs, deps, nil
+}
+
+// listImports returns the imports of deps packages, by import path. It costs
+// an additional go list invocation over all dependencies.
+func listImports(env *GoEnv, deps []string) (map[string][]string, error) {
+	args := []string{"-e", "-f", "{{.ImportPath}}|{{join .Imports \"|\"}}"}
+	args = append(args, deps...)
+	cmd := env.command("list", args...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, env.commandError(cmd, string(out))
+	}
+	imports := map[string][]string{}
+	for _, line := range strings.Split(string(out), "\n") {
+		parts := strings.Split(strings.TrimSpace(line), "|")
+		if parts[0] == "" {
+			continue
+		}
+		imports[parts[0]] = parts[1:]
+	}
+	return imports, nil
+}
+
+// walkImports visits packages reachable from roots through at most maxDepth
+// imports, or all of them if maxDepth is negative, breadth first. It returns
+// the importer of every visited package, roots having none, so following
+// importers gives shortest import chains.
+func walkImports(roots []string, imports map[string][]string, maxDepth int) map[string]string {
+	importers := map[string]string{}
+	depths := map[string]int{}
+	queue := []string{}
+	for _, root := range roots {
+		importers[root] = ""
+		depths[root] = 0
+		queue = append(queue, root)
+	}
+	for len(queue) > 0 {
+		pkg := queue[0]
+		queue = queue[1:]
+		if maxDepth >= 0 && depths[pkg] >= maxDepth {
+			continue
+		}
+		for _, imp := range imports[pkg] {
+			if _, ok := depths[imp]; ok || imp == "" {
+				continue
+			}
+			importers[imp] = pkg
+			depths[imp] = depths[pkg] + 1
+			queue = append(queue, imp)
+		}
+	}
+	return importers
+}
+
+// limitDepth returns deps entries reachable from roots through at most
+// maxDepth imports. Roots are at depth 0.
+func limitDepth(roots, deps []string, imports map[string][]string, maxDepth int) []string {
+	reached := walkImports(roots, imports, maxDepth)
+	kept := []string{}
+	for _, dep := range deps {
+		if _, ok := reached[dep]; ok {
+			kept = append(kept, dep)
+		}
+	}
+	return kept
+}
+
+// importChain returns the import paths leading from a root package to pkg,
+// both included, given the importers returned by walkImports, or nil if pkg
+// was not reached.
+func importChain(importers map[string]string, pkg string) []string {
+	if _, ok := importers[pkg]; !ok {
+		return nil
+	}
+	chain := []string{}
+	for p := pkg; p != ""; p = importers[p] {
+		chain = append([]string{p}, chain...)
+	}
+	return chain
 }
 
-func listStandardPackages(gopath string) ([]string, error) {
-	return expandPackages(gopath, []string{"std", "cmd"})
+// listStandardPackages maps standard packages import paths to the pattern
+// they were expanded from, either "std" or "cmd".
+func listStandardPackages(env *GoEnv) (map[string]string, error) {
+	std := map[string]string{}
+	for _, source := range []string{"std", "cmd"} {
+		pkgs, err := expandPackages(env, []string{source})
+		if err != nil {
+			return nil, err
+		}
+		for _, pkg := range pkgs {
+			std[pkg] = source
+		}
+	}
+	return std, nil
 }
 
 type PkgError struct {
@@ -283,39 +1034,105 @@ type PkgInfo struct {
 	Dir        string
 	Root       string
 	ImportPath string
+	Goroot     bool
+	Module     *PkgModule
 	Error      *PkgError
 }
 
-func getPackagesInfo(gopath string, pkgs []string) ([]*PkgInfo, error) {
-	args := []string{"list", "-e", "-json"}
+// skipToJSON returns data starting at the first line beginning with "{".
+func skipToJSON(data []byte) []byte {
+	for pos := 0; pos < len(data); {
+		if data[pos] == '{' {
+			return data[pos:]
+		}
+		n := bytes.IndexByte(data[pos:], '\n')
+		if n < 0 {
+			break
+		}
+		pos += n + 1
+	}
+	return data
+}
+
+// snippet returns up to 200 bytes of data around offset.
+func snippet(data []byte, offset int) string {
+	start, end := offset-100, offset+100
+	if start < 0 {
+		start = 0
+	}
+	if end > len(data) {
+		end = len(data)
+	}
+	if start > end {
+		start = end
+	}
+	return string(data[start:end])
+}
+
+func getPackagesInfo(env *GoEnv, pkgs []string) ([]*PkgInfo, error) {
+	args := []string{"-e", "-json"}
 	// TODO: split the list for platforms which do not support massive argument
 	// lists.
 	args = append(args, pkgs...)
-	cmd := exec.Command("go", args...)
-	cmd.Env = fixEnv(gopath)
-	out, err := cmd.CombinedOutput()
+	cmd := env.command("list", args...)
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	err := cmd.Run()
 	if err != nil {
-		return nil, fmt.Errorf("go %s failed with:\n%s",
-			strings.Join(args, " "), string(out))
+		return nil, env.commandError(cmd, stdout.String()+stderr.String())
 	}
-	infos := make([]*PkgInfo, 0, len(pkgs))
+	// Build warnings may precede the JSON stream.
+	return decodePackagesInfo(skipToJSON(stdout.Bytes()), pkgs)
+}
+
+// decodePackagesInfo decodes go list -json output and returns the information
+// of every package in pkgs, in order. Packages are matched by import path, as
+// go list may report errored packages out of order or without import path.
+// Requested packages missing from the output are returned with an error,
+// borrowed from unmatched errored entries if any.
+func decodePackagesInfo(out []byte, pkgs []string) ([]*PkgInfo, error) {
+	byPath := map[string]*PkgInfo{}
+	unmatched := []*PkgInfo{}
 	decoder := json.NewDecoder(bytes.NewBuffer(out))
-	for _, pkg := range pkgs {
+	for {
 		info := &PkgInfo{}
 		err := decoder.Decode(info)
+		if err == io.EOF {
+			break
+		}
 		if err != nil {
-			return nil, fmt.Errorf("could not retrieve package information for %s", pkg)
+			return nil, fmt.Errorf(
+				"could not retrieve package information: %s, near:\n%s",
+				err, snippet(out, int(decoder.InputOffset())))
 		}
-		if pkg != info.ImportPath {
-			return nil, fmt.Errorf("package information mismatch: asked for %s, got %s",
-				pkg, info.ImportPath)
+		if info.ImportPath == "" {
+			if info.Error != nil {
+				unmatched = append(unmatched, info)
+			}
+			continue
 		}
-		if info.Error != nil && info.Name == "" {
-			info.Name = info.ImportPath
+		byPath[info.ImportPath] = info
+	}
+	infos := make([]*PkgInfo, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		info, ok := byPath[pkg]
+		if !ok {
+			info = &PkgInfo{
+				ImportPath: pkg,
+				Error:      &PkgError{Err: "go list returned no information"},
+			}
+			if len(unmatched) > 0 {
+				info.Error = unmatched[0].Error
+				unmatched = unmatched[1:]
+			}
+		}
+		if info.Error != nil && info.Name == "" {
+			info.Name = info.ImportPath
 		}
 		infos = append(infos, info)
 	}
-	return infos, err
+	return infos, nil
 }
 
 var (
@@ -326,11 +1143,18 @@ var (
 		`(licen[sc]e\.[^.]+)|` +
 		`(.*(?:un)?licen[sc]e.*)` +
 		`)$`)
+	// reNotice matches attribution notices shipped along licenses, like
+	// Apache NOTICE files or third party notices.
+	reNotice = regexp.MustCompile(
+		`(?i)^(?:notice|third[_-]?party(?:[_-]?(?:notices?|licen[sc]es?))?)(?:\.[^.]+)?$`)
 )
 
 // scoreLicenseName returns a factor between 0 and 1 weighting how likely
 // supplied filename is a license file.
 func scoreLicenseName(name string) float64 {
+	if reNotice.MatchString(name) {
+		return 0.
+	}
 	m := reLicense.FindStringSubmatch(name)
 	switch {
 	case m == nil:
@@ -349,52 +1173,796 @@ func scoreLicenseName(name string) float64 {
 	return 0.
 }
 
+// findNotice returns the path of the attribution notice file sitting next to
+// the license file or directory at licensePath, relative to root, or an empty
+// string if there is none.
+func findNotice(root, licensePath string) (string, error) {
+	dir := filepath.Dir(licensePath)
+	fis, err := ioutil.ReadDir(filepath.Join(root, dir))
+	if err != nil {
+		return "", err
+	}
+	for _, fi := range fis {
+		if fi.Mode().IsRegular() && reNotice.MatchString(fi.Name()) {
+			return filepath.Join(dir, fi.Name()), nil
+		}
+	}
+	return "", nil
+}
+
+// findLicenseIn looks for a license file in directory path, relative to src,
+// or a LICENSES subdirectory if there is none. Files whose name score, see
+// scoreLicenseName, is below minScore are ignored. It returns an empty string
+// if neither was found.
+func findLicenseIn(src, path string, minScore float64) (string, error) {
+	fis, err := ioutil.ReadDir(filepath.Join(src, path))
+	if err != nil {
+		return "", err
+	}
+	bestScore := float64(0)
+	bestName := ""
+	for _, fi := range fis {
+		if !fi.Mode().IsRegular() {
+			continue
+		}
+		score := scoreLicenseName(fi.Name())
+		if score > bestScore && score >= minScore {
+			bestScore = score
+			bestName = fi.Name()
+		}
+	}
+	if bestName != "" {
+		return filepath.Join(path, bestName), nil
+	}
+	for _, fi := range fis {
+		if fi.IsDir() && reLicenseDir.MatchString(fi.Name()) {
+			dir := filepath.Join(path, fi.Name())
+			ok, err := hasRegularFiles(filepath.Join(src, dir))
+			if err != nil {
+				return "", err
+			}
+			if ok {
+				return dir, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// isBeneath returns true if path is dir or one of its descendants.
+func isBeneath(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." &&
+		!strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// importPathDirs returns the directories of import path importPath and its
+// parents, deepest first, relative to $GOPATH/src. Import paths are slash
+// separated on every platform, unlike the returned directories.
+func importPathDirs(importPath string) []string {
+	dirs := []string{}
+	for p := importPath; p != "." && p != "/" && p != ""; p = path.Dir(p) {
+		dirs = append(dirs, filepath.FromSlash(p))
+	}
+	return dirs
+}
+
 // findLicense looks for license files in package import path, and down to
 // parent directories until a file is found or $GOPATH/src is reached. It
-// returns the path and score of the best entry, an empty string if none was
-// found.
-func findLicense(info *PkgInfo) (string, error) {
-	path := info.ImportPath
-	for ; path != "."; path = filepath.Dir(path) {
-		fis, err := ioutil.ReadDir(filepath.Join(info.Root, "src", path))
+// returns the path and score of the best entry, relative to licenseBase, an
+// empty string if none was found. When a directory has no license file but a
+// LICENSES subdirectory, the subdirectory path is returned instead.
+//
+// If top is not empty and contains the package directory, the search stops at
+// top instead of $GOPATH/src, wherever top is. Otherwise, packages belonging
+// to a module, like those of the module cache or local replacements, are
+// searched up to the module root directory, and local imports up to the
+// filesystem root. Files named less like licenses than minScore are ignored,
+// see findLicenseIn.
+func findLicense(info *PkgInfo, top string, minScore float64) (string, error) {
+	src := licenseBase(info)
+	stop := ""
+	if top != "" && info.Dir != "" && isBeneath(info.Dir, top) {
+		stop = top
+	} else if isLocalImport(info) {
+		stop = src
+	} else {
+		stop = moduleDir(info)
+	}
+	if stop != "" {
+		path, err := filepath.Rel(src, info.Dir)
 		if err != nil {
 			return "", err
 		}
-		bestScore := float64(0)
-		bestName := ""
-		for _, fi := range fis {
-			if !fi.Mode().IsRegular() {
-				continue
-			}
-			score := scoreLicenseName(fi.Name())
-			if score > bestScore {
-				bestScore = score
-				bestName = fi.Name()
+		stop, err := filepath.Rel(src, stop)
+		if err != nil {
+			return "", err
+		}
+		for {
+			found, err := findLicenseIn(src, path, minScore)
+			if err != nil || found != "" || path == stop || path == "." {
+				return found, err
 			}
+			path = filepath.Dir(path)
 		}
-		if bestName != "" {
-			return filepath.Join(path, bestName), nil
+	}
+	for _, dir := range importPathDirs(info.ImportPath) {
+		found, err := findLicenseIn(src, dir, minScore)
+		if err != nil || found != "" {
+			return found, err
+		}
+	}
+	if info.Goroot {
+		// Standard packages are covered by the LICENSE file at GOROOT,
+		// above the "src" directory.
+		fi, err := os.Stat(filepath.Join(info.Root, "LICENSE"))
+		if err == nil && fi.Mode().IsRegular() {
+			return filepath.Join("..", "LICENSE"), nil
 		}
 	}
 	return "", nil
 }
 
+// reLicenseDir matches directories holding one license file per license, as
+// recommended by the REUSE specification.
+var reLicenseDir = regexp.MustCompile(`(?i)^licen[sc]es$`)
+
+func hasRegularFiles(dir string) (bool, error) {
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return false, err
+	}
+	for _, fi := range fis {
+		if fi.Mode().IsRegular() {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// LicenseFile is the result of matching a single license file.
+type LicenseFile struct {
+	// Path is the file path relative to $GOPATH/src, like License.Path.
+	Path string
+	// Hash is the file content hash, see hashLicense.
+	Hash string
+	// Err describes why the file was not matched, like "empty license file".
+	Err string
+	MatchResult
+	// Explained holds the match against Options.Explain template, if any.
+	Explained *MatchResult
+}
+
 type License struct {
-	Package      string
-	Score        float64
-	Template     *Template
-	Path         string
+	Package string
+	Version string
+	// Status classifies the detection result, see setStatus.
+	Status Status
+	Score  float64
+	// TextScore is the template match score, before Options.NameWeight
+	// blends the license file name score into Score.
+	TextScore float64
+	Template  *Template
+	Path      string
+	// Category is the obligation category of the license, like "permissive"
+	// or "strong-copyleft", see categorize.
+	Category string
+	// Obligations holds notes about the active obligations of the license,
+	// see filterObligations.
+	Obligations []string
+	// Replaced describes the go.mod replacement of the package module, like
+	// "../fork", if any. The license is looked up in the replacement.
+	Replaced string
+	// Changed is true if the license hash differs from the one recorded in
+	// the baseline, see applyBaseline.
+	Changed bool
+	// Trusted is true if the package passes the policy because of its import
+	// path, see Policy.Trusted.
+	Trusted bool
+	// Header is true if the license was inferred from a source file header,
+	// Path being the source file, instead of a license file.
+	Header bool
+	// Source is "doc.go" if the license was matched from the leading notice
+	// of the package doc.go file, Path being that file, see matchDocNotice.
+	Source string
+	// Notice is the path of the attribution notice file next to the
+	// license, like Apache NOTICE files, relative to $GOPATH/src.
+	Notice       string
 	Err          string
 	ExtraWords   []string
 	MissingWords []string
+	// Diff is copied from MatchResult.
+	Diff []string
+	// Explained holds the match against Options.Explain template, if any.
+	Explained *MatchResult
+	// Trimmed is true if text following the license was ignored.
+	Trimmed bool
+	// Preamble is true if the license was inferred from a GNU notice
+	// preamble.
+	Preamble bool
+	// Declared is the SPDX license expression declared in the go.mod file
+	// of the package module, if any, see applyDeclared.
+	Declared string
+	// Override is true if the license was forced with Options.Overrides.
+	Override bool
+	// MainModule is true for packages of the project being analyzed, see
+	// isMainPackage, as opposed to third-party dependencies.
+	MainModule bool
+	// Embedded is true if the license file is embedded in the package with
+	// a //go:embed directive, see isEmbedded.
+	Embedded bool
+	// Included is the path, relative to the same root as Path, of the file
+	// referenced by the license file, which was matched instead, see
+	// findInclude.
+	Included string
+	// DeclaredOnly is true if the license was inferred from Declared alone.
+	DeclaredOnly bool
+	// DeclaredMismatch is true if the detected license disagrees with
+	// Declared.
+	DeclaredMismatch bool
+	// Common, LicenseWords and TemplateWords are copied from MatchResult.
+	Common        int
+	LicenseWords  int
+	TemplateWords int
+	// Second and SecondScore are copied from MatchResult.
+	Second      *Template
+	SecondScore float64
+	// Files lists every matched file when Path is a directory of license
+	// files, or a license file with alternatives. Other fields then describe
+	// the lowest scoring file.
+	Files []LicenseFile
+	// Alternatives is true if Files are alternative licenses, like
+	// LICENSE-MIT and LICENSE-APACHE in dual licensed projects, instead of
+	// licenses applying together. See findAlternatives and isDualLicensed.
+	Alternatives bool
+	// Hash is the license file content hash, see hashLicense. For directories,
+	// it is the hash of the files hashes.
+	Hash string
+	// Projects lists the projects using the package, when several projects
+	// are scanned.
+	Projects []string
+	// Violation describes how the license violates the policy, if it does.
+	Violation string
+	// ImportChain lists the import paths from a listed package to this
+	// one, both included, along a shortest import chain, with
+	// Options.ImportChains.
+	ImportChain []string
+	// Grouped lists the packages sharing the license file, grouped under
+	// their common import path prefix, see groupLicenses.
+	Grouped []string
+	// Members holds the licenses of the Grouped packages as they were
+	// before grouping, when requested, so structured outputs keep per
+	// package results.
+	Members []License
+	// Similar lists the packages whose license texts were collapsed into
+	// this one, see dedupeLicenses.
+	Similar []string
+	// file is the absolute path of the license file, if any.
+	file string
 }
 
-func listLicenses(gopath string, pkgs []string) ([]License, error) {
-	templates, err := loadTemplates()
+// Templates returns the matched templates, one per license file. Unmatched
+// files have nil templates.
+func (l *License) Templates() []*Template {
+	if len(l.Files) == 0 {
+		if l.Template == nil {
+			return nil
+		}
+		return []*Template{l.Template}
+	}
+	templates := []*Template{}
+	for _, f := range l.Files {
+		templates = append(templates, f.Template)
+	}
+	return templates
+}
+
+// truncatedRatio is the license to template word count ratio below which a
+// license file is suspected to be a stub or truncated.
+const truncatedRatio = 0.25
+
+// Truncated returns true if the license file has much fewer words than the
+// matched template, whatever the score.
+func (l *License) Truncated() bool {
+	return l.Template != nil && !l.Preamble &&
+		float64(l.LicenseWords) < truncatedRatio*float64(l.TemplateWords)
+}
+
+// Status classifies how a license was detected.
+type Status string
+
+const (
+	// StatusExact is a license matching its template exactly, or nearly.
+	StatusExact Status = "exact"
+	// StatusApproximate is a license matching its template with a score
+	// above the confidence threshold.
+	StatusApproximate Status = "approximate"
+	// StatusLowConfidence is a license whose best template score is below
+	// the confidence threshold.
+	StatusLowConfidence Status = "low-confidence"
+	// StatusUnknown is a license file matching no template.
+	StatusUnknown Status = "unknown"
+	// StatusMissing is a package without license file.
+	StatusMissing Status = "missing"
+	// StatusError is a package whose license could not be detected.
+	StatusError Status = "error"
+)
+
+// exactScore is the dice score above which matches are considered exact, see
+// scaleConfidence for other algorithms.
+const exactScore = .99
+
+// classify returns the status of l given confidence and exact match
+// thresholds.
+func (l *License) classify(confidence, exact float64) Status {
+	switch {
+	case l.Err != "":
+		return StatusError
+	case l.Template != nil && l.Score > exact:
+		return StatusExact
+	case l.Template != nil && l.Score >= confidence:
+		return StatusApproximate
+	case l.Template != nil:
+		return StatusLowConfidence
+	case l.Path != "":
+		return StatusUnknown
+	}
+	return StatusMissing
+}
+
+// strictConfidence returns the confidence threshold of -strict mode, where
+// only matches above the exact threshold count as identified.
+func strictConfidence(exact float64) float64 {
+	return math.Nextafter(exact, 1)
+}
+
+// applyStrict marks licenses which are not exact matches as unknown, whatever
+// their template, so they are reported and handled like unidentified ones.
+// Failed and missing licenses are left as is.
+func applyStrict(licenses []License) {
+	for i, l := range licenses {
+		if l.Status == StatusApproximate || l.Status == StatusLowConfidence {
+			licenses[i].Status = StatusUnknown
+			licenses[i].Category = CategoryUnknown
+		}
+	}
+}
+
+// setStatus computes the Status of every license, then its Category, which
+// depends on it.
+func setStatus(licenses []License, confidence, exact float64) {
+	for i := range licenses {
+		licenses[i].Status = licenses[i].classify(confidence, exact)
+		licenses[i].Category = categorize(&licenses[i])
+	}
+}
+
+// identified returns true if l matched a template exactly or approximately,
+// see setStatus.
+func (l *License) identified() bool {
+	return l.Status == StatusExact || l.Status == StatusApproximate
+}
+
+// isProblem returns true if the license is not identified, see setStatus,
+// looks truncated or violates the policy.
+func (l *License) isProblem() bool {
+	return !l.identified() || l.Truncated() || l.Violation != ""
+}
+
+// filterProblems returns licenses which are problems, see isProblem.
+func filterProblems(licenses []License) []License {
+	kept := []License{}
+	for _, l := range licenses {
+		if l.isProblem() {
+			kept = append(kept, l)
+		}
+	}
+	return kept
+}
+
+// Ambiguous returns true if the best and second best templates scores are
+// within delta of each other. It is always false if delta is not positive.
+func (l *License) Ambiguous(delta float64) bool {
+	return delta > 0 && l.Template != nil && l.Second != nil &&
+		len(l.Files) == 0 && l.Score-l.SecondScore <= delta
+}
+
+// AmbiguousTitle returns both best and second best template titles, marked as
+// uncertain.
+func (l *License) AmbiguousTitle() string {
+	return fmt.Sprintf("%s? / %s?", l.Template.Title, l.Second.Title)
+}
+
+// Title returns the matched template title, or the titles of all matched
+// templates joined with AND when the license is made of several files, or OR
+// when they are alternatives.
+func (l *License) Title() string {
+	if len(l.Files) == 0 {
+		if l.Template == nil {
+			return ""
+		}
+		return l.Template.Title
+	}
+	titles := []string{}
+	for _, f := range l.Files {
+		title := "?"
+		if f.Template != nil {
+			title = f.Template.Title
+		}
+		titles = append(titles, title)
+	}
+	return strings.Join(titles, l.operator())
+}
+
+// URL returns the canonical address of the matched template, or an empty
+// string if it is unknown or the license is made of several files.
+func (l *License) URL() string {
+	if len(l.Files) > 0 || l.Template == nil {
+		return ""
+	}
+	return l.Template.URL
+}
+
+// minPrintableRatio is the minimum fraction of printable characters in a
+// license file, below which it is considered binary.
+const minPrintableRatio = 0.9
+
+// archiveMagics lists the leading bytes of common archive formats.
+var archiveMagics = [][]byte{
+	[]byte("PK\x03\x04"),         // zip
+	[]byte("PK\x05\x06"),         // empty zip
+	[]byte("\x1f\x8b"),           // gzip
+	[]byte("\xfd7zXZ\x00"),       // xz
+	[]byte("7z\xbc\xaf\x27\x1c"), // 7z
+	[]byte("\x28\xb5\x2f\xfd"),   // zstd
+	[]byte("Rar!\x1a\x07"),       // rar
+	[]byte("!<arch>\n"),          // ar
+}
+
+// isArchive returns true if data starts like an archive.
+func isArchive(data []byte) bool {
+	for _, magic := range archiveMagics {
+		if bytes.HasPrefix(data, magic) {
+			return true
+		}
+	}
+	if len(data) > 3 && bytes.HasPrefix(data, []byte("BZh")) &&
+		data[3] >= '1' && data[3] <= '9' {
+		return true
+	}
+	// tar headers hold a "ustar" magic at offset 257.
+	return len(data) >= 262 && bytes.Equal(data[257:262], []byte("ustar"))
+}
+
+// checkLicenseData returns a description of the problem if data cannot be a
+// license text, because it is empty, an archive or binary, and an empty
+// string otherwise.
+func checkLicenseData(data []byte) string {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return "empty license file"
+	}
+	if isArchive(data) {
+		return "invalid license file (archive)"
+	}
+	if bytes.IndexByte(data, 0) >= 0 {
+		return "binary license file"
+	}
+	total, printable := 0, 0
+	for len(data) > 0 {
+		r, n := utf8.DecodeRune(data)
+		data = data[n:]
+		total++
+		if r != utf8.RuneError && (unicode.IsPrint(r) || unicode.IsSpace(r)) {
+			printable++
+		}
+	}
+	if float64(printable) < minPrintableRatio*float64(total) {
+		return "binary license file"
+	}
+	return ""
+}
+
+// filterLicenses returns licenses with a template identified by one of names,
+// which may be glob patterns, see matchesLicense.
+func filterLicenses(licenses []License, names []string) []License {
+	kept := []License{}
+	for _, l := range licenses {
+		for _, t := range l.Templates() {
+			if t != nil && matchesAny(names, t) {
+				kept = append(kept, l)
+				break
+			}
+		}
+	}
+	return kept
+}
+
+// normalizeEOL converts CRLF and CR line endings to LF.
+func normalizeEOL(data []byte) []byte {
+	data = bytes.Replace(data, []byte("\r\n"), []byte("\n"), -1)
+	return bytes.Replace(data, []byte("\r"), []byte("\n"), -1)
+}
+
+// hashLicense returns the hexadecimal SHA256 of license data, after line
+// endings normalization so CRLF and LF copies of a file hash the same.
+func hashLicense(data []byte) string {
+	h := sha256.Sum256(normalizeEOL(data))
+	return hex.EncodeToString(h[:])
+}
+
+// MatchLicense matches license text data against preloaded templates, as
+// returned by loadTemplates, honoring opts.TrimTrailing. Unlike listLicenses,
+// it does not load templates, so it can be called repeatedly. With
+// opts.NormalizeYears, templates must have been passed to
+// normalizeTemplateYears.
+func MatchLicense(templates []*Template, data []byte, opts Options) MatchResult {
+	r, _ := matchLicense(templates, data, opts)
+	return r
+}
+
+// matchLicense is MatchLicense also returning the matched data, which is
+// trimmed if r.Trimmed is true.
+func matchLicense(templates []*Template, data []byte, opts Options) (MatchResult, []byte) {
+	if opts.NormalizeYears {
+		data = normalizeYears(data)
+	}
+	r := matchTemplates(data, templates, opts.TitleWeight, opts.Algorithm)
+	if opts.TrimTrailing && r.Template != nil {
+		if trimmed, ok := trimTrailing(data, r.Template); ok {
+			t := matchTemplates(trimmed, templates, opts.TitleWeight, opts.Algorithm)
+			if t.Score > r.Score {
+				r = t
+				r.Trimmed = true
+				data = trimmed
+			}
+		}
+	}
+	return matchPreamble(r, data, templates, opts.TitleWeight, opts.Algorithm), data
+}
+
+// matcher matches license files against templates. Results are cached by
+// content hash, so identical license texts are matched once.
+type matcher struct {
+	templates []*Template
+	explain   *Template
+	opts      Options
+	hashes    map[string]LicenseFile
+	// declared caches go.mod license declarations by module directory.
+	declared map[string]string
+	// overrides force the license of matching packages, see findOverride.
+	overrides []Override
+}
+
+func newMatcher(templates []*Template, explain *Template, opts Options) *matcher {
+	return &matcher{
+		templates: templates,
+		explain:   explain,
+		opts:      opts,
+		hashes:    map[string]LicenseFile{},
+		declared:  map[string]string{},
+	}
+}
+
+// matchFile reads and matches the license file at fpath against templates.
+func (m *matcher) matchFile(fpath string) (LicenseFile, error) {
+	data, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		return LicenseFile{}, err
+	}
+	hash := hashLicense(data)
+	if f, ok := m.hashes[hash]; ok {
+		return f, nil
+	}
+	data = decodeLicenseData(data)
+	f := LicenseFile{
+		Hash: hash,
+		Err:  checkLicenseData(data),
+	}
+	if f.Err != "" {
+		m.hashes[hash] = f
+		return f, nil
+	}
+	r, data := matchLicense(m.templates, data, m.opts)
+	if m.opts.Diff && r.Template != nil {
+		r.Diff = unifiedWordDiff(r.Template, makeWordSet(data))
+	}
+	f.MatchResult = r
+	if m.explain != nil {
+		e := matchTemplate(data, m.explain, m.opts.TitleWeight, m.opts.Algorithm)
+		f.Explained = &e
+	}
+	m.hashes[hash] = f
+	return f, nil
+}
+
+// matchPath matches the license file at path, relative to root, or every file
+// in it if path is a directory.
+func (m *matcher) matchPath(root, path string) ([]LicenseFile, error) {
+	fpath := filepath.Join(root, path)
+	fi, err := os.Stat(fpath)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.IsDir() {
+		f, err := m.matchFile(fpath)
+		f.Path = path
+		return []LicenseFile{f}, err
+	}
+	fis, err := ioutil.ReadDir(fpath)
+	if err != nil {
+		return nil, err
+	}
+	files := []LicenseFile{}
+	for _, fi := range fis {
+		if !fi.Mode().IsRegular() {
+			continue
+		}
+		f, err := m.matchFile(filepath.Join(fpath, fi.Name()))
+		if err != nil {
+			return nil, err
+		}
+		f.Path = filepath.Join(path, fi.Name())
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+// matchPaths matches the license files or directories at paths, relative to
+// root, see matchPath.
+func (m *matcher) matchPaths(root string, paths []string) ([]LicenseFile, error) {
+	files := []LicenseFile{}
+	for _, path := range paths {
+		matched, err := m.matchPath(root, path)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matched...)
+	}
+	return files, nil
+}
+
+// prefixPaths prefixes l license, notice, included and license files paths
+// with prefix. Files are copied, they may be shared with other packages.
+func (l *License) prefixPaths(prefix string) {
+	for _, p := range []*string{&l.Path, &l.Notice, &l.Included} {
+		if *p != "" {
+			*p = filepath.Join(prefix, *p)
+		}
+	}
+	if len(l.Files) > 0 {
+		files := make([]LicenseFile, len(l.Files))
+		for i, f := range l.Files {
+			f.Path = filepath.Join(prefix, f.Path)
+			files[i] = f
+		}
+		l.Files = files
+	}
+}
+
+// setFiles fills license match fields from supplied matched files.
+func (l *License) setFiles(files []LicenseFile) {
+	if len(files) == 0 {
+		return
+	}
+	f := files[0]
+	for _, other := range files[1:] {
+		if other.Score < f.Score {
+			f = other
+		}
+	}
+	l.Score = f.Score
+	l.TextScore = f.Score
+	l.Template = f.Template
+	l.ExtraWords = f.ExtraWords
+	l.MissingWords = f.MissingWords
+	l.Diff = f.Diff
+	l.Trimmed = f.Trimmed
+	l.Preamble = f.Preamble
+	l.Common = f.Common
+	l.LicenseWords = f.LicenseWords
+	l.TemplateWords = f.TemplateWords
+	l.Second = f.Second
+	l.SecondScore = f.SecondScore
+	l.Explained = f.Explained
+	l.Err = f.Err
+	if len(files) > 1 {
+		l.Files = files
+	}
+	l.Hash = hashFiles(files)
+}
+
+// hashFiles returns the combined hash of files, see combineHashes.
+func hashFiles(files []LicenseFile) string {
+	hashes := []string{}
+	for _, f := range files {
+		hashes = append(hashes, f.Hash)
+	}
+	return combineHashes(hashes)
+}
+
+// combineHashes returns the single hash of a license made of files with
+// supplied hashes, which is the file hash if there is only one.
+func combineHashes(hashes []string) string {
+	if len(hashes) == 1 {
+		return hashes[0]
+	}
+	h := sha256.New()
+	for _, s := range hashes {
+		h.Write([]byte(s))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Options controls how licenses are detected and matched.
+type Options struct {
+	// Explain is the title, nickname or SPDX identifier of a template matched
+	// against every license file, in addition to the best template.
+	Explain string
+	// TrimTrailing ignores text following the end of the best matching
+	// template, when doing so improves the match.
+	TrimTrailing bool
+	// NormalizeYears replaces standalone years in licenses and templates
+	// with a placeholder word, see normalizeYears, so they never count as
+	// extra or missing words.
+	NormalizeYears bool
+	// ImportChains records the shortest import chain from a listed package
+	// to every dependency in License.ImportChain.
+	ImportChains bool
+	// NoSelf excludes the packages of the project being analyzed, see
+	// isMainPackage, to only report third-party licenses.
+	NoSelf bool
+	// MaxDepth, when positive, excludes dependencies more than MaxDepth
+	// imports away from the listed packages.
+	MaxDepth int
+	// Versions resolves packages versions, if not nil. Packages whose
+	// version cannot be resolved have none.
+	Versions VersionResolver
+	// NameWeight, between 0 and 1, is the weight of the license file name
+	// score, see scoreLicenseName, in reported scores. Text match scores are
+	// used as is when zero.
+	NameWeight float64
+	// TitleWeight, if positive, is the weight of words first appearing in
+	// templates titles, instead of 1, in text match scores. Lower values
+	// better separate licenses of the same family, like GPL versions.
+	TitleWeight float64
+	// SPDXTemplates is a directory of SPDX license templates, matched along
+	// the embedded ones, see loadSPDXTemplates.
+	SPDXTemplates string
+	// Algorithm names the text match score function, see scoreFuncs. Dice
+	// is used when empty.
+	Algorithm string
+	// Diff computes unified word diffs of licenses and their templates.
+	Diff bool
+	// MinNameScore is the name score, see scoreLicenseName, below which
+	// files are not considered license files, so packages with only
+	// ambiguously named files report no license instead of a wrong one.
+	MinNameScore float64
+	// Root, if not empty, is an absolute directory where license lookups
+	// of packages beneath it stop, so its license file is always
+	// considered.
+	Root string
+	// IncludeStd reports packages of the "std" standard library subset.
+	IncludeStd bool
+	// IncludeCmd reports packages of the "cmd" standard library subset.
+	IncludeCmd bool
+	// State, if not nil, persists match results and reuses them for
+	// unchanged packages.
+	State *State
+	// Overrides force the license of packages, without matching their
+	// license files. Values look like "PACKAGE=LICENSE", see parseOverride.
+	Overrides []string
+}
+
+func listLicenses(env *GoEnv, pkgs []string, opts Options) ([]License, error) {
+	err := env.checkGo()
+	if err != nil {
+		return nil, err
+	}
+	m, err := prepareMatcher(opts)
 	if err != nil {
 		return nil, err
 	}
-	deps, err := listPackagesAndDeps(gopath, pkgs)
+	roots, deps, err := listPackagesAndDeps(env, pkgs)
 	if err != nil {
 		if _, ok := err.(*MissingError); ok {
 			return nil, err
@@ -402,23 +1970,39 @@ func listLicenses(gopath string, pkgs []string) ([]License, error) {
 		return nil, fmt.Errorf("could not list %s dependencies: %s",
 			strings.Join(pkgs, " "), err)
 	}
-	std, err := listStandardPackages(gopath)
+	var importers map[string]string
+	if opts.MaxDepth > 0 || opts.ImportChains {
+		imports, err := listImports(env, deps)
+		if err != nil {
+			return nil, fmt.Errorf("could not list %s dependencies imports: %s",
+				strings.Join(pkgs, " "), err)
+		}
+		if opts.MaxDepth > 0 {
+			deps = limitDepth(roots, deps, imports, opts.MaxDepth)
+		}
+		importers = walkImports(roots, imports, -1)
+	}
+	std, err := listStandardPackages(env)
 	if err != nil {
 		return nil, fmt.Errorf("could not list standard packages: %s", err)
 	}
-	stdSet := map[string]bool{}
-	for _, n := range std {
-		stdSet[n] = true
+	included := map[string]bool{
+		"std": opts.IncludeStd,
+		"cmd": opts.IncludeCmd,
 	}
-	infos, err := getPackagesInfo(gopath, deps)
+	infos, err := getPackagesInfo(env, deps)
 	if err != nil {
 		return nil, err
 	}
 
 	// Cache matched licenses by path. Useful for package with a lot of
 	// subpackages like bleve.
-	matched := map[string]MatchResult{}
+	matched := map[string][]LicenseFile{}
 
+	isRoot := map[string]bool{}
+	for _, root := range roots {
+		isRoot[root] = true
+	}
 	licenses := []License{}
 	for _, info := range infos {
 		if info.Error != nil {
@@ -428,38 +2012,284 @@ func listLicenses(gopath string, pkgs []string) ([]License, error) {
 			})
 			continue
 		}
-		if stdSet[info.ImportPath] {
+		if source, ok := std[info.ImportPath]; ok && !included[source] {
+			continue
+		}
+		self := isMainPackage(info, isRoot)
+		if self && opts.NoSelf {
 			continue
 		}
-		path, err := findLicense(info)
+		license, err := m.license(info, matched)
 		if err != nil {
 			return nil, err
 		}
-		license := License{
-			Package: info.ImportPath,
-			Path:    path,
-		}
-		if path != "" {
-			fpath := filepath.Join(info.Root, "src", path)
-			m, ok := matched[fpath]
-			if !ok {
-				data, err := ioutil.ReadFile(fpath)
-				if err != nil {
-					return nil, err
-				}
-				m = matchTemplates(data, templates)
-				matched[fpath] = m
-			}
-			license.Score = m.Score
-			license.Template = m.Template
-			license.ExtraWords = m.ExtraWords
-			license.MissingWords = m.MissingWords
+		license.MainModule = self
+		if opts.ImportChains && !isRoot[info.ImportPath] {
+			license.ImportChain = importChain(importers, info.ImportPath)
 		}
 		licenses = append(licenses, license)
 	}
+	if opts.State != nil {
+		err = opts.State.Save()
+		if err != nil {
+			return nil, err
+		}
+	}
 	return licenses, nil
 }
 
+// prepareMatcher returns a matcher of the embedded templates, with the
+// explained template and overrides of opts resolved.
+func prepareMatcher(opts Options) (*matcher, error) {
+	templates, err := loadTemplates()
+	if err != nil {
+		return nil, err
+	}
+	if opts.SPDXTemplates != "" {
+		templates, err = loadSPDXTemplates(opts.SPDXTemplates, templates)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if opts.NormalizeYears {
+		normalizeTemplateYears(templates)
+	}
+	var explain *Template
+	if opts.Explain != "" {
+		explain, err = findTemplate(templates, opts.Explain)
+		if err != nil {
+			return nil, err
+		}
+	}
+	overrides, err := resolveOverrides(templates, opts.Overrides)
+	if err != nil {
+		return nil, err
+	}
+	m := newMatcher(templates, explain, opts)
+	m.overrides = overrides
+	return m, nil
+}
+
+// license returns the license of package info, see scanPackage, cross-checked
+// with its go.mod declaration and categorized. Scan failures are reported in
+// License.Err, the returned error is about recording match results in the
+// state.
+func (m *matcher) license(info *PkgInfo, matched map[string][]LicenseFile) (License, error) {
+	opts := m.opts
+	license, files, err := m.scanPackage(info, matched)
+	if err != nil {
+		// Report the failure and keep scanning other packages.
+		license.Err = err.Error()
+	} else if opts.State != nil && license.Path != "" && !license.Header &&
+		license.Source == "" {
+		err = opts.State.Record(info.ImportPath, license.Version,
+			hashFiles(files), opts, files)
+		if err != nil {
+			return license, err
+		}
+	}
+	if license.Err == "" {
+		err = m.applyDeclared(&license, info)
+		if err != nil {
+			license.Err = err.Error()
+		}
+	}
+	return license, nil
+}
+
+// scanPackage locates and matches the license of package info, reusing and
+// filling matched, which caches license files by path. It returns the license
+// files along the license, or the license as far as it was detected and an
+// error. License paths are displayed under licensePrefix, while returned files
+// keep paths relative to licenseBase.
+func (m *matcher) scanPackage(info *PkgInfo,
+	matched map[string][]LicenseFile) (License, []LicenseFile, error) {
+
+	license, files, err := m.scanLicenseFiles(info, matched)
+	if prefix := licensePrefix(info); prefix != "" {
+		license.prefixPaths(filepath.FromSlash(prefix))
+	}
+	return license, files, err
+}
+
+// scanLicenseFiles implements scanPackage, with license paths relative to
+// licenseBase.
+func (m *matcher) scanLicenseFiles(info *PkgInfo,
+	matched map[string][]LicenseFile) (License, []LicenseFile, error) {
+
+	opts := m.opts
+	license := License{
+		Package:  info.ImportPath,
+		Replaced: describeReplace(info.Module),
+	}
+	if opts.Versions != nil {
+		version, err := opts.Versions.Version(info.Dir, info.ImportPath)
+		if err != nil {
+			// Module cache directories are read-only extracts without
+			// version control metadata, but are named after their version.
+			// Vendored copies only have the one reported by go list.
+			_, version = moduleCacheDir(info.Dir)
+			if version == "" && info.Module != nil {
+				version = info.Module.Version
+			}
+		}
+		license.Version = version
+	}
+	if t := findOverride(m.overrides, info.ImportPath); t != nil {
+		license.Template = t
+		license.Score = 1
+		license.TextScore = 1
+		license.Override = true
+		return license, nil, nil
+	}
+	path, err := findLicense(info, opts.Root, opts.MinNameScore)
+	if err != nil {
+		return license, nil, err
+	}
+	license.Path = path
+	if path == "" {
+		if info.Dir != "" {
+			var ok bool
+			ok, err = matchDocNotice(&license, info, m.templates, opts)
+			if err == nil && !ok {
+				err = matchHeader(&license, info, m.templates)
+			}
+		}
+		return license, nil, err
+	}
+	root := licenseBase(info)
+	license.Notice, err = findNotice(root, path)
+	if err != nil {
+		return license, nil, err
+	}
+	license.Embedded, err = isEmbedded(info.Dir, filepath.Join(root, path))
+	if err != nil {
+		return license, nil, err
+	}
+	alternatives, err := findAlternatives(root, path, opts.MinNameScore)
+	if err != nil {
+		return license, nil, err
+	}
+	license.Included, err = findInclude(root, path)
+	if err != nil {
+		return license, nil, err
+	}
+	if license.Included != "" {
+		path = license.Included
+	}
+	paths := append([]string{path}, alternatives...)
+	fpath := filepath.Join(root, path)
+	license.file = fpath
+	files, ok := matched[fpath]
+	if !ok && opts.State != nil {
+		hash, err := hashPaths(root, paths)
+		if err != nil {
+			return license, nil, err
+		}
+		files, ok = opts.State.Lookup(info.ImportPath, license.Version, hash,
+			opts, m.templates)
+	}
+	if !ok {
+		files, err = m.matchPaths(root, paths)
+		if err != nil {
+			return license, nil, err
+		}
+	}
+	matched[fpath] = files
+	if len(paths) > 1 {
+		kept := pickAlternatives(files)
+		if len(kept) > 1 {
+			license.Alternatives, err = isDualLicensed(root, kept)
+			if err != nil {
+				return license, nil, err
+			}
+		}
+		license.setFiles(kept)
+	} else {
+		license.setFiles(files)
+	}
+	if opts.NameWeight > 0 && license.Template != nil {
+		name := scoreLicenseName(filepath.Base(path))
+		license.Score = (1-opts.NameWeight)*license.TextScore +
+			opts.NameWeight*name
+	}
+	return license, files, nil
+}
+
+// locateLicense returns the absolute path of the license file or directory
+// of package pkg.
+func locateLicense(env *GoEnv, pkg string) (string, error) {
+	pkgs, err := expandPackages(env, []string{pkg})
+	if err != nil {
+		return "", err
+	}
+	if len(pkgs) != 1 {
+		return "", fmt.Errorf("%s matches %d packages, expected one", pkg, len(pkgs))
+	}
+	infos, err := getPackagesInfo(env, pkgs)
+	if err != nil {
+		return "", err
+	}
+	info := infos[0]
+	if info.Error != nil {
+		return "", fmt.Errorf("could not load %s: %s", pkg, info.Error.Err)
+	}
+	path, err := findLicense(info, "", 0)
+	if err != nil {
+		return "", err
+	}
+	if path == "" {
+		return "", fmt.Errorf("no license found for %s", pkg)
+	}
+	return filepath.Join(licenseBase(info), path), nil
+}
+
+// readLicenseText returns the content of the license file at fpath, or the
+// concatenated content of every license file if fpath is a directory.
+func readLicenseText(fpath string) ([]byte, error) {
+	fi, err := os.Stat(fpath)
+	if err != nil {
+		return nil, err
+	}
+	paths := []string{fpath}
+	if fi.IsDir() {
+		fis, err := ioutil.ReadDir(fpath)
+		if err != nil {
+			return nil, err
+		}
+		paths = paths[:0]
+		for _, fi := range fis {
+			if fi.Mode().IsRegular() {
+				paths = append(paths, filepath.Join(fpath, fi.Name()))
+			}
+		}
+	}
+	text := []byte{}
+	for _, p := range paths {
+		data, err := ioutil.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+		text = append(text, data...)
+	}
+	return text, nil
+}
+
+// showLicenseText writes the license file content of package pkg to w, or the
+// content of every license file if the license is a directory.
+func showLicenseText(env *GoEnv, w io.Writer, pkg string) error {
+	fpath, err := locateLicense(env, pkg)
+	if err != nil {
+		return err
+	}
+	text, err := readLicenseText(fpath)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(text)
+	return err
+}
+
 // longestCommonPrefix returns the longest common prefix over import path
 // components of supplied licenses.
 func longestCommonPrefix(licenses []License) string {
@@ -500,10 +2330,25 @@ func longestCommonPrefix(licenses []License) string {
 	return strings.Join(prefix, "/")
 }
 
+// merge folds the projects and policy and baseline outcomes of other, a
+// license collapsed into l, into l.
+func (l *License) merge(other License) {
+	for _, p := range other.Projects {
+		l.Projects = addProject(l.Projects, p)
+	}
+	if l.Violation == "" {
+		l.Violation = other.Violation
+	}
+	l.Trusted = l.Trusted && other.Trusted
+	l.Changed = l.Changed || other.Changed
+}
+
 // groupLicenses returns the input licenses after grouping them by license path
 // and find their longest import path common prefix. Entries with empty paths
-// are left unchanged.
-func groupLicenses(licenses []License) ([]License, error) {
+// are left unchanged, as are entries whose common prefix has less than
+// threshold path components, if threshold is positive. With members, grouped
+// entries keep the licenses they replace in Members.
+func groupLicenses(licenses []License, threshold int, members bool) ([]License, error) {
 	paths := map[string][]License{}
 	for _, l := range licenses {
 		if l.Path == "" {
@@ -511,36 +2356,70 @@ func groupLicenses(licenses []License) ([]License, error) {
 		}
 		paths[l.Path] = append(paths[l.Path], l)
 	}
+	grouped := map[string]License{}
 	for k, v := range paths {
 		if len(v) <= 1 {
 			continue
 		}
 		prefix := longestCommonPrefix(v)
+		if threshold > 0 && countComponents(prefix) < threshold {
+			continue
+		}
+		packages := []string{}
+		for _, l := range v {
+			packages = append(packages, l.Package)
+		}
 		if prefix == "" {
 			return nil, fmt.Errorf(
-				"packages share the same license but not common prefix: %v", v)
+				"packages share the same license %s but not common prefix: %s",
+				k, strings.Join(packages, ", "))
 		}
 		l := v[0]
 		l.Package = prefix
-		paths[k] = []License{l}
+		l.Grouped = packages
+		if members {
+			l.Members = append([]License{}, v...)
+		}
+		for _, other := range v[1:] {
+			l.merge(other)
+		}
+		grouped[k] = l
 	}
 	kept := []License{}
+	done := map[string]bool{}
 	for _, l := range licenses {
-		if l.Path == "" {
+		g, ok := grouped[l.Path]
+		if !ok {
 			kept = append(kept, l)
-			continue
-		}
-		if v, ok := paths[l.Path]; ok {
-			kept = append(kept, v[0])
-			delete(paths, l.Path)
+		} else if !done[l.Path] {
+			kept = append(kept, g)
+			done[l.Path] = true
 		}
 	}
 	return kept, nil
 }
 
+// countComponents returns the number of components of import path prefix.
+func countComponents(prefix string) int {
+	if prefix == "" {
+		return 0
+	}
+	return strings.Count(prefix, "/") + 1
+}
+
 type Row struct {
-	Package, License, Match, Words string
-	Score                          float64
+	Package, Version, Projects, Notice, Category, License, Match, Words string
+	Obligations                                                         string
+	Status                                                              Status
+	Score                                                               float64
+	// URL is the canonical license address, linking title, the License
+	// prefix holding the license title, in reports.
+	URL   string
+	title string
+	// violation is true if the license violates the policy.
+	violation bool
+	// index is the row position before sorting.
+	index int
 }
 
 type Rows []Row
@@ -569,40 +2448,251 @@ func (r Rows) Swap(i, j int) {
 	r[i], r[j] = r[j], r[i]
 }
 
-func generateReport(report string, licenses []License, confidence float64, words bool) error {
+// SortedRows orders Rows according to By, either "license" for license, score
+// and package ordering, "package", or "score" for ascending score and package
+// ordering.
+type SortedRows struct {
+	Rows
+	By string
+}
+
+func (r SortedRows) Less(i, j int) bool {
+	ii, jj := r.Rows[i], r.Rows[j]
+	switch r.By {
+	case "package":
+		return ii.Package < jj.Package
+	case "score":
+		return ii.Score < jj.Score || (ii.Score == jj.Score && ii.Package < jj.Package)
+	}
+	return r.Rows.Less(i, j)
+}
+
+// sortRows sorts rows by "license", "package" or "score". With "none", rows
+// are left in input order, which is the order of the packages listed by go
+// list.
+func sortRows(rows Rows, by string) error {
+	switch by {
+	case "none":
+		return nil
+	case "license", "package", "score":
+		sort.Stable(SortedRows{Rows: rows, By: by})
+		return nil
+	}
+	return fmt.Errorf("unknown sort order: %s", by)
+}
+
+// topRows returns the first n rows, or all of them if n is not positive.
+func topRows(rows Rows, n int) Rows {
+	if n > 0 && len(rows) > n {
+		return rows[:n]
+	}
+	return rows
+}
+
+// sortLicenses orders licenses like their rows would be by sortRows, and
+// returns the first top ones if top is positive.
+func sortLicenses(licenses []License, by string, top int) ([]License, error) {
+	rows := make(Rows, len(licenses))
+	for i, l := range licenses {
+		rows[i] = Row{
+			Package: l.Package,
+			License: l.Title(),
+			Score:   l.Score,
+			index:   i,
+		}
+	}
+	err := sortRows(rows, by)
+	if err != nil {
+		return nil, err
+	}
+	sorted := []License{}
+	for _, row := range topRows(rows, top) {
+		sorted = append(sorted, licenses[row.index])
+	}
+	return sorted, nil
+}
+
+// limitWords returns the first max words, which are the earliest ones as
+// words are sorted by position, and a "(+N more)" suffix counting the others.
+// All words are returned when max is not positive.
+func limitWords(words []string, max int) ([]string, string) {
+	if max <= 0 || len(words) <= max {
+		return words, ""
+	}
+	return words[:max], fmt.Sprintf(" (+%d more)", len(words)-max)
+}
+
+// joinWords joins words with commas, up to max of them, see limitWords.
+func joinWords(words []string, max int) string {
+	words, more := limitWords(words, max)
+	return strings.Join(words, ", ") + more
+}
+
+// markdownLink returns a markdown link to url labeled with text.
+func markdownLink(text, url string) string {
+	return "[" + text + "](" + url + ")"
+}
+
+// licenseMarkers returns the bracketed markers following the title of l in
+// reports, like " [header]", or an empty string if it has none.
+func licenseMarkers(l License) string {
+	markers := ""
+	if l.Trimmed {
+		markers += " [trimmed]"
+	}
+	if l.Header {
+		markers += " [header]"
+	}
+	if l.Source != "" {
+		markers += " [source: " + l.Source + "]"
+	}
+	if l.Preamble {
+		markers += " [preamble]"
+	}
+	if l.Truncated() {
+		markers += " [truncated?]"
+	}
+	if l.Violation != "" {
+		markers += " [violation: " + l.Violation + "]"
+	}
+	if l.Trusted {
+		markers += " [trusted]"
+	}
+	if l.Replaced != "" {
+		markers += " [replaced: " + l.Replaced + "]"
+	}
+	if l.Changed {
+		markers += " [changed]"
+	}
+	if l.Override {
+		markers += " [manual override]"
+	}
+	if l.DeclaredOnly {
+		markers += " [declared]"
+	}
+	if l.DeclaredMismatch {
+		markers += " [declared mismatch: " + l.Declared + "]"
+	}
+	if l.Included != "" {
+		markers += " [included: " + l.Included + "]"
+	}
+	if l.Embedded {
+		markers += " [embedded]"
+	}
+	if l.MainModule {
+		markers += " [main module]"
+	}
+	return markers
+}
+
+// reportRows returns the report rows of licenses, sorted and limited per ro,
+// with license titles linked to their canonical text.
+func reportRows(licenses []License, ro ReportOptions) (Rows, error) {
 	table := make(Rows, len(licenses))
 	for i, l := range licenses {
-		license, diff := "?", ""
-		if l.Template != nil {
-			if l.Score > .99 {
-				license = fmt.Sprintf("%s", l.Template.Title)
-			} else if l.Score >= confidence {
-				license = fmt.Sprintf("%s", l.Template.Title)
-				for _, word := range l.ExtraWords {
-					diff += " +" + word
-				}
-				for _, word := range l.MissingWords {
-					diff += " -" + word
-				}
-			} else {
-				license = fmt.Sprintf("? (%s)", l.Template.Title)
+		license, diff, url := "?", "", ""
+		switch l.Status {
+		case StatusExact:
+			license, url = l.Title(), l.URL()
+		case StatusApproximate:
+			license, url = l.Title(), l.URL()
+			extra, more := limitWords(l.ExtraWords, ro.MaxWords)
+			for _, word := range extra {
+				diff += " +" + word
+			}
+			diff += more
+			missing, more := limitWords(l.MissingWords, ro.MaxWords)
+			for _, word := range missing {
+				diff += " -" + word
 			}
-		} else if l.Err != "" {
+			diff += more
+		case StatusLowConfidence:
+			license = fmt.Sprintf("? (%s)", l.Title())
+		case StatusError:
 			license = strings.Replace(l.Err, "\n", " ", -1)
 		}
-		table[i].Package = l.Package
-		table[i].License = license
-		table[i].Match = fmt.Sprintf("%2d%%", int(100*l.Score+.5))
-		table[i].Words = diff
-		table[i].Score = l.Score
+		if l.Ambiguous(ro.Ambiguity) {
+			license, url = l.AmbiguousTitle(), ""
+		}
+		license += licenseMarkers(l)
+		table[i].Package = l.packages()
+		table[i].Version = l.Version
+		table[i].Projects = strings.Join(l.Projects, ", ")
+		table[i].Notice = l.Notice
+		table[i].Category = l.Category
+		table[i].License = license
+		if url != "" {
+			table[i].URL = url
+			table[i].title = l.Title()
+		}
+		table[i].Match = fmt.Sprintf("%2d%%", int(100*l.Score+.5))
+		table[i].Words = diff
+		table[i].Obligations = strings.Join(l.Obligations, "; ")
+		table[i].Status = l.Status
+		table[i].Score = l.Score
+		table[i].violation = l.Violation != ""
+	}
+	sortBy := ro.SortBy
+	if sortBy == "" {
+		sortBy = "license"
+	}
+	err := sortRows(table, sortBy)
+	if err != nil {
+		return nil, err
+	}
+	table = topRows(table, ro.Top)
+	for i, row := range table {
+		if row.URL != "" {
+			table[i].License = markdownLink(row.title, row.URL) +
+				strings.TrimPrefix(row.License, row.title)
+		}
+	}
+	return table, nil
+}
+
+func generateReport(report string, licenses []License, ro ReportOptions) error {
+	if ro.ByLicense {
+		return generateSectionsReport(report, licenses, ro)
+	}
+	table, err := reportRows(licenses, ro)
+	if err != nil {
+		return err
+	}
+	if ro.Markdown {
+		out, err := os.Create(report)
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+		err = writeMarkdownTable(out, table, ro)
+		if err != nil {
+			return err
+		}
+		return out.Close()
 	}
-	sort.Sort(table)
 
-	maxPackage, maxLicense, maxMatch, maxWords := 0, 0, 0, 0
+	versions, projects, notices := false, false, false
+	maxPackage, maxVersion, maxProjects, maxNotice, maxCategory := 0, 0, 0, 0, 0
+	maxLicense, maxMatch, maxWords, maxObligations := 0, 0, 0, 0
 	for _, row := range table {
 		if width := len(row.Package); width > maxPackage {
 			maxPackage = width
 		}
+		if width := len(row.Version); width > maxVersion {
+			maxVersion = width
+			versions = true
+		}
+		if width := len(row.Projects); width > maxProjects {
+			maxProjects = width
+			projects = true
+		}
+		if width := len(row.Notice); width > maxNotice {
+			maxNotice = width
+			notices = true
+		}
+		if width := len(row.Category); width > maxCategory {
+			maxCategory = width
+		}
 		if width := len(row.License); width > maxLicense {
 			maxLicense = width
 		}
@@ -612,6 +2702,9 @@ func generateReport(report string, licenses []License, confidence float64, words
 		if width := len(row.Words); width > maxWords {
 			maxWords = width
 		}
+		if width := len(row.Obligations); width > maxObligations {
+			maxObligations = width
+		}
 	}
 
 	out, err := os.Create(report)
@@ -636,12 +2729,32 @@ func generateReport(report string, licenses []License, confidence float64, words
 	}
 	out.WriteString("|")
 	rowWidthPackage := writeHeading("Package", maxPackage)
+	var rowWidthVersion int
+	if versions {
+		rowWidthVersion = writeHeading("Version", maxVersion)
+	}
+	var rowWidthProjects int
+	if projects {
+		rowWidthProjects = writeHeading("Projects", maxProjects)
+	}
+	var rowWidthNotice int
+	if notices {
+		rowWidthNotice = writeHeading("Notice", maxNotice)
+	}
+	var rowWidthCategory int
+	if ro.Categories {
+		rowWidthCategory = writeHeading("Category", maxCategory)
+	}
 	rowWidthLicense := writeHeading("License", maxLicense)
 	rowWidthMatch := writeHeading("Match", maxMatch)
 	var rowWidthWords int
-	if words {
+	if ro.Words {
 		rowWidthWords = writeHeading("Words", maxWords)
 	}
+	var rowWidthObligations int
+	if ro.Obligations {
+		rowWidthObligations = writeHeading("Obligations", maxObligations)
+	}
 	out.WriteString("\n")
 
 	writeSep := func(width int) {
@@ -653,11 +2766,26 @@ func generateReport(report string, licenses []License, confidence float64, words
 	}
 	out.WriteString("|")
 	writeSep(rowWidthPackage)
+	if versions {
+		writeSep(rowWidthVersion)
+	}
+	if projects {
+		writeSep(rowWidthProjects)
+	}
+	if notices {
+		writeSep(rowWidthNotice)
+	}
+	if ro.Categories {
+		writeSep(rowWidthCategory)
+	}
 	writeSep(rowWidthLicense)
 	writeSep(rowWidthMatch)
-	if words {
+	if ro.Words {
 		writeSep(rowWidthWords)
 	}
+	if ro.Obligations {
+		writeSep(rowWidthObligations)
+	}
 	out.WriteString("\n")
 
 	writeRow := func(data string, width int) {
@@ -672,17 +2800,239 @@ func generateReport(report string, licenses []License, confidence float64, words
 	for _, row := range table {
 		out.WriteString("|")
 		writeRow(row.Package, rowWidthPackage)
+		if versions {
+			writeRow(row.Version, rowWidthVersion)
+		}
+		if projects {
+			writeRow(row.Projects, rowWidthProjects)
+		}
+		if notices {
+			writeRow(row.Notice, rowWidthNotice)
+		}
+		if ro.Categories {
+			writeRow(row.Category, rowWidthCategory)
+		}
 		writeRow(row.License, rowWidthLicense)
 		writeRow(row.Match, rowWidthMatch)
-		if words {
+		if ro.Words {
 			writeRow(row.Words, rowWidthWords)
 		}
+		if ro.Obligations {
+			writeRow(row.Obligations, rowWidthObligations)
+		}
 		out.WriteString("\n")
 	}
 
 	return nil
 }
 
+// generateSectionsReport writes licenses in report file with a section per
+// license, see printLicenseSections.
+func generateSectionsReport(report string, licenses []License, ro ReportOptions) error {
+	out, err := os.Create(report)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	err = printLicenseSections(out, licenses, ro)
+	if err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+// printLicenseSections writes licenses to w with a section per license, see
+// writeLicenseSections. With ro.Top, only the first packages are listed, in
+// ro.SortBy order.
+func printLicenseSections(w io.Writer, licenses []License, ro ReportOptions) error {
+	if ro.Top > 0 {
+		sortBy := ro.SortBy
+		if sortBy == "" {
+			sortBy = "license"
+		}
+		var err error
+		licenses, err = sortLicenses(licenses, sortBy, ro.Top)
+		if err != nil {
+			return err
+		}
+	}
+	return writeLicenseSections(w, licenses)
+}
+
+// ReportOptions controls how licenses are rendered.
+type ReportOptions struct {
+	// Words displays the words differing between licenses and templates.
+	Words bool
+	// MaxWords, if positive, limits the number of extra and missing words
+	// displayed per license.
+	MaxWords int
+	// SortBy is the rows order, see sortRows. Each output has its own
+	// default when empty.
+	SortBy string
+	// Ambiguity is the score delta below which second best templates are
+	// displayed, if positive.
+	Ambiguity float64
+	// Categories displays licenses obligation categories.
+	Categories bool
+	// Obligations displays licenses obligation notes.
+	Obligations bool
+	// ByLicense lays reports out as a section per license instead of a
+	// table.
+	ByLicense bool
+	// Top limits the output to the first Top rows, after sorting, if
+	// positive.
+	Top int
+	// Color colorizes the table license column by status, see licenseColor.
+	Color bool
+	// ExplainGrouping lists the packages grouped in table rows, see
+	// groupLicenses.
+	ExplainGrouping bool
+	// Markdown writes reports as GitHub flavored markdown tables, see
+	// writeMarkdownTable, instead of aligned pipe tables.
+	Markdown bool
+}
+
+// printTable writes licenses as a text table to w.
+func printTable(w io.Writer, licenses []License, ro ReportOptions) error {
+	table := make(Rows, 0, len(licenses))
+	for _, l := range licenses {
+		// Markers go on the first line, details on the following ones.
+		license, details := "?", ""
+		switch l.Status {
+		case StatusExact:
+			license = l.Title()
+		case StatusApproximate:
+			license = fmt.Sprintf("%s (%2d%%)", l.Title(), int(100*l.Score))
+			if len(l.Diff) > 0 {
+				details += "\n\t" + strings.Join(l.Diff, "\n\t")
+				break
+			}
+			if ro.Words && len(l.ExtraWords) > 0 {
+				details += "\n\t+words: " + joinWords(l.ExtraWords, ro.MaxWords)
+			}
+			if ro.Words && len(l.MissingWords) > 0 {
+				details += "\n\t-words: " + joinWords(l.MissingWords, ro.MaxWords)
+			}
+		case StatusLowConfidence:
+			license = fmt.Sprintf("? (%s, %2d%%)", l.Title(), int(100*l.Score))
+		case StatusError:
+			license = strings.Replace(l.Err, "\n", " ", -1)
+		}
+		if l.Ambiguous(ro.Ambiguity) {
+			license = fmt.Sprintf("%s (%2d%%, %2d%%)", l.AmbiguousTitle(),
+				int(100*l.Score), int(100*l.SecondScore))
+		}
+		license += licenseMarkers(l)
+		if ro.Color {
+			license = colorize(license, licenseColor(&l))
+		}
+		license += details
+		if ro.Obligations {
+			for _, note := range l.Obligations {
+				license += "\n\tobligation: " + note
+			}
+		}
+		if len(l.ImportChain) > 0 {
+			license += "\n\timported by: " + strings.Join(l.ImportChain, " -> ")
+		}
+		if ro.ExplainGrouping && len(l.Grouped) > 0 {
+			license += "\n\tgrouped by " + l.Path + ": " + strings.Join(l.Grouped, ", ")
+		}
+		if e := l.Explained; e != nil {
+			license += fmt.Sprintf("\n\t%s: %2d%%", e.Template.Title, int(100*e.Score))
+			if len(e.ExtraWords) > 0 {
+				license += "\n\t+words: " + joinWords(e.ExtraWords, ro.MaxWords)
+			}
+			if len(e.MissingWords) > 0 {
+				license += "\n\t-words: " + joinWords(e.MissingWords, ro.MaxWords)
+			}
+		}
+		table = append(table, Row{
+			Package:  l.packages(),
+			Version:  l.Version,
+			Projects: strings.Join(l.Projects, ", "),
+			Notice:   l.Notice,
+			Category: l.Category,
+			License:  license,
+			Status:   l.Status,
+			Score:    l.Score,
+		})
+	}
+	if ro.SortBy != "" {
+		err := sortRows(table, ro.SortBy)
+		if err != nil {
+			return err
+		}
+	}
+	table = topRows(table, ro.Top)
+
+	versions, projects, notices := false, false, false
+	for _, row := range table {
+		versions = versions || row.Version != ""
+		projects = projects || row.Projects != ""
+		notices = notices || row.Notice != ""
+	}
+	tw := tabwriter.NewWriter(w, 1, 4, 2, ' ', 0)
+	for _, row := range table {
+		line := row.Package + "\t"
+		indent := "\n\t"
+		if versions {
+			line += row.Version + "\t"
+			indent += "\t"
+		}
+		if projects {
+			line += row.Projects + "\t"
+			indent += "\t"
+		}
+		if notices {
+			line += row.Notice + "\t"
+			indent += "\t"
+		}
+		if ro.Categories {
+			line += row.Category + "\t"
+			indent += "\t"
+		}
+		line += strings.Replace(row.License, "\n\t", indent, -1)
+		_, err := tw.Write([]byte(line + "\n"))
+		if err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+var reWindowsVar = regexp.MustCompile(`%([A-Za-z_][A-Za-z0-9_]*)%`)
+
+// expandPath expands a leading "~" to the user home directory, and $VAR,
+// ${VAR} or %VAR% environment variables in path.
+func expandPath(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") ||
+		strings.HasPrefix(path, "~"+string(filepath.Separator)) {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = home + path[1:]
+		}
+	}
+	path = reWindowsVar.ReplaceAllStringFunc(path, func(s string) string {
+		if v, ok := os.LookupEnv(s[1 : len(s)-1]); ok {
+			return v
+		}
+		return s
+	})
+	return os.ExpandEnv(path)
+}
+
+// stringsFlag is a flag.Value collecting the values of a repeated flag.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
 func printLicenses() error {
 	flag.Usage = func() {
 		fmt.Println(`Usage: licenses IMPORTPATH...
@@ -692,72 +3042,842 @@ standard library packages, and prints their licenses. Licenses are detected by
 looking for files named like LICENSE, COPYING, COPYRIGHT and other variants in
 the package directory, and its parent directories until one is found. Files
 content is matched against a set of well-known licenses and the best match is
-displayed along with its score.
+displayed along with its score. Directories without license file but with a
+LICENSES subdirectory, like REUSE compliant ones, have all the files of the
+subdirectory matched and combined. Other license files next to the best named
+one, but not source files, are reported along when they match other licenses,
+joined with OR when named after their license, like LICENSE-APACHE along
+LICENSE-MIT, or stating dual licensing, and with AND otherwise. Attribution
+notices next to license files, like Apache NOTICE or THIRD_PARTY_NOTICES files,
+are displayed in a Notice column. Packages whose license could not be read are
+reported with their error and the command fails.
+Packages without license file whose Go sources carry an Apache-2.0 or MPL-2.0
+header are reported with that license, a lower score and a [header] marker.
+The MPL-2.0 "This Source Code Form is subject to the terms of the Mozilla
+Public License, v. 2.0" notice, which the license accepts in place of its
+text, scores 95%.
+Before that, license notices atop their doc.go file, a common place for
+single file packages, are matched like license files and reported with a
+[source: doc.go] marker, and a "source" JSON field.
+License files holding only a GNU notice preamble, like "This program is free
+software; you can redistribute it...", are reported with the GNU license and
+version they reference, a 90% score and a [preamble] marker. Along a GNU
+license text, the preamble picks the family member it references when texts
+are too similar to tell apart.
 
 With -a, all individual packages are displayed instead of grouping them by
 license files.
+With -group-threshold N, packages sharing a license file are only grouped when
+their common import path prefix has at least N components, like 3 for
+"github.com/user/repo". Others are displayed individually.
+With -explain-grouping, grouped table rows list the packages they stand for
+and the license file they share, which caused the grouping.
+With -group-members, grouped JSON and protobuf entries also hold the full
+results of the packages they stand for, in "members" arrays, while tables stay
+grouped. It spares a second scan with -a.
+With -dedupe SCORE, like 0.98, licenses matching the same template whose texts
+are similar above SCORE, when compared with each other like with templates, are
+collapsed into a single entry listing all their packages. It removes the noise
+of license files only differing by copyright holders or years.
 With -w, words in package license file not found in the template license are
 displayed. It helps assessing the changes importance.
-With -r, a report is generated and saved in the specified file.`)
+With -diff-format unified, approximate matches display a unified diff like
+view of their template and license words instead of differing words. Both are
+taken as sequences of distinct words, in first appearance order. Hunks start
+with "@@ -template +license @@" word ranges, followed by runs of common words,
+template words missing from the license, prefixed with "-", and extra license
+words, prefixed with "+". It also fills the "diff" field of JSON output.
+With -max-words N, at most N extra and N missing words are displayed per
+license, the earliest ones in the text, followed by a "(+M more)" count. N
+lower or equal to zero, the default, displays all of them.
+With -r, a report is generated and saved in the specified file.
+Report license titles link to their canonical text, when known, like JSON
+"url" fields.
+With -format md, the report, or the standard output without -r, is a GitHub
+flavored markdown table, with a "|---|" separator row and escaped pipes in
+cells, instead of an aligned pipe table. The default, -format table, keeps the
+existing layouts.
+With -format protobuf, licenses are written as a serialized LicenseSet protocol
+buffers message, whose schema is licenses.proto, to the report or the standard
+output. Messages hold packages, versions, SPDX identifiers, scores, license
+paths and hashes and statuses.
+With -format xlsx, or excel, the -r report is an Excel workbook with the report
+table columns, a frozen header row and filters, for spreadsheet reviews.
+License cells are colored like -color output, green for exact matches, yellow
+for approximate ones and red for the others, and matches are percentages.
+With -by-license, the report, or the standard output without -r, has a
+markdown section per license listing its packages and versions, instead of a
+single table. Unknown, low confidence and failed licenses, then missing ones,
+get their own sections at the end. It cannot be combined with -json, -format
+protobuf or xlsx.
+With -explain, every license is also matched against the specified template,
+identified by title, nickname or SPDX identifier, and the result displayed.
+In module mode, licenses of modules replaced by go.mod replace directives are
+looked up in the replacement, and such packages are marked as replaced.
+Licenses declared by comments like "// license: MIT" or
+"// SPDX-License-Identifier: MIT" atop module go.mod files are checked against
+the detected ones, and disagreements marked as declared mismatches. Modules
+without recognized license get their declared license, if it is a single
+known identifier.
+License files embedded in their package by //go:embed directives, and thus
+shipped in binaries, are marked as embedded.
+Short license files referencing another file by relative path, like "The full
+license text is in ./docs/LICENSE.txt", are matched using the referenced file
+when it exists beneath their directory, and marked as included from it.
+With -offline, go commands are prevented from accessing the network, packages
+must be vendored or in the module cache. Unless -mod or GOFLAGS set it, or the
+module is vendored, -mod=mod is added to GOFLAGS.
+With -mod, the value is forwarded to every go list invocation, like
+-mod=vendor or -mod=readonly. GOFLAGS is honored as well.
+With -go PATH, PATH is run instead of the go binary found in PATH, like
+"go1.21" or "/usr/local/go1.21/bin/go", to pin the toolchain listing packages.
+It defaults to the GO environment variable, if set.
+With -trim-trailing, text following the end of the best matching license,
+like a project specific note, is ignored when it improves the match. Such
+licenses are marked as trimmed.
+With -normalize-years, standalone years like "2007", outside copyright lines
+which are always ignored, are replaced with a placeholder in licenses and
+templates, so year edits never count as extra or missing words.
+With -color, the table license column is colorized: green for exact matches,
+yellow for approximate ones and red for the others and policy violations.
+It defaults to "auto", colorizing only when writing to a terminal and NO_COLOR
+is not set. "always" and "never" force it.
+License files with less than a quarter of their matched template words are
+marked as truncated, whatever their score.
+With -override PACKAGE=LICENSE, repeatable, PACKAGE is reported with LICENSE,
+identified by title, nickname or SPDX identifier, without looking up its
+license file, and marked as a manual override. PACKAGE may end with "/..." to
+cover its subpackages. The first matching override applies.
+With -deps-graph, every dependency is reported with the shortest import chain
+from a listed package to it, like "app -> example.com/lib -> example.com/gpl",
+explaining why it is there. JSON output has it in "import_chain" arrays. It
+costs an additional go list invocation, shared with -max-depth.
+With -max-depth N, only dependencies within N imports of the listed packages
+are reported, 1 meaning direct dependencies. Computing the import graph costs
+an additional go list invocation over all dependencies.
+With -versions, packages versions are resolved and displayed. Resolvers are
+"git" for the checked out commit, "describe" for its closest tag as given by
+git describe, falling back to the commit, "vcs" for the revision of whatever
+version control system manages the package, "module" for the module cache
+version and "date" for the last modification date of the package files.
+With -only LICENSE[,LICENSE...], only packages whose detected license is one of
+the listed titles, SPDX identifiers or glob patterns, like "*GPL*", are
+reported. Unlike -deny, it does not change the command outcome.
+With -strict, only exact matches, scoring above 99%, count as identified.
+Other matches are reported as unknown licenses, fail -allow policies and are
+kept by -quiet, as if their license was not recognized.
+With -quiet, only unknown, failed, truncated or low confidence licenses are
+reported. Nothing is printed if there are none.
+With -manifest FILE, the modules listed in FILE, as written by "go mod download
+-json", are reported instead of package arguments, without invoking go. Each
+module is reported once, with the license found in its downloaded directory and
+the version of the manifest. Modules whose download failed are reported with
+their error.
+With -binary PATH, the module dependencies recorded in the build information
+of the Go binary at PATH are reported, with licenses looked up in the module
+cache, $GOMODCACHE or $GOPATH/pkg/mod, and versions from the binary. Modules
+missing from the cache are reported as "source unavailable".
+With -submodules, the git submodules declared in the .gitmodules file of the
+current repository, and nested ones, are reported as well, as pseudo-packages
+named after their path, with licenses found in their directories. Package
+arguments are optional. Submodules not checked out are reported as such.
+With -watch, licenses are scanned and printed again whenever go.mod, go.sum,
+go.work or vendor/modules.txt files of the current or -project directories
+modules change, until interrupted. Bursts of changes trigger a single rescan
+once files are left unchanged for a second. Scan errors are reported without
+stopping.
+With -project, package arguments are listed in every specified project
+directory, each with its own module context, and the results merged. Packages
+are annotated with the projects using them, with a row per version and license
+when projects disagree. The flag can be repeated.
+With -ambiguity DELTA, licenses whose best and second best template scores
+are within DELTA, like 0.02, are displayed with both titles, as in
+"MIT License? / ISC License?".
+With -policy FILE, licenses are checked against a JSON policy listing allowed
+and denied licenses, by title or SPDX identifier, and per-package approved
+licenses:
+
+  {
+    "allow": ["MIT", "Apache-2.0"],
+    "deny": ["GPL-3.0"],
+    "exceptions": {"github.com/foo/bar": "LGPL-2.1"}
+  }
+
+Licenses can be glob patterns matched against titles and SPDX identifiers,
+ignoring case, like "*GPL*" or "GPL-*". A license both allowed and denied is
+denied. Exceptions apply to subpackages too, the longest matching import path
+prefix winning. Violations are reported and the command fails if there are any.
+With -allow and -deny, licenses or license patterns are added to the policy
+allowed and denied lists. Both flags can be repeated and used without -policy.
+With -baseline FILE, license file hashes are compared to the ones recorded in
+FILE, a JSON object mapping packages to hashes. Packages whose license changed
+are marked and the command fails if there are any. With -update-baseline, FILE
+is rewritten with the scanned hashes instead.
+With -lock FILE -verify, the license of every package is checked against FILE,
+a JSON object mapping packages to their version, license SPDX expression and
+license hash, like go.sum does for module contents. Packages missing from FILE
+or whose license or license text changed are written to stderr and the command
+fails if there are any. With -lock FILE -update-lock, FILE is rewritten with the
+scanned licenses instead.
+With -trusted PREFIX, packages whose import path starts with PREFIX, like
+first-party code, pass policy checks whatever their license and are marked as
+trusted, but are still listed. The flag can be repeated, and policy files
+accept a "trusted" list of prefixes as well.
+With -suggest, licenses scoring between 70% and the confidence threshold,
+differing from their template by a few words only, are written to stderr as
+suggested template variants, with the words to add and remove, as candidates
+for the template corpus. Packages with the same differences are merged.
+With -check-compatibility, detected licenses are checked against a built-in
+list of licenses which cannot be combined and distributed together, like
+GPL-2.0 and Apache-2.0. Incompatible pairs are written to stderr with their
+packages and the reason why, and the command fails if there are any.
+Alternative licenses conflict only if every alternative does, and licenses
+without SPDX identifier, below the confidence threshold or failed are ignored.
+GPL versions are taken as "only".
+Packages of the project being analyzed, the main module ones in module mode or
+the package arguments otherwise, are reported with their own license and marked
+as main module. With -no-self, they are left out, to only report third-party
+licenses.
+With -include-std and -include-cmd, packages of the "std" or "cmd" standard
+library subsets are reported instead of being skipped, with the Go LICENSE.
+With -name-weight W, scores blend the template match score with a score of the
+license file name, LICENSE being more trustworthy than license.rst for
+instance, as (1-W)*text + W*name. JSON output keeps the text score apart.
+With -min-name-score S, files whose name scores below S are not considered
+license files, LICENSE scoring 1, LICENSE.md 0.9, COPYING 0.8, LICENSE.rst 0.7
+and names merely containing "license" 0.6. Packages with only ambiguously
+named files then report no license rather than a possibly wrong one.
+With -title-weight W, words first appearing in templates titles, like "GNU
+GENERAL PUBLIC LICENSE", count for W instead of 1 in text scores, so the body
+text dominates and licenses of the same family are better told apart.
+With -algorithm NAME, text scores are computed from common and total words
+with "dice", 2*common/(license+template), the default, "jaccard",
+common/union, or "cosine", the cosine similarity of word frequency vectors.
+The confidence threshold, tuned for dice, is converted to the equivalent
+score of the others. Jaccard scores partial matches lower, while cosine
+weighs repeated words more and tolerates extra text around licenses better,
+but tells templates of different lengths apart less well. -ranked shows the
+effect on a given license file.
+With -spdx-templates DIR, the SPDX license templates of DIR, named like
+MIT.template.txt as in the "template" directory of the SPDX license-list-data
+repository, are matched as well. Words of <<beginOptional>> regions and
+replaceable <<var>> fields, like copyright lines, are optional: neither
+missing nor extra. Licenses already known are reported under the same title
+with the best of both scores, others under their SPDX identifier.
+With -root DIR, license lookups of packages beneath DIR stop at DIR instead of
+$GOPATH/src, so a top-level LICENSE in DIR covers packages without their own,
+as in monorepos.
+With -state FILE, match results are periodically saved in FILE by package,
+license hash and version. Later runs reuse them for unchanged packages, which
+also resumes interrupted scans.
+With -categories, licenses obligation categories are displayed, one of
+public-domain, permissive, weak-copyleft, strong-copyleft or unknown. Packages
+with several licenses get the most restrictive category.
+With -obligations, only licenses imposing active obligations, like source
+disclosure, are reported, that is neither permissive nor public domain, along
+with their category and a summary of their obligations. It is meant for
+compliance reviews.
+With -json, licenses are written as JSON, including their status, one of
+exact, approximate, low-confidence, unknown, missing or error, and the raw word
+counts used to compute match scores. The "licenses" array is preceded by a
+"metadata" object recording the tool and Go versions, the package arguments,
+GOPATH or module mode, the scan time and the confidence threshold.
+Fatal errors are then written to stderr as a JSON object, like {"error": "..."}.
+JSON is written on a single line, or indented with -pretty, which applies to
+-licenses as well. Fields are ordered the same way in both cases.
+With -sort, rows are ordered by "license", "package", ascending "score" or left
+in the listing order with "none". Reports default to "license", the standard
+output to the listing order.
+With -top N, only the first N rows are reported, after sorting, in every output
+format. N lower or equal to zero reports all rows.
+With -show-text PACKAGE, the license file of PACKAGE is printed, and nothing
+else is done.
+With -compare-upstream PACKAGE=URL, the license of PACKAGE, typically a fork, is
+compared with the license of its upstream project at URL, a git repository,
+like a .git address or https://github.com/OWNER/REPO, cloned to find its
+license file, the URL of a license file or a local license file or directory,
+and nothing else is done. Each comparison reports whether
+both texts are alike, per the confidence threshold, and match the same
+template, along with their similarity, their best templates and the differing
+words otherwise. The command fails if any license differs from its upstream.
+The flag can be repeated.
+With -license-history PACKAGE, the git commits which touched the license file of
+PACKAGE are printed with their dates, most recent first, following renames,
+and nothing else is done. It helps assessing relicensing risks.
+With -ranked -file PATH, every template is scored against the license file at
+PATH and printed, best first, with the number of common words, and nothing
+else is done. It helps spotting templates too close to be told apart. -json
+-title-weight, -algorithm and -spdx-templates apply.
+With -list-templates, the loaded license templates are listed along with their
+language, for translations, and word set size, and nothing else is done.
+With -validate-templates, license templates are checked for a title and a
+minimum number of words, like when they are loaded, and for identical word
+sets, which make all of them but the first unreachable, and nothing else is
+done.
+With -update-templates SRC, run from the repository root, the templates of
+the assets directory are updated from the license texts of SRC, a directory of
+.txt files or the base URL of SPDX license texts like
+https://raw.githubusercontent.com/spdx/license-list-data/main/text, and nothing
+else is done. Templates are added or changed, along with their generated
+sources. Plain texts named after their SPDX identifier, like "GPL-2.0.txt",
+keep the front matter of the matching template. With -dry-run, changes are
+reported but not written.
+With -licenses, the title and SPDX identifier of every detectable license are
+written as JSON, for instance to build policy files, and nothing else is done.
+
+Default flags are read from the .licenses.conf file of the current directory,
+one or more per line, with "#" comment lines, then from the LICENSES_FLAGS
+environment variable, like LICENSES_FLAGS="-strict -deny GPL-3.0". Only
+-format, -strict, -allow and -deny can have defaults. Command line flags
+override them, and the values of repeatable ones replace the default ones.`)
 		os.Exit(1)
 	}
 	all := flag.Bool("a", false, "display all individual packages")
+	depsGraph := flag.Bool("deps-graph", false,
+		"report the shortest import chain from a listed package to every dependency")
+	watch := flag.Bool("watch", false, "rescan and print licenses again when module files change")
+	dedupe := flag.Float64("dedupe", 0,
+		"collapse licenses whose texts are similar above this score, from 0 to 1, if positive")
+	explainGrouping := flag.Bool("explain-grouping", false,
+		"list the packages of grouped rows and their shared license file")
+	groupMembers := flag.Bool("group-members", false,
+		"keep the results of grouped packages in JSON and protobuf outputs")
+	groupThreshold := flag.Int("group-threshold", 0,
+		"only group packages whose common prefix has at least N components, if positive")
 	words := flag.Bool("w", false, "display words not matching license template")
+	maxWords := flag.Int("max-words", 0, "maximum number of differing words displayed per license, 0 for all")
 	report := flag.String("r", "", "generate a report file")
+	byLicense := flag.Bool("by-license", false, "lay the report out as a section per license")
+	format := flag.String("format", "table",
+		"output format, table, md for GitHub flavored markdown, protobuf or xlsx")
+	listTemplates := flag.Bool("list-templates", false, "list license templates and exit")
+	validateTemplates := flag.Bool("validate-templates", false, "check license templates and exit")
+	updateSource := flag.String("update-templates", "",
+		"update the assets directory templates from this directory or URL and exit")
+	dryRun := flag.Bool("dry-run", false, "with -update-templates, only report changes")
+	listTitles := flag.Bool("licenses", false, "list detectable licenses as JSON and exit")
+	explain := flag.String("explain", "", "also match licenses against this template")
+	sortBy := flag.String("sort", "", "sort rows by license, package, score or none")
+	mod := flag.String("mod", "", "module download mode passed to go list")
+	offline := flag.Bool("offline", false, "prevent go commands from accessing the network")
+	goBin := flag.String("go", "", "go binary to run, defaults to $GO or go in PATH")
+	trim := flag.Bool("trim-trailing", false, "ignore text following license end")
+	normYears := flag.Bool("normalize-years", false, "ignore year differences between licenses and templates")
+	jsonOut := flag.Bool("json", false, "write licenses as JSON")
+	pretty := flag.Bool("pretty", false, "indent JSON output")
+	color := flag.String("color", "auto", "colorize the table: auto, always or never")
+	diffFormat := flag.String("diff-format", "words", "differing words format: words or unified")
+	maxDepth := flag.Int("max-depth", 0, "ignore dependencies deeper than this, if positive")
+	versions := flag.String("versions", "", "resolve versions with git, describe, vcs, module or date")
+	only := flag.String("only", "", "only report these comma-separated licenses or license patterns")
+	top := flag.Int("top", 0, "only report the first N rows after sorting, if positive")
+	quiet := flag.Bool("quiet", false, "only report problematic licenses")
+	strict := flag.Bool("strict", false, "only count exact matches as identified licenses")
+	ambiguity := flag.Float64("ambiguity", 0, "show second best match when within this score delta")
+	policyPath := flag.String("policy", "", "check licenses against this policy file")
+	showText := flag.String("show-text", "", "print the license file of this package and exit")
+	var upstreams stringsFlag
+	flag.Var(&upstreams, "compare-upstream",
+		"compare the license of a package with its upstream one, as PACKAGE=URL, repeatable")
+	history := flag.String("license-history", "",
+		"print the commits which touched the license file of this package and exit")
+	ranked := flag.Bool("ranked", false, "print every template score against the -file license and exit")
+	rankedFile := flag.String("file", "", "license file ranked by -ranked")
+	noSelf := flag.Bool("no-self", false, "do not report the packages of the main module")
+	includeStd := flag.Bool("include-std", false, "report std standard library packages")
+	includeCmd := flag.Bool("include-cmd", false, "report cmd standard library packages")
+	minNameScore := flag.Float64("min-name-score", 0,
+		"ignore files whose name score is below this, from 0 to 1")
+	nameWeight := flag.Float64("name-weight", 0, "weight of license file names in scores, from 0 to 1")
+	titleWeight := flag.Float64("title-weight", 0,
+		"weight of template title words in scores, from 0 to 1, 0 to disable")
+	spdxTemplates := flag.String("spdx-templates", "",
+		"also match SPDX license templates of this directory")
+	algorithm := flag.String("algorithm", "dice",
+		"text match score formula, one of dice, jaccard or cosine")
+	root := flag.String("root", "", "stop license lookups at this directory")
+	showCategories := flag.Bool("categories", false, "display licenses obligation categories")
+	obligationsOnly := flag.Bool("obligations", false,
+		"only report licenses with active obligations, with obligation notes")
+	suggest := flag.Bool("suggest", false, "suggest template variants for near-miss licenses")
+	checkCompat := flag.Bool("check-compatibility", false,
+		"report licenses which cannot be distributed together")
+	baselinePath := flag.String("baseline", "", "fail if licenses differ from this baseline file")
+	updateBaseline := flag.Bool("update-baseline", false, "rewrite the -baseline file with scanned licenses")
+	lockPath := flag.String("lock", "", "license lock file of -verify and -update-lock")
+	verify := flag.Bool("verify", false, "fail if licenses disagree with the -lock file")
+	updateLock := flag.Bool("update-lock", false, "rewrite the -lock file with scanned licenses")
+	statePath := flag.String("state", "", "persist match results in this file and reuse them")
+	manifest := flag.String("manifest", "", "report the modules of this go mod download -json manifest")
+	binary := flag.String("binary", "", "report the module dependencies built in this Go binary")
+	submodules := flag.Bool("submodules", false, "report git submodules as well")
+	var projects stringsFlag
+	flag.Var(&projects, "project", "scan packages in this project directory, repeatable")
+	var allow, deny stringsFlag
+	flag.Var(&allow, "allow", "allow this license or license pattern, repeatable")
+	flag.Var(&deny, "deny", "deny this license or license pattern, repeatable")
+	var overrides stringsFlag
+	flag.Var(&overrides, "override", "force the license of a package, as PACKAGE=LICENSE, repeatable")
+	var trusted stringsFlag
+	flag.Var(&trusted, "trusted", "pass policy checks for packages with this import path prefix, repeatable")
+	err := loadDefaults(flag.CommandLine)
+	if err != nil {
+		return err
+	}
 	flag.Parse()
-	if flag.NArg() < 1 {
+	for _, path := range []*string{report, policyPath, statePath, root, baselinePath,
+		updateSource, rankedFile, manifest, binary, spdxTemplates, lockPath} {
+		*path = expandPath(*path)
+	}
+	for i, p := range projects {
+		projects[i] = expandPath(p)
+	}
+	if *goBin == "" {
+		*goBin = os.Getenv("GO")
+	}
+	env := &GoEnv{
+		Mod:     *mod,
+		Offline: *offline,
+		Go:      expandPath(*goBin),
+	}
+	if *updateSource != "" {
+		return updateTemplates(os.Stdout, *updateSource, "assets", *dryRun)
+	}
+	if *validateTemplates {
+		templates, err := loadTemplates()
+		if err != nil {
+			return err
+		}
+		err = checkTemplates(templates)
+		if err != nil {
+			return err
+		}
+		err = warnDuplicateTemplates(os.Stderr, templates)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%d valid templates\n", len(templates))
+		return nil
+	}
+	if *listTemplates {
+		templates, err := loadTemplates()
+		if err != nil {
+			return err
+		}
+		return printTemplates(os.Stdout, templates)
+	}
+	if *listTitles {
+		templates, err := loadTemplates()
+		if err != nil {
+			return err
+		}
+		return writeTemplatesJSON(os.Stdout, templates, *pretty)
+	}
+	if _, err := getScoreFunc(*algorithm); err != nil {
+		return err
+	}
+	if *ranked {
+		if *rankedFile == "" {
+			return fmt.Errorf("-ranked requires a -file license file")
+		}
+		templates, err := loadTemplates()
+		if err != nil {
+			return err
+		}
+		if *spdxTemplates != "" {
+			templates, err = loadSPDXTemplates(*spdxTemplates, templates)
+			if err != nil {
+				return err
+			}
+		}
+		ranks, err := rankFile(*rankedFile, templates, *titleWeight, *algorithm)
+		if err != nil {
+			return err
+		}
+		if *jsonOut {
+			return writeRankingJSON(os.Stdout, ranks, *pretty)
+		}
+		return printRanking(os.Stdout, ranks)
+	}
+	if *showText != "" {
+		err := env.checkGo()
+		if err != nil {
+			return err
+		}
+		return showLicenseText(env, os.Stdout, *showText)
+	}
+	if *history != "" {
+		err := env.checkGo()
+		if err != nil {
+			return err
+		}
+		return printLicenseHistory(env, os.Stdout, *history)
+	}
+	if flag.NArg() < 1 && *manifest == "" && *binary == "" && !*submodules &&
+		len(upstreams) == 0 {
 		return fmt.Errorf("expect at least one package argument")
 	}
 	pkgs := flag.Args()
 
-	confidence := 0.9
-	licenses, err := listLicenses("", pkgs)
+	confidence := scaleConfidence(*algorithm, 0.9)
+	exact := scaleConfidence(*algorithm, exactScore)
+	if *strict {
+		confidence = strictConfidence(exact)
+	}
+	resolver, err := getVersionResolver(*versions)
 	if err != nil {
 		return err
 	}
-	if !*all {
-		licenses, err = groupLicenses(licenses)
+	opts := Options{
+		Explain:        *explain,
+		TrimTrailing:   *trim,
+		NormalizeYears: *normYears,
+		MaxDepth:       *maxDepth,
+		Versions:       resolver,
+		NoSelf:         *noSelf,
+		ImportChains:   *depsGraph,
+		IncludeStd:     *includeStd,
+		IncludeCmd:     *includeCmd,
+		NameWeight:     *nameWeight,
+		MinNameScore:   *minNameScore,
+		TitleWeight:    *titleWeight,
+		Algorithm:      *algorithm,
+		SPDXTemplates:  *spdxTemplates,
+		Overrides:      overrides,
+	}
+	if *root != "" {
+		opts.Root, err = filepath.Abs(*root)
 		if err != nil {
 			return err
 		}
 	}
-
-	if *report != "" {
-		return generateReport(*report, licenses, confidence, *words)
+	if *minNameScore < 0 || *minNameScore > 1 {
+		return fmt.Errorf("-min-name-score must be between 0 and 1, got %v", *minNameScore)
+	}
+	if *nameWeight < 0 || *nameWeight > 1 {
+		return fmt.Errorf("-name-weight must be between 0 and 1, got %v", *nameWeight)
+	}
+	if *dedupe < 0 || *dedupe > 1 {
+		return fmt.Errorf("-dedupe must be between 0 and 1, got %v", *dedupe)
+	}
+	if *titleWeight < 0 || *titleWeight > 1 {
+		return fmt.Errorf("-title-weight must be between 0 and 1, got %v", *titleWeight)
+	}
+	switch *diffFormat {
+	case "words":
+	case "unified":
+		opts.Diff = true
+	default:
+		return fmt.Errorf("unknown diff format: %s", *diffFormat)
+	}
+	if len(upstreams) > 0 {
+		err := env.checkGo()
+		if err != nil {
+			return err
+		}
+		comparisons, err := compareUpstreams(env, upstreams, confidence, opts)
+		if err != nil {
+			return err
+		}
+		err = printUpstreamComparisons(os.Stdout, comparisons, *maxWords)
+		if err != nil {
+			return err
+		}
+		differing := 0
+		for _, c := range comparisons {
+			if !c.Same {
+				differing++
+			}
+		}
+		if differing > 0 {
+			return fmt.Errorf("%d packages license differ from upstream", differing)
+		}
+		return nil
+	}
+	if *statePath != "" {
+		opts.State, err = loadState(*statePath)
+		if err != nil {
+			return err
+		}
 	}
+	scan := func() error {
+		var licenses []License
+		if *manifest != "" || *binary != "" {
+			if flag.NArg() > 0 || len(projects) > 0 || *submodules ||
+				(*manifest != "" && *binary != "") {
+				return fmt.Errorf("-manifest and -binary exclude each other, package arguments, -project and -submodules")
+			}
+			if *manifest != "" {
+				licenses, err = listManifestLicenses(*manifest, opts)
+			} else {
+				licenses, err = listBinaryLicenses(*binary, opts)
+			}
+		} else if len(pkgs) > 0 && len(projects) > 0 {
+			licenses, err = listProjectsLicenses(env, projects, pkgs, opts)
+		} else if len(pkgs) > 0 {
+			licenses, err = listLicenses(env, pkgs, opts)
+		}
+		if err != nil {
+			return err
+		}
+		if *submodules {
+			modules, err := listSubmodulesLicenses(".", opts)
+			if err != nil {
+				return err
+			}
+			licenses = append(licenses, modules...)
+		}
+		setStatus(licenses, confidence, exact)
+		if *strict {
+			applyStrict(licenses)
+		}
+		violations := 0
+		if *policyPath != "" || len(allow) > 0 || len(deny) > 0 || len(trusted) > 0 {
+			policy := &Policy{}
+			if *policyPath != "" {
+				policy, err = loadPolicy(*policyPath)
+				if err != nil {
+					return err
+				}
+			}
+			policy.Allow = append(policy.Allow, allow...)
+			policy.Deny = append(policy.Deny, deny...)
+			policy.Trusted = append(policy.Trusted, trusted...)
+			violations = applyPolicy(licenses, policy)
+		}
+		var suggestions []Suggestion
+		if *suggest {
+			minScore := scaleConfidence(*algorithm, suggestMinScore)
+			suggestions = suggestTemplates(licenses, minScore, confidence)
+		}
+		var conflicts []Conflict
+		if *checkCompat {
+			conflicts = checkCompatibility(licenses)
+		}
+		changed := []string{}
+		if *updateBaseline {
+			if *baselinePath == "" {
+				return fmt.Errorf("-update-baseline requires -baseline")
+			}
+			err = writeBaseline(*baselinePath, makeBaseline(licenses))
+			if err != nil {
+				return err
+			}
+		} else if *baselinePath != "" {
+			baseline, err := loadBaseline(*baselinePath)
+			if err != nil {
+				return err
+			}
+			changed = applyBaseline(licenses, baseline)
+		}
+		var mismatches []LockMismatch
+		if *verify || *updateLock {
+			if *lockPath == "" || (*verify && *updateLock) {
+				return fmt.Errorf("-verify and -update-lock exclude each other and require -lock")
+			}
+			if *updateLock {
+				err = writeLock(*lockPath, makeLock(licenses))
+				if err != nil {
+					return err
+				}
+			} else {
+				lock, err := loadLock(*lockPath)
+				if err != nil {
+					return err
+				}
+				mismatches = verifyLock(licenses, lock)
+			}
+		} else if *lockPath != "" {
+			return fmt.Errorf("-lock requires -verify or -update-lock")
+		}
+		if !*all {
+			licenses, err = groupLicenses(licenses, *groupThreshold, *groupMembers)
+			if err != nil {
+				return err
+			}
+		}
+		if *dedupe > 0 {
+			licenses, err = dedupeLicenses(licenses, *dedupe)
+			if err != nil {
+				return err
+			}
+		}
 
-	w := tabwriter.NewWriter(os.Stdout, 1, 4, 2, ' ', 0)
-	for _, l := range licenses {
-		license := "?"
-		if l.Template != nil {
-			if l.Score > .99 {
-				license = fmt.Sprintf("%s", l.Template.Title)
-			} else if l.Score >= confidence {
-				license = fmt.Sprintf("%s (%2d%%)", l.Template.Title, int(100*l.Score))
-				if *words && len(l.ExtraWords) > 0 {
-					license += "\n\t+words: " + strings.Join(l.ExtraWords, ", ")
+		if *quiet {
+			licenses = filterProblems(licenses)
+		}
+		if *only != "" {
+			licenses = filterLicenses(licenses, strings.Split(*only, ","))
+		}
+		if *obligationsOnly {
+			licenses = filterObligations(licenses)
+		}
+
+		ro := ReportOptions{
+			Words:           *words,
+			MaxWords:        *maxWords,
+			SortBy:          *sortBy,
+			Ambiguity:       *ambiguity,
+			Categories:      *showCategories || *obligationsOnly,
+			Obligations:     *obligationsOnly,
+			ByLicense:       *byLicense,
+			Top:             *top,
+			ExplainGrouping: *explainGrouping,
+		}
+		ro.Color, err = useColor(*color, *report != "")
+		if err != nil {
+			return err
+		}
+		protobuf, xlsx := false, false
+		switch *format {
+		case "table":
+		case "md":
+			ro.Markdown = true
+		case "protobuf":
+			protobuf = true
+		case "xlsx", "excel":
+			if *report == "" {
+				return fmt.Errorf("-format %s requires a -r report file", *format)
+			}
+			xlsx = true
+		default:
+			return fmt.Errorf("unknown output format: %s", *format)
+		}
+		if *byLicense && (xlsx || protobuf || *jsonOut) {
+			return fmt.Errorf("-by-license requires -format table or md")
+		}
+		if xlsx {
+			err = generateXLSXReport(*report, licenses, ro)
+		} else if protobuf {
+			if *sortBy != "" || *top > 0 {
+				by := *sortBy
+				if by == "" {
+					by = "none"
 				}
-				if *words && len(l.MissingWords) > 0 {
-					license += "\n\t-words: " + strings.Join(l.MissingWords, ", ")
+				licenses, err = sortLicenses(licenses, by, *top)
+				if err != nil {
+					return err
 				}
+			}
+			if *report != "" {
+				err = writeProtobufReport(*report, licenses)
 			} else {
-				license = fmt.Sprintf("? (%s, %2d%%)", l.Template.Title, int(100*l.Score))
+				err = writeProtobuf(os.Stdout, licenses)
 			}
-		} else if l.Err != "" {
-			license = strings.Replace(l.Err, "\n", " ", -1)
+		} else if *jsonOut {
+			if *sortBy != "" || *top > 0 {
+				by := *sortBy
+				if by == "" {
+					by = "none"
+				}
+				licenses, err = sortLicenses(licenses, by, *top)
+				if err != nil {
+					return err
+				}
+			}
+			var meta *Metadata
+			meta, err = collectMetadata(env, pkgs, projects, confidence)
+			if err != nil {
+				return err
+			}
+			if *report != "" {
+				err = writeJSONReport(*report, licenses, meta, *pretty)
+			} else {
+				err = writeJSON(os.Stdout, licenses, meta, *pretty)
+			}
+		} else if *report != "" {
+			err = generateReport(*report, licenses, ro)
+		} else if ro.ByLicense {
+			err = printLicenseSections(os.Stdout, licenses, ro)
+		} else if ro.Markdown {
+			err = printMarkdownTable(os.Stdout, licenses, ro)
+		} else {
+			err = printTable(os.Stdout, licenses, ro)
+		}
+		if err != nil {
+			return err
+		}
+		err = printSuggestions(os.Stderr, suggestions)
+		if err != nil {
+			return err
+		}
+		failed := 0
+		for _, l := range licenses {
+			if l.Status == StatusError {
+				failed++
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d packages licenses could not be read", failed)
+		}
+		if violations > 0 {
+			return fmt.Errorf("%d packages violate the license policy", violations)
+		}
+		if len(mismatches) > 0 {
+			err = printLockMismatches(os.Stderr, mismatches)
+			if err != nil {
+				return err
+			}
+			return fmt.Errorf("%d packages disagree with the lock file", len(mismatches))
+		}
+		if len(conflicts) > 0 {
+			err = printConflicts(os.Stderr, conflicts)
+			if err != nil {
+				return err
+			}
+			return fmt.Errorf("%d pairs of licenses are incompatible", len(conflicts))
+		}
+		if len(changed) > 0 {
+			return fmt.Errorf("%d packages license changed since baseline: %s",
+				len(changed), strings.Join(changed, ", "))
+		}
+		return nil
+	}
+	if !*watch {
+		return scan()
+	}
+	dirs := []string{}
+	for _, project := range projects {
+		root, err := moduleRoot(env, project)
+		if err != nil {
+			return err
+		}
+		dirs = append(dirs, root)
+	}
+	if len(dirs) == 0 {
+		root, err := moduleRoot(env, ".")
+		if err != nil {
+			return err
 		}
-		_, err = w.Write([]byte(l.Package + "\t" + license + "\n"))
+		dirs = append(dirs, root)
+	}
+	for {
+		last, err := snapshotFiles(dirs)
+		if err != nil {
+			return err
+		}
+		err = scan()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		}
+		err = waitForChanges(dirs, last, watchInterval, watchQuiet)
 		if err != nil {
 			return err
 		}
+		fmt.Fprintf(os.Stderr, "module files changed, rescanning\n")
 	}
-	return w.Flush()
 }
 
 func main() {
 	err := printLicenses()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		// With -json, errors are JSON as well so wrappers parse a single
+		// format.
+		if f := flag.Lookup("json"); f != nil && f.Value.String() == "true" {
+			writeJSONError(os.Stderr, err)
+		} else {
+			fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		}
 		os.Exit(1)
 	}
 }
@@ -1,8 +1,14 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
+	"io/ioutil"
+	"os"
 	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
 	"strings"
 	"testing"
 )
@@ -17,11 +23,15 @@ type testResult struct {
 }
 
 func listTestLicenses(pkgs []string) ([]testResult, error) {
+	return listTestLicensesWithOptions(pkgs, Options{})
+}
+
+func listTestLicensesWithOptions(pkgs []string, opts Options) ([]testResult, error) {
 	gopath, err := filepath.Abs("testdata")
 	if err != nil {
 		return nil, err
 	}
-	licenses, err := listLicenses(gopath, pkgs)
+	licenses, err := listLicenses(&GoEnv{GOPATH: gopath}, pkgs, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -31,7 +41,7 @@ func listTestLicenses(pkgs []string) ([]testResult, error) {
 			Package: l.Package,
 		}
 		if l.Template != nil {
-			r.License = l.Template.Title
+			r.License = l.Title()
 			r.Score = int(100 * l.Score)
 		}
 		if l.Err != "" {
@@ -45,6 +55,12 @@ func listTestLicenses(pkgs []string) ([]testResult, error) {
 }
 
 func compareTestLicenses(pkgs []string, wanted []testResult) error {
+	return compareTestLicensesWithOptions(pkgs, Options{}, wanted)
+}
+
+func compareTestLicensesWithOptions(pkgs []string, opts Options,
+	wanted []testResult) error {
+
 	stringify := func(res []testResult) string {
 		parts := []string{}
 		for _, r := range res {
@@ -63,7 +79,7 @@ func compareTestLicenses(pkgs []string, wanted []testResult) error {
 		return strings.Join(parts, "\n")
 	}
 
-	licenses, err := listTestLicenses(pkgs)
+	licenses, err := listTestLicensesWithOptions(pkgs, opts)
 	if err != nil {
 		return err
 	}
@@ -84,131 +100,1438 @@ func TestNoDependencies(t *testing.T) {
 	}
 }
 
-func TestMultipleLicenses(t *testing.T) {
-	err := compareTestLicenses([]string{"colors/blue"}, []testResult{
-		{Package: "colors/blue", License: "Apache License 2.0", Score: 100},
+func TestFrontMatter(t *testing.T) {
+	err := compareTestLicenses([]string{"colors/red", "colors/tan"}, []testResult{
+		{Package: "colors/red", License: "MIT License", Score: 98, Missing: 2},
+		{Package: "colors/tan", License: "MIT License", Score: 98, Missing: 2},
 	})
 	if err != nil {
 		t.Fatal(err)
 	}
+	for _, test := range []struct {
+		Data     string
+		Expected string
+	}{
+		{"---\ntitle: License\ntags:\n  - legal\n---\ntext", "\ntext"},
+		{"\r\n---\r\ntitle: License\r\n---\r\ntext", "\ntext"},
+		// Markdown rules around text are not front matter.
+		{"---\nSome license text.\n---\ntext", "---\nSome license text.\n---\ntext"},
+		{"---\ntitle: License\ntext", "---\ntitle: License\ntext"},
+		{"text\n---\ntitle: License\n---\n", "text\n---\ntitle: License\n---\n"},
+	} {
+		if got := string(stripFrontMatter([]byte(test.Data))); got != test.Expected {
+			t.Errorf("%q: expected %q, got %q", test.Data, test.Expected, got)
+		}
+	}
 }
 
-func TestNoLicense(t *testing.T) {
-	err := compareTestLicenses([]string{"colors/green"}, []testResult{
-		{Package: "colors/green", License: "", Score: 0},
+func TestPlaceholders(t *testing.T) {
+	err := compareTestLicenses([]string{"colors/orange"}, []testResult{
+		{Package: "colors/orange", License: `BSD 3-clause "New" or "Revised" License`,
+			Score: 100},
 	})
 	if err != nil {
 		t.Fatal(err)
 	}
 }
 
-func TestMainWithDependencies(t *testing.T) {
-	// It also tests license retrieval in parent directory.
-	err := compareTestLicenses([]string{"colors/cmd/paint"}, []testResult{
-		{Package: "colors/cmd/paint", License: "Academic Free License v3.0", Score: 100},
-		{Package: "colors/red", License: "MIT License", Score: 98, Missing: 2},
+func TestEnumeration(t *testing.T) {
+	err := compareTestLicenses([]string{"colors/navy", "colors/olive"}, []testResult{
+		{Package: "colors/navy", License: `BSD 3-clause "New" or "Revised" License`,
+			Score: 100},
+		{Package: "colors/olive", License: `BSD 3-clause "New" or "Revised" License`,
+			Score: 100},
 	})
 	if err != nil {
 		t.Fatal(err)
 	}
+	words := makeWordSet([]byte("1. Definitions.\n  (a) license\niv) terms\n2.1 grant\nversion 2.0\n"))
+	for _, w := range []string{"1", "a", "iv"} {
+		if _, ok := words[w]; ok {
+			t.Fatalf("enumeration token %q not stripped: %v", w, words)
+		}
+	}
+	if _, ok := words["2"]; !ok {
+		t.Fatalf("inline numbers should be preserved: %v", words)
+	}
 }
 
-func TestMainWithAliasedDependencies(t *testing.T) {
-	err := compareTestLicenses([]string{"colors/cmd/mix"}, []testResult{
-		{Package: "colors/cmd/mix", License: "Academic Free License v3.0", Score: 100},
-		{Package: "colors/red", License: "MIT License", Score: 98, Missing: 2},
-		{Package: "couleurs/red", License: "GNU Lesser General Public License v2.1",
+func TestMatchLicense(t *testing.T) {
+	templates, err := loadTemplates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "src", "colors", "pink",
+		"LICENSE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := MatchLicense(templates, data, Options{})
+	if r.Template == nil || r.Template.Title != "MIT License" || r.Trimmed {
+		t.Fatalf("unexpected match: %+v", r)
+	}
+	trimmed := MatchLicense(templates, data, Options{TrimTrailing: true})
+	if !trimmed.Trimmed || trimmed.Score <= r.Score {
+		t.Fatalf("trimmed match expected: %+v", trimmed)
+	}
+}
+
+func TestTitleWeight(t *testing.T) {
+	templates, err := loadTemplates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	gpl2, err := findTemplate(templates, "GPL-2.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gpl3, err := findTemplate(templates, "GPL-3.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gpl2.TitleLen != 4 || gpl3.TitleLen != 4 {
+		t.Fatalf("unexpected GPL titles lengths: %d, %d", gpl2.TitleLen, gpl3.TitleLen)
+	}
+	for _, test := range []struct {
+		Package string
+		Right   *Template
+		Wrong   *Template
+	}{
+		{"gold", gpl2, gpl3},
+		{"plum", gpl3, gpl2},
+	} {
+		data, err := ioutil.ReadFile(filepath.Join("testdata", "src", "colors",
+			test.Package, "LICENSE"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		margin := func(weight float64) float64 {
+			right := matchTemplate(data, test.Right, weight, "")
+			wrong := matchTemplate(data, test.Wrong, weight, "")
+			return right.Score - wrong.Score
+		}
+		if margin(0.1) <= margin(0) {
+			t.Fatalf("%s: title weight did not improve separation: %v <= %v",
+				test.Package, margin(0.1), margin(0))
+		}
+		r := MatchLicense(templates, data, Options{TitleWeight: 0.1})
+		if r.Template != test.Right || r.Score != 1 {
+			t.Fatalf("%s: unexpected match: %+v", test.Package, r)
+		}
+	}
+}
+
+func BenchmarkMatchLicense(b *testing.B) {
+	templates, err := loadTemplates()
+	if err != nil {
+		b.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "src", "colors", "blue",
+		"LICENSE"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		MatchLicense(templates, data, Options{})
+	}
+}
+
+func TestLazyTemplateWords(t *testing.T) {
+	templates, err := loadTemplates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tpl := range templates {
+		if tpl.words != nil {
+			t.Fatalf("%s words were computed at load time", tpl.Title)
+		}
+	}
+	if err := checkTemplates(templates); err != nil {
+		t.Fatal(err)
+	}
+	for _, tpl := range templates {
+		if tpl.words != nil {
+			t.Fatalf("%s words were computed by validation", tpl.Title)
+		}
+	}
+	// Preparing a scan does not compute them either.
+	m, err := prepareMatcher(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tpl := range m.templates {
+		if tpl.words != nil {
+			t.Fatalf("%s words were computed by prepareMatcher", tpl.Title)
+		}
+	}
+	// Concurrent first uses compute the same word set.
+	tpl := templates[0]
+	sizes := make(chan int, 8)
+	for i := 0; i < cap(sizes); i++ {
+		go func() {
+			sizes <- len(tpl.Words())
+		}()
+	}
+	for i := 0; i < cap(sizes); i++ {
+		if size := <-sizes; size == 0 || size != len(tpl.Words()) {
+			t.Fatalf("unexpected %s word set size: %d", tpl.Title, size)
+		}
+	}
+}
+
+func TestDuplicateTemplates(t *testing.T) {
+	templates, err := loadTemplates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if duplicates := duplicateTemplates(templates); len(duplicates) != 0 {
+		t.Fatalf("embedded templates have identical word sets: %v", duplicates)
+	}
+	a := &Template{Title: "A", text: []byte("some license words")}
+	b := &Template{Title: "B", text: []byte("Words, some LICENSE words.")}
+	c := &Template{Title: "C", text: []byte("some license words and more")}
+	duplicates := duplicateTemplates([]*Template{a, c, b})
+	if !reflect.DeepEqual(duplicates, [][]*Template{{a, b}}) {
+		t.Fatalf("unexpected duplicates: %v", duplicates)
+	}
+	out := &bytes.Buffer{}
+	err = warnDuplicateTemplates(out, []*Template{a, c, b})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wanted := "warning: templates \"A\", \"B\" have identical word sets, only \"A\" can be matched\n"
+	if out.String() != wanted {
+		t.Fatalf("unexpected warning: %q", out.String())
+	}
+}
+
+func BenchmarkLoadTemplates(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_, err := loadTemplates()
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestZeroClauseBSD(t *testing.T) {
+	err := compareTestLicenses([]string{"colors/indigo", "colors/orange"}, []testResult{
+		{Package: "colors/indigo", License: "BSD Zero Clause License", Score: 100},
+		{Package: "colors/orange", License: `BSD 3-clause "New" or "Revised" License`,
 			Score: 100},
 	})
 	if err != nil {
 		t.Fatal(err)
 	}
+	templates, err := loadTemplates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	isc, err := findTemplate(templates, "ISC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bsd0, err := findTemplate(templates, "BSD-0-Clause")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// ISC is 0BSD plus a notice retention clause.
+	data := []byte("Copyright (c) 2016, Foo\n\n" +
+		"Permission to use, copy, modify, and/or distribute this software for any\n" +
+		"purpose with or without fee is hereby granted, provided that the above\n" +
+		"copyright notice and this permission notice appear in all copies.\n" +
+		strings.Join(bsd0.Tail(), " "))
+	r := MatchLicense([]*Template{bsd0, isc}, data, Options{})
+	if r.Template != isc {
+		t.Fatalf("ISC text matched %s", r.Template.Title)
+	}
 }
 
-func TestMissingPackage(t *testing.T) {
-	_, err := listTestLicenses([]string{"colors/missing"})
-	if err == nil {
-		t.Fatal("no error on missing package")
+func TestTranslatedTemplates(t *testing.T) {
+	err := compareTestLicenses([]string{"colors/beige", "colors/cyan", "colors/red"},
+		[]testResult{
+			{Package: "colors/beige", License: "MIT License (German)", Score: 100},
+			{Package: "colors/cyan", License: "MIT License (French)", Score: 100},
+			{Package: "colors/red", License: "MIT License", Score: 98, Missing: 2},
+		})
+	if err != nil {
+		t.Fatal(err)
 	}
-	if _, ok := err.(*MissingError); !ok {
-		t.Fatalf("MissingError expected")
+	templates, err := loadTemplates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	languages := map[string]string{}
+	for _, templ := range templates {
+		if templ.SPDX == "MIT" {
+			languages[templ.Title] = templ.Language
+		}
+	}
+	if languages["MIT License"] != "" || languages["MIT License (German)"] != "de" ||
+		languages["MIT License (French)"] != "fr" {
+		t.Fatalf("unexpected MIT templates languages: %v", languages)
 	}
 }
 
-func TestMismatch(t *testing.T) {
-	err := compareTestLicenses([]string{"colors/yellow"}, []testResult{
-		{Package: "colors/yellow", License: "Microsoft Reciprocal License", Score: 25,
-			Extra: 106, Missing: 131},
+func TestTrimTrailing(t *testing.T) {
+	err := compareTestLicenses([]string{"colors/pink"}, []testResult{
+		{Package: "colors/pink", License: "MIT License", Score: 87, Extra: 25, Missing: 2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = compareTestLicensesWithOptions([]string{"colors/pink"}, Options{
+		TrimTrailing: true,
+	}, []testResult{
+		{Package: "colors/pink", License: "MIT License", Score: 98, Missing: 2},
 	})
 	if err != nil {
 		t.Fatal(err)
 	}
 }
 
-func TestNoBuildableGoSourceFiles(t *testing.T) {
-	_, err := listTestLicenses([]string{"colors/cmd"})
-	if err == nil {
-		t.Fatal("no error on missing package")
+func TestLicensesDirectory(t *testing.T) {
+	err := compareTestLicenses([]string{"colors/teal"}, []testResult{
+		{Package: "colors/teal", License: "Apache License 2.0 AND MIT License",
+			Score: 98, Missing: 2},
+	})
+	if err != nil {
+		t.Fatal(err)
 	}
-	if _, ok := err.(*MissingError); !ok {
-		t.Fatalf("MissingError expected")
+}
+
+func TestTruncated(t *testing.T) {
+	gopath, err := filepath.Abs("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	licenses, err := listLicenses(&GoEnv{GOPATH: gopath},
+		[]string{"colors/gray", "colors/red"}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	truncated := map[string]bool{}
+	for _, l := range licenses {
+		truncated[l.Package] = l.Truncated()
+	}
+	if !truncated["colors/gray"] || truncated["colors/red"] {
+		t.Fatalf("unexpected truncated licenses: %v", truncated)
 	}
 }
 
-func TestBroken(t *testing.T) {
-	err := compareTestLicenses([]string{"colors/broken"}, []testResult{
-		{Package: "colors/broken", License: "GNU General Public License v3.0", Score: 100},
-		{Package: "colors/missing", License: "", Score: 0, Err: "some error"},
-		{Package: "colors/red", License: "MIT License", Score: 98, Missing: 2},
+func TestEmptyOrBinaryLicense(t *testing.T) {
+	err := compareTestLicenses([]string{"colors/black", "colors/white"}, []testResult{
+		{Package: "colors/black", Err: "some error"},
+		{Package: "colors/white", Err: "some error"},
 	})
 	if err != nil {
 		t.Fatal(err)
 	}
+	if msg := checkLicenseData([]byte(" \n\t")); msg != "empty license file" {
+		t.Fatalf("unexpected empty file check: %q", msg)
+	}
+	if msg := checkLicenseData([]byte("\x89PNG\r\n\x1a\n\x00")); msg != "binary license file" {
+		t.Fatalf("unexpected binary file check: %q", msg)
+	}
+	tar := make([]byte, 512)
+	copy(tar, "LICENSE")
+	copy(tar[257:], "ustar\x0000")
+	for _, data := range [][]byte{
+		[]byte("PK\x03\x04\x14\x00"),
+		[]byte("\x1f\x8b\x08\x00"),
+		[]byte("BZh91AY&SY"),
+		tar,
+	} {
+		if msg := checkLicenseData(data); msg != "invalid license file (archive)" {
+			t.Fatalf("unexpected archive check for %q: %q", data[:4], msg)
+		}
+	}
+	if msg := checkLicenseData([]byte("BZh is not an archive")); msg != "" {
+		t.Fatalf("text rejected: %q", msg)
+	}
+	if msg := checkLicenseData([]byte("Copyright \xa9 2016 \xc9cole, MIT")); msg != "" {
+		t.Fatalf("text with a few invalid characters rejected: %q", msg)
+	}
 }
 
-func TestBrokenDependency(t *testing.T) {
-	err := compareTestLicenses([]string{"colors/purple"}, []testResult{
-		{Package: "colors/broken", License: "GNU General Public License v3.0", Score: 100},
-		{Package: "colors/missing", License: "", Score: 0, Err: "some error"},
-		{Package: "colors/purple", License: "", Score: 0},
-		{Package: "colors/red", License: "MIT License", Score: 98, Missing: 2},
+func TestNameWeight(t *testing.T) {
+	gopath, err := filepath.Abs("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	licenses, err := listLicenses(&GoEnv{GOPATH: gopath},
+		[]string{"colors/cmd/paint", "colors/red"}, Options{NameWeight: 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, l := range licenses {
+		// LICENSE.md scores 0.9 and LICENSE 1.0.
+		name := 1.0
+		if l.Package == "colors/cmd/paint" {
+			name = 0.9
+		}
+		wanted := 0.5*l.TextScore + 0.5*name
+		if l.TextScore == 0 || l.Score != wanted {
+			t.Fatalf("%s: unexpected blended score %v, text score %v", l.Package,
+				l.Score, l.TextScore)
+		}
+	}
+}
+
+func TestMinNameScore(t *testing.T) {
+	// colors/rose only has a license checker configuration file, whose name
+	// contains "license".
+	err := compareTestLicenses([]string{"colors/rose"}, []testResult{
+		{Package: "colors/rose", License: `"Do What The F*ck You Want To Public License"`,
+			Score: 16, Extra: 14, Missing: 35},
 	})
 	if err != nil {
 		t.Fatal(err)
 	}
+	err = compareTestLicensesWithOptions([]string{"colors/rose"}, Options{MinNameScore: 0.7},
+		[]testResult{
+			{Package: "colors/rose"},
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
 }
 
-func TestPackageExpression(t *testing.T) {
-	err := compareTestLicenses([]string{"colors/cmd/..."}, []testResult{
-		{Package: "colors/cmd/mix", License: "Academic Free License v3.0", Score: 100},
-		{Package: "colors/cmd/paint", License: "Academic Free License v3.0", Score: 100},
+func TestNotice(t *testing.T) {
+	err := compareTestLicenses([]string{"colors/silver"}, []testResult{
+		{Package: "colors/silver", License: "Apache License 2.0", Score: 100},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	gopath, err := filepath.Abs("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	licenses, err := listLicenses(&GoEnv{GOPATH: gopath},
+		[]string{"colors/red", "colors/silver"}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	notices := map[string]string{}
+	for _, l := range licenses {
+		notices[l.Package] = l.Notice
+	}
+	if notices["colors/silver"] != filepath.Join("colors", "silver", "NOTICE") ||
+		notices["colors/red"] != "" {
+		t.Fatalf("unexpected notices: %v", notices)
+	}
+	for _, name := range []string{"NOTICE", "NOTICE.txt", "THIRD_PARTY_LICENSES",
+		"third-party-notices.md"} {
+		if score := scoreLicenseName(name); score != 0 {
+			t.Fatalf("%s should not be a license file, got %v", name, score)
+		}
+	}
+}
+
+func TestRoot(t *testing.T) {
+	root, err := filepath.Abs(filepath.Join("testdata", "src", "colors", "cmd", "paint"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The lookup stops at paint, before colors/cmd/LICENSE.md.
+	err = compareTestLicensesWithOptions([]string{"colors/cmd/paint"}, Options{
+		Root: root,
+	}, []testResult{
+		{Package: "colors/cmd/paint", License: "", Score: 0},
 		{Package: "colors/red", License: "MIT License", Score: 98, Missing: 2},
-		{Package: "couleurs/red", License: "GNU Lesser General Public License v2.1",
-			Score: 100},
 	})
 	if err != nil {
 		t.Fatal(err)
 	}
+	if !isBeneath("/a/b", "/a") || !isBeneath("/a", "/a") || isBeneath("/ab", "/a") ||
+		isBeneath("/", "/a") {
+		t.Fatal("unexpected isBeneath results")
+	}
 }
 
-func TestCleanLicenseData(t *testing.T) {
-	data := `The MIT License (MIT)
+func TestImportPathDirs(t *testing.T) {
+	dirs := importPathDirs("github.com/user/repo")
+	expected := []string{
+		filepath.Join("github.com", "user", "repo"),
+		filepath.Join("github.com", "user"),
+		"github.com",
+	}
+	if strings.Join(dirs, "|") != strings.Join(expected, "|") {
+		t.Fatalf("unexpected import path directories: %q", dirs)
+	}
+}
 
-	Copyright (c) 2013 Ben Johnson
-	
-	Some other lines.
-	And more.
-	`
-	cleaned := string(cleanLicenseData([]byte(data)))
-	wanted := "the mit license (mit)\n\t\n\tsome other lines.\n\tand more.\n\t"
-	if wanted != cleaned {
-		t.Fatalf("license data mismatch: %q\n!=\n%q", cleaned, wanted)
+func TestWindowsRoot(t *testing.T) {
+	if runtime.GOOS != "windows" {
+		t.Skip("backslashes only separate paths on Windows")
+	}
+	paint, err := filepath.Abs(filepath.Join("testdata", "src", "colors", "cmd", "paint"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := &PkgInfo{
+		Dir:        paint,
+		Root:       filepath.Join(paint, "..", "..", "..", ".."),
+		ImportPath: "colors/cmd/paint",
+	}
+	for _, test := range []struct {
+		Top  string
+		Path string
+	}{
+		{"", `colors\cmd\LICENSE.md`},
+		{strings.Replace(paint, "/", `\`, -1), ""},
+		{strings.Replace(filepath.Dir(paint), "/", `\`, -1) + `\`, `colors\cmd\LICENSE.md`},
+	} {
+		path, err := findLicense(info, test.Top, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if path != test.Path {
+			t.Errorf("unexpected license path with root %q: %q", test.Top, path)
+		}
+	}
+	if !isBeneath(`C:\gopath\src\a`, `C:\gopath`) || isBeneath(`C:\gopathx\a`, `C:\gopath`) {
+		t.Fatal("unexpected isBeneath results")
 	}
 }
 
-func TestStandardPackages(t *testing.T) {
-	err := compareTestLicenses([]string{"encoding/json", "cmd/addr2line"}, []testResult{})
+func TestStatus(t *testing.T) {
+	mit := &Template{Title: "MIT License"}
+	tests := []struct {
+		License License
+		Status  Status
+	}{
+		{License{Template: mit, Score: 1, Path: "LICENSE"}, StatusExact},
+		{License{Template: mit, Score: 0.95, Path: "LICENSE"}, StatusApproximate},
+		{License{Template: mit, Score: 0.5, Path: "LICENSE"}, StatusLowConfidence},
+		{License{Path: "LICENSE"}, StatusUnknown},
+		{License{}, StatusMissing},
+		{License{Err: "binary license file", Path: "LICENSE"}, StatusError},
+	}
+	licenses := []License{}
+	for _, test := range tests {
+		licenses = append(licenses, test.License)
+	}
+	setStatus(licenses, 0.9, exactScore)
+	for i, test := range tests {
+		if licenses[i].Status != test.Status {
+			t.Errorf("%+v: expected %s, got %s", test.License, test.Status,
+				licenses[i].Status)
+		}
+	}
+}
+
+func TestJaccardStatus(t *testing.T) {
+	// Jaccard scores D/(2-D) where dice scores D, thresholds are scaled
+	// alike.
+	mit := &Template{Title: "MIT License"}
+	licenses := []License{
+		{Template: mit, Score: 0.992 / 1.008, Path: "LICENSE"},
+		{Template: mit, Score: 0.95 / 1.05, Path: "LICENSE"},
+		{Template: mit, Score: 0.85 / 1.15, Path: "LICENSE"},
+	}
+	exact := scaleConfidence("jaccard", exactScore)
+	setStatus(licenses, scaleConfidence("jaccard", 0.9), exact)
+	expected := []Status{StatusExact, StatusApproximate, StatusLowConfidence}
+	for i, status := range expected {
+		if licenses[i].Status != status {
+			t.Errorf("%+v: expected %s, got %s", licenses[i], status, licenses[i].Status)
+		}
+	}
+	setStatus(licenses, strictConfidence(exact), exact)
+	applyStrict(licenses)
+	if licenses[0].Status != StatusExact || licenses[1].Status != StatusUnknown {
+		t.Fatalf("unexpected jaccard strict statuses: %s, %s", licenses[0].Status,
+			licenses[1].Status)
+	}
+	// A dice score of 0.75 is a suggestion, not a jaccard one of 0.6.
+	licenses = []License{{Package: "a", Template: mit, Score: 0.75 / 1.25}}
+	suggestions := suggestTemplates(licenses,
+		scaleConfidence("jaccard", suggestMinScore), scaleConfidence("jaccard", 0.9))
+	if len(suggestions) != 1 {
+		t.Fatalf("unexpected jaccard suggestions: %+v", suggestions)
+	}
+}
+
+func TestStrictStatus(t *testing.T) {
+	mit := &Template{Title: "MIT License"}
+	licenses := []License{
+		{Template: mit, Score: 1, Path: "LICENSE"},
+		{Template: mit, Score: exactScore, Path: "LICENSE"},
+		{Template: mit, Score: 0.5, Path: "LICENSE"},
+		{},
+	}
+	strict := strictConfidence(exactScore)
+	setStatus(licenses, strict, exactScore)
+	applyStrict(licenses)
+	expected := []Status{StatusExact, StatusUnknown, StatusUnknown, StatusMissing}
+	for i, status := range expected {
+		if licenses[i].Status != status {
+			t.Errorf("%+v: expected %s, got %s", licenses[i], status, licenses[i].Status)
+		}
+	}
+	// Sub-exact matches are unknown licenses for policies too.
+	p := &Policy{Allow: []string{"MIT"}}
+	if v := p.Check(&licenses[1]); v != "unknown license" {
+		t.Fatalf("unexpected strict violation: %q", v)
+	}
+	if !licenses[1].isProblem() || licenses[0].isProblem() {
+		t.Fatal("unexpected strict problems")
+	}
+}
+
+func TestScanPackageError(t *testing.T) {
+	templates, err := loadTemplates()
 	if err != nil {
 		t.Fatal(err)
 	}
+	m := newMatcher(templates, nil, Options{})
+	info := &PkgInfo{
+		Name:       "gone",
+		Dir:        filepath.Join("testdata", "src", "colors", "gone"),
+		Root:       "testdata",
+		ImportPath: "colors/gone",
+	}
+	l, _, err := m.scanPackage(info, map[string][]LicenseFile{})
+	if err == nil || l.Package != "colors/gone" {
+		t.Fatalf("package error expected, got %+v, %v", l, err)
+	}
+}
+
+func TestMultipleLicenses(t *testing.T) {
+	// LICENSE and COPYING hold licenses applying together, the score is the
+	// lowest of both.
+	err := compareTestLicenses([]string{"colors/blue"}, []testResult{
+		{Package: "colors/blue", License: "Apache License 2.0 AND MIT License",
+			Score: 98, Missing: 2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNoLicense(t *testing.T) {
+	err := compareTestLicenses([]string{"colors/green"}, []testResult{
+		{Package: "colors/green", License: "", Score: 0},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMainWithDependencies(t *testing.T) {
+	// It also tests license retrieval in parent directory.
+	err := compareTestLicenses([]string{"colors/cmd/paint"}, []testResult{
+		{Package: "colors/cmd/paint", License: "Academic Free License v3.0", Score: 100},
+		{Package: "colors/red", License: "MIT License", Score: 98, Missing: 2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMainModule(t *testing.T) {
+	gopath, err := filepath.Abs("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := &GoEnv{GOPATH: gopath}
+	licenses, err := listLicenses(env, []string{"colors/cmd/paint"}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(licenses) != 2 || !licenses[0].MainModule || licenses[1].MainModule {
+		t.Fatalf("unexpected main module licenses: %+v", licenses)
+	}
+	err = compareTestLicensesWithOptions([]string{"colors/cmd/paint"}, Options{NoSelf: true},
+		[]testResult{
+			{Package: "colors/red", License: "MIT License", Score: 98, Missing: 2},
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMainWithAliasedDependencies(t *testing.T) {
+	err := compareTestLicenses([]string{"colors/cmd/mix"}, []testResult{
+		{Package: "colors/cmd/mix", License: "Academic Free License v3.0", Score: 100},
+		{Package: "colors/red", License: "MIT License", Score: 98, Missing: 2},
+		{Package: "couleurs/red", License: "GNU Lesser General Public License v2.1",
+			Score: 100},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMissingPackage(t *testing.T) {
+	_, err := listTestLicenses([]string{"colors/missing"})
+	if err == nil {
+		t.Fatal("no error on missing package")
+	}
+	if _, ok := err.(*MissingError); !ok {
+		t.Fatalf("MissingError expected")
+	}
+}
+
+func TestOfflineEnv(t *testing.T) {
+	t.Setenv("GOFLAGS", "-tags=foo")
+	env := &GoEnv{GOPATH: "/gopath", Offline: true}
+	environ := env.environ()
+	if lookupEnv(environ, "GOPATH") != "/gopath" || lookupEnv(environ, "GOPROXY") != "off" ||
+		lookupEnv(environ, "GOFLAGS") != "-tags=foo -mod=mod" {
+		t.Fatalf("unexpected offline environment: %v", environ)
+	}
+	env.Mod = "readonly"
+	if flags := lookupEnv(env.environ(), "GOFLAGS"); flags != "-tags=foo" {
+		t.Fatalf("-mod should not be forced with GoEnv.Mod: %s", flags)
+	}
+
+	dir, err := ioutil.TempDir("", "licenses-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, d := range []string{"vendor", "sub"} {
+		if err := os.Mkdir(filepath.Join(dir, d), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/m\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env = &GoEnv{Dir: filepath.Join(dir, "sub"), Offline: true}
+	if flags := lookupEnv(env.environ(), "GOFLAGS"); flags != "-tags=foo" {
+		t.Fatalf("-mod should not be forced on vendored modules: %s", flags)
+	}
+}
+
+func TestSkipToJSON(t *testing.T) {
+	data := "go: warning: some build warning\n{\"ImportPath\": \"colors/red\"}\n"
+	got := string(skipToJSON([]byte(data)))
+	if got != "{\"ImportPath\": \"colors/red\"}\n" {
+		t.Fatalf("unexpected JSON stream: %q", got)
+	}
+	if got := string(skipToJSON([]byte("no json"))); got != "no json" {
+		t.Fatalf("unexpected output: %q", got)
+	}
+}
+
+func TestDecodePackagesInfo(t *testing.T) {
+	out := `{"ImportPath": "colors/blue", "Name": "blue"}
+{"Error": {"Err": "broken.go:9:1: expected declaration"}}
+{"ImportPath": "colors/red", "Name": "red"}
+`
+	infos, err := decodePackagesInfo([]byte(out),
+		[]string{"colors/red", "colors/broken", "colors/blue"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := []string{}
+	for _, info := range infos {
+		s := info.ImportPath + " " + info.Name
+		if info.Error != nil {
+			s += " " + info.Error.Err
+		}
+		got = append(got, s)
+	}
+	wanted := []string{
+		"colors/red red",
+		"colors/broken colors/broken broken.go:9:1: expected declaration",
+		"colors/blue blue",
+	}
+	if strings.Join(got, "\n") != strings.Join(wanted, "\n") {
+		t.Fatalf("unexpected package information:\n%s", strings.Join(got, "\n"))
+	}
+	_, err = decodePackagesInfo([]byte(`{"ImportPath": `), []string{"colors/red"})
+	if err == nil {
+		t.Fatal("truncated output should fail")
+	}
+}
+
+func TestShowLicenseText(t *testing.T) {
+	gopath, err := filepath.Abs("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := &bytes.Buffer{}
+	err = showLicenseText(&GoEnv{GOPATH: gopath}, buf, "colors/cmd/paint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wanted, err := ioutil.ReadFile(filepath.Join("testdata", "src", "colors", "cmd",
+		"LICENSE.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != string(wanted) {
+		t.Fatalf("unexpected license text: %q", buf.String())
+	}
+	err = showLicenseText(&GoEnv{GOPATH: gopath}, buf, "colors/green")
+	if err == nil {
+		t.Fatal("showing missing license should fail")
+	}
+}
+
+func TestMissingGo(t *testing.T) {
+	path := os.Getenv("PATH")
+	defer os.Setenv("PATH", path)
+	os.Setenv("PATH", "")
+	_, err := listTestLicenses([]string{"colors/red"})
+	if err == nil || !strings.Contains(err.Error(), "could not find go executable") ||
+		!strings.Contains(err.Error(), "-manifest") {
+		t.Fatalf("missing go error expected, got %v", err)
+	}
+}
+
+func TestMismatch(t *testing.T) {
+	err := compareTestLicenses([]string{"colors/yellow"}, []testResult{
+		{Package: "colors/yellow", License: "Microsoft Reciprocal License", Score: 25,
+			Extra: 106, Missing: 124},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNoBuildableGoSourceFiles(t *testing.T) {
+	_, err := listTestLicenses([]string{"colors/cmd"})
+	if err == nil {
+		t.Fatal("no error on missing package")
+	}
+	if _, ok := err.(*MissingError); !ok {
+		t.Fatalf("MissingError expected")
+	}
+}
+
+func TestBroken(t *testing.T) {
+	err := compareTestLicenses([]string{"colors/broken"}, []testResult{
+		{Package: "colors/broken", License: "GNU General Public License v3.0", Score: 100},
+		{Package: "colors/missing", License: "", Score: 0, Err: "some error"},
+		{Package: "colors/red", License: "MIT License", Score: 98, Missing: 2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBrokenDependency(t *testing.T) {
+	err := compareTestLicenses([]string{"colors/purple"}, []testResult{
+		{Package: "colors/broken", License: "GNU General Public License v3.0", Score: 100},
+		{Package: "colors/missing", License: "", Score: 0, Err: "some error"},
+		{Package: "colors/purple", License: "", Score: 0},
+		{Package: "colors/red", License: "MIT License", Score: 98, Missing: 2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMaxDepth(t *testing.T) {
+	err := compareTestLicensesWithOptions([]string{"colors/purple"}, Options{
+		MaxDepth: 1,
+	}, []testResult{
+		{Package: "colors/broken", License: "GNU General Public License v3.0", Score: 100},
+		{Package: "colors/purple", License: "", Score: 0},
+		{Package: "colors/red", License: "MIT License", Score: 98, Missing: 2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestImportChain(t *testing.T) {
+	imports := map[string][]string{
+		"app":    {"lib", "util"},
+		"lib":    {"gpl", "util"},
+		"util":   {"gpl"},
+		"gpl":    {},
+		"unused": {"gpl"},
+	}
+	importers := walkImports([]string{"app"}, imports, -1)
+	tests := []struct {
+		Package string
+		Chain   []string
+	}{
+		{"app", []string{"app"}},
+		{"gpl", []string{"app", "lib", "gpl"}},
+		{"util", []string{"app", "util"}},
+		{"unused", nil},
+	}
+	for _, test := range tests {
+		chain := importChain(importers, test.Package)
+		if !reflect.DeepEqual(chain, test.Chain) {
+			t.Errorf("%s: expected chain %v, got %v", test.Package, test.Chain, chain)
+		}
+	}
+	deps := limitDepth([]string{"app"}, []string{"gpl", "lib", "unused", "util"}, imports, 1)
+	if !reflect.DeepEqual(deps, []string{"lib", "util"}) {
+		t.Fatalf("unexpected depth limited dependencies: %v", deps)
+	}
+
+	gopath, err := filepath.Abs("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	licenses, err := listLicenses(&GoEnv{GOPATH: gopath}, []string{"colors/cmd/paint"},
+		Options{ImportChains: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(licenses) != 2 || licenses[0].ImportChain != nil ||
+		!reflect.DeepEqual(licenses[1].ImportChain, []string{"colors/cmd/paint", "colors/red"}) {
+		t.Fatalf("unexpected import chains: %+v", licenses)
+	}
+}
+
+func TestIncludeCmd(t *testing.T) {
+	res, err := listTestLicensesWithOptions([]string{"cmd/addr2line"}, Options{
+		IncludeCmd: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) == 0 {
+		t.Fatal("cmd packages expected")
+	}
+	for _, r := range res {
+		if !strings.HasPrefix(r.Package, "cmd/") {
+			t.Fatalf("unexpected non-cmd package: %s", r.Package)
+		}
+		if r.License != `BSD 3-clause "New" or "Revised" License` {
+			t.Fatalf("unexpected %s license: %q", r.Package, r.License)
+		}
+	}
+}
+
+func TestPackageExpression(t *testing.T) {
+	err := compareTestLicenses([]string{"colors/cmd/..."}, []testResult{
+		{Package: "colors/cmd/mix", License: "Academic Free License v3.0", Score: 100},
+		{Package: "colors/cmd/paint", License: "Academic Free License v3.0", Score: 100},
+		{Package: "colors/red", License: "MIT License", Score: 98, Missing: 2},
+		{Package: "couleurs/red", License: "GNU Lesser General Public License v2.1",
+			Score: 100},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCleanLicenseData(t *testing.T) {
+	data := `The MIT License (MIT)
+
+	Copyright (c) 2013 Ben Johnson
+	
+	Some other lines.
+	And more.
+	`
+	cleaned := string(cleanLicenseData([]byte(data)))
+	wanted := "the mit license (mit)\n\t\n\tsome other lines.\n\tand more.\n\t"
+	if wanted != cleaned {
+		t.Fatalf("license data mismatch: %q\n!=\n%q", cleaned, wanted)
+	}
+}
+
+func TestUnicodeWords(t *testing.T) {
+	data := "Licence accordée à ZOË Müller, sans garantie d'aucune sorte."
+	words := makeWordSet([]byte(data))
+	for _, w := range []string{"accordée", "à", "zoë", "müller", "d'aucune"} {
+		if _, ok := words[w]; !ok {
+			t.Fatalf("%q not found in %v", w, words)
+		}
+	}
+	if len(words) != 9 {
+		t.Fatalf("unexpected words: %v", words)
+	}
+}
+
+func TestFilterProblems(t *testing.T) {
+	mit := &Template{Title: "MIT License"}
+	licenses := []License{
+		{Package: "exact", Template: mit, Score: 1},
+		{Package: "approximate", Template: mit, Score: 0.95},
+		{Package: "low", Template: mit, Score: 0.5},
+		{Package: "unknown"},
+		{Package: "failed", Err: "some error"},
+		{Package: "truncated", Template: mit, Score: 1, LicenseWords: 10,
+			TemplateWords: 100},
+	}
+	setStatus(licenses, 0.9, exactScore)
+	names := []string{}
+	for _, l := range filterProblems(licenses) {
+		names = append(names, l.Package)
+	}
+	if got := strings.Join(names, " "); got != "low unknown failed truncated" {
+		t.Fatalf("unexpected problems: %s", got)
+	}
+}
+
+func TestGroupThreshold(t *testing.T) {
+	licenses := []License{
+		{Package: "github.com/a/b", Path: "github.com/LICENSE"},
+		{Package: "github.com/c/d", Path: "github.com/LICENSE"},
+		{Package: "example.com/x/y", Path: "example.com/x/LICENSE"},
+		{Package: "example.com/x/z", Path: "example.com/x/LICENSE"},
+		{Package: "missing"},
+	}
+	for threshold, expected := range map[int]string{
+		0: "github.com example.com/x missing",
+		2: "github.com/a/b github.com/c/d example.com/x missing",
+		3: "github.com/a/b github.com/c/d example.com/x/y example.com/x/z missing",
+	} {
+		grouped, err := groupLicenses(licenses, threshold, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names := []string{}
+		for _, l := range grouped {
+			names = append(names, l.Package)
+		}
+		if got := strings.Join(names, " "); got != expected {
+			t.Errorf("unexpected grouping with threshold %d: %s", threshold, got)
+		}
+	}
+}
+
+func TestGroupMembers(t *testing.T) {
+	licenses := []License{
+		{Package: "colors/cmd/mix", Version: "v1", Path: "colors/cmd/LICENSE.md"},
+		{Package: "colors/cmd/paint", Version: "v2", Path: "colors/cmd/LICENSE.md"},
+		{Package: "colors/red", Path: "colors/red/LICENSE"},
+	}
+	grouped, err := groupLicenses(licenses, 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(grouped) != 2 || grouped[0].Members != nil {
+		t.Fatalf("unexpected members without -group-members: %+v", grouped)
+	}
+	grouped, err = groupLicenses(licenses, 0, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(grouped) != 2 || grouped[1].Members != nil ||
+		!reflect.DeepEqual(grouped[0].Members, licenses[:2]) {
+		t.Fatalf("unexpected grouped members: %+v", grouped)
+	}
+	j := makeJSONLicense(grouped[0])
+	if len(j.Members) != 2 || j.Members[0].Package != "colors/cmd/mix" ||
+		j.Members[1].Version != "v2" {
+		t.Fatalf("unexpected JSON members: %+v", j.Members)
+	}
+}
+
+func TestExplainGrouping(t *testing.T) {
+	licenses := []License{
+		{Package: "colors/cmd/mix", Path: "colors/cmd/LICENSE.md", Status: StatusExact},
+		{Package: "colors/cmd/paint", Path: "colors/cmd/LICENSE.md", Status: StatusExact},
+	}
+	grouped, err := groupLicenses(licenses, 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := &bytes.Buffer{}
+	err = printTable(buf, grouped, ReportOptions{ExplainGrouping: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(),
+		"grouped by colors/cmd/LICENSE.md: colors/cmd/mix, colors/cmd/paint") {
+		t.Fatalf("unexpected grouping explanation:\n%s", buf.String())
+	}
+	_, err = groupLicenses([]License{
+		{Package: "a/x", Path: "LICENSE"},
+		{Package: "b/y", Path: "LICENSE"},
+	}, 0, false)
+	if err == nil || !strings.Contains(err.Error(), "LICENSE but not common prefix: a/x, b/y") {
+		t.Fatalf("unexpected grouping error: %v", err)
+	}
+}
+
+func TestFilterLicenses(t *testing.T) {
+	mit := &Template{Title: "MIT License", SPDX: "MIT"}
+	gpl := &Template{Title: "GNU General Public License v3.0", SPDX: "GPL-3.0"}
+	lgpl := &Template{Title: "GNU Lesser General Public License v2.1",
+		SPDX: "LGPL-2.1"}
+	licenses := []License{
+		{Package: "colors/red", Template: mit},
+		{Package: "colors/broken", Template: gpl},
+		{Package: "colors/green"},
+		{Package: "colors/teal", Template: mit, Files: []LicenseFile{
+			{MatchResult: MatchResult{Template: mit}},
+			{MatchResult: MatchResult{Template: lgpl}},
+		}},
+	}
+	kept := []string{}
+	for _, l := range filterLicenses(licenses, []string{"*GPL*", "unknown"}) {
+		kept = append(kept, l.Package)
+	}
+	if strings.Join(kept, " ") != "colors/broken colors/teal" {
+		t.Fatalf("unexpected filtered licenses: %v", kept)
+	}
+}
+
+func TestHashLineEndings(t *testing.T) {
+	gopath, err := filepath.Abs("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	licenses, err := listLicenses(&GoEnv{GOPATH: gopath},
+		[]string{"colors/maroon", "colors/red"}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(licenses) != 2 {
+		t.Fatalf("unexpected licenses: %v", licenses)
+	}
+	if licenses[0].Hash == "" || licenses[0].Hash != licenses[1].Hash {
+		t.Fatalf("CRLF and LF licenses hashes differ: %s != %s",
+			licenses[0].Hash, licenses[1].Hash)
+	}
+}
+
+func TestSecondBestMatch(t *testing.T) {
+	templates, err := loadTemplates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "src", "colors",
+		"orange", "LICENSE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := matchTemplates(data, templates, 0, "")
+	if m.Second == nil || m.Second.SPDX != "BSD-3-Clause-Clear" {
+		t.Fatalf("unexpected second best template: %v", m.Second)
+	}
+	l := License{Template: m.Template, Score: m.Score, Second: m.Second,
+		SecondScore: m.SecondScore}
+	if l.Ambiguous(0.01) || !l.Ambiguous(0.1) {
+		t.Fatalf("unexpected ambiguity: %f vs %f", l.Score, l.SecondScore)
+	}
+	wanted := `BSD 3-clause "New" or "Revised" License? / BSD 3-clause Clear License?`
+	if title := l.AmbiguousTitle(); title != wanted {
+		t.Fatalf("unexpected title: %s", title)
+	}
+}
+
+func TestStandardPackages(t *testing.T) {
+	err := compareTestLicenses([]string{"encoding/json", "cmd/addr2line"}, []testResult{})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTemplatesOrder(t *testing.T) {
+	titles := func() []string {
+		templates, err := loadTemplates()
+		if err != nil {
+			t.Fatal(err)
+		}
+		titles := []string{}
+		for _, templ := range templates {
+			titles = append(titles, templ.Title)
+		}
+		return titles
+	}
+	first := titles()
+	if !sort.StringsAreSorted(first) {
+		t.Fatalf("templates are not sorted by title: %v", first)
+	}
+	if second := titles(); strings.Join(first, "\n") != strings.Join(second, "\n") {
+		t.Fatalf("templates order differs:\n%v\n!=\n%v", first, second)
+	}
+}
+
+func TestValidateTemplate(t *testing.T) {
+	templates, err := loadTemplates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, templ := range templates {
+		if err := validateTemplate(templ); err != nil {
+			t.Fatalf("built-in template is invalid: %s", err)
+		}
+	}
+	bad := []string{
+		"---\ntitle: Empty\n---\n",
+		"---\nnickname: Untitled\n---\n" + strings.Repeat("word ", 30),
+		"no front matter at all",
+	}
+	for _, content := range bad {
+		templ, err := parseTemplate(content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := validateTemplate(templ); err == nil {
+			t.Fatalf("invalid template accepted: %q", content)
+		}
+	}
+}
+
+func TestTemplateSPDX(t *testing.T) {
+	templates, err := loadTemplates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, templ := range templates {
+		if templ.Title == "MIT License" {
+			if templ.SPDX != "MIT" {
+				t.Fatalf("unexpected MIT SPDX identifier: %q", templ.SPDX)
+			}
+			return
+		}
+	}
+	t.Fatal("MIT template not found")
+}
+
+func TestExplain(t *testing.T) {
+	gopath, err := filepath.Abs("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	licenses, err := listLicenses(&GoEnv{GOPATH: gopath}, []string{"colors/red"}, Options{
+		Explain: "isc",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(licenses) != 1 {
+		t.Fatalf("unexpected licenses: %v", licenses)
+	}
+	l := licenses[0]
+	if l.Template == nil || l.Template.Title != "MIT License" {
+		t.Fatalf("MIT License expected, got %v", l.Template)
+	}
+	e := l.Explained
+	if e == nil || e.Template.Title != "ISC License" {
+		t.Fatalf("ISC License explanation expected, got %v", e)
+	}
+	if e.Score >= l.Score || len(e.ExtraWords) == 0 || len(e.MissingWords) == 0 {
+		t.Fatalf("unexpected explanation: %+v", e)
+	}
+}
+
+func TestTemplateURL(t *testing.T) {
+	templates, err := loadTemplates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mit, err := findTemplate(templates, "MIT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mit.URL != "http://opensource.org/licenses/MIT" {
+		t.Fatalf("unexpected MIT URL: %s", mit.URL)
+	}
+	// Templates without source link to the SPDX license list.
+	tpl, err := parseTemplate("---\ntitle: Foo\nspdx-id: Foo-1.0\n---\nfoo\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tpl.URL != "https://spdx.org/licenses/Foo-1.0.html" {
+		t.Fatalf("unexpected default URL: %s", tpl.URL)
+	}
+
+	dir, err := ioutil.TempDir("", "licenses")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	report := filepath.Join(dir, "report.md")
+	licenses := []License{
+		{Package: "a", Template: mit, Status: StatusExact, Score: 1, Header: true},
+		{Package: "b", Status: StatusMissing},
+	}
+	err = generateReport(report, licenses, ReportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	link := "| [MIT License](http://opensource.org/licenses/MIT) [header] |"
+	if !strings.Contains(string(data), link) {
+		t.Fatalf("license link not found in report:\n%s", data)
+	}
+}
+
+func TestSortRows(t *testing.T) {
+	rows := func() Rows {
+		return Rows{
+			{Package: "c", License: "MIT License", Score: 1},
+			{Package: "a", License: "MIT License", Score: 0.95},
+			{Package: "b", License: "Apache License 2.0", Score: 1},
+		}
+	}
+	packages := func(rows Rows) string {
+		names := []string{}
+		for _, r := range rows {
+			names = append(names, r.Package)
+		}
+		return strings.Join(names, " ")
+	}
+	for _, test := range []struct {
+		By       string
+		Expected string
+	}{
+		{By: "license", Expected: "b a c"},
+		{By: "package", Expected: "a b c"},
+		{By: "score", Expected: "a b c"},
+		{By: "none", Expected: "c a b"},
+	} {
+		r := rows()
+		err := sortRows(r, test.By)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := packages(r); got != test.Expected {
+			t.Fatalf("%s order mismatch: %s != %s", test.By, got, test.Expected)
+		}
+	}
+	if err := sortRows(rows(), "size"); err == nil {
+		t.Fatal("unknown sort order should fail")
+	}
+}
+
+func TestSortLicenses(t *testing.T) {
+	licenses := []License{
+		{Package: "c", Score: 1},
+		{Package: "a", Score: 0.95},
+		{Package: "b", Score: 1},
+	}
+	for _, test := range []struct {
+		By       string
+		Top      int
+		Expected string
+	}{
+		{By: "score", Top: 0, Expected: "a b c"},
+		{By: "score", Top: 2, Expected: "a b"},
+		{By: "package", Top: 5, Expected: "a b c"},
+		{By: "none", Top: 1, Expected: "c"},
+		{By: "none", Top: -1, Expected: "c a b"},
+	} {
+		sorted, err := sortLicenses(licenses, test.By, test.Top)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names := []string{}
+		for _, l := range sorted {
+			names = append(names, l.Package)
+		}
+		if got := strings.Join(names, " "); got != test.Expected {
+			t.Fatalf("%s/%d mismatch: %s != %s", test.By, test.Top, got, test.Expected)
+		}
+	}
+}
+
+func TestLimitWords(t *testing.T) {
+	words := []string{"a", "b", "c", "d"}
+	for _, test := range []struct {
+		Max      int
+		Expected string
+	}{
+		{0, "a, b, c, d"},
+		{4, "a, b, c, d"},
+		{2, "a, b (+2 more)"},
+	} {
+		if got := joinWords(words, test.Max); got != test.Expected {
+			t.Fatalf("%d: %q != %q", test.Max, got, test.Expected)
+		}
+	}
+	mit := &Template{Title: "MIT License"}
+	licenses := []License{{
+		Package:    "colors/pink",
+		Template:   mit,
+		Status:     StatusApproximate,
+		Score:      0.95,
+		ExtraWords: words,
+	}}
+	buf := &bytes.Buffer{}
+	err := printTable(buf, licenses, ReportOptions{Words: true, MaxWords: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "+words: a, b, c (+1 more)\n") {
+		t.Fatalf("unexpected table:\n%s", buf.String())
+	}
+}
+
+func TestGoEnvCommand(t *testing.T) {
+	env := &GoEnv{Mod: "vendor"}
+	cmd := env.command("list", "-e", "colors/red")
+	got := strings.Join(cmd.Args[1:], " ")
+	if got != "list -mod=vendor -e colors/red" {
+		t.Fatalf("unexpected go arguments: %s", got)
+	}
+}
+
+func TestGoBinary(t *testing.T) {
+	env := &GoEnv{Go: "go1.99"}
+	if cmd := env.command("list"); cmd.Args[0] != "go1.99" {
+		t.Fatalf("unexpected go binary: %s", cmd.Args[0])
+	}
+	if err := env.checkGo(); err == nil {
+		t.Fatal("missing go binary should fail")
+	}
+	gopath, err := filepath.Abs("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	env = &GoEnv{
+		GOPATH: gopath,
+		Go:     filepath.Join(runtime.GOROOT(), "bin", "go"),
+	}
+	licenses, err := listLicenses(env, []string{"colors/red"}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(licenses) != 1 || licenses[0].Title() != "MIT License" {
+		t.Fatalf("unexpected licenses: %+v", licenses)
+	}
+}
+
+func TestExpandPath(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip(err)
+	}
+	os.Setenv("LICENSES_TEST_DIR", "reports")
+	defer os.Unsetenv("LICENSES_TEST_DIR")
+	tests := map[string]string{
+		"~":                              home,
+		"~/out.md":                       home + "/out.md",
+		"$LICENSES_TEST_DIR/out.md":      "reports/out.md",
+		"${LICENSES_TEST_DIR}/out.md":    "reports/out.md",
+		"%LICENSES_TEST_DIR%/out.md":     "reports/out.md",
+		"%LICENSES_TEST_MISSING%/out.md": "%LICENSES_TEST_MISSING%/out.md",
+		"a~/out.md":                      "a~/out.md",
+	}
+	for path, wanted := range tests {
+		if got := expandPath(path); got != wanted {
+			t.Errorf("%s: expected %s, got %s", path, wanted, got)
+		}
+	}
+}
+
+func TestReportRowsMarkers(t *testing.T) {
+	mit := &Template{Title: "MIT License", SPDX: "MIT"}
+	licenses := []License{
+		{Package: "a", Template: mit, Score: 0.95, Status: StatusApproximate,
+			Trimmed: true, Header: true},
+	}
+	table, err := reportRows(licenses, ReportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table) != 1 || table[0].License != "MIT License [trimmed] [header]" {
+		t.Fatalf("unexpected rows: %+v", table)
+	}
+	if markers := licenseMarkers(licenses[0]); markers != " [trimmed] [header]" {
+		t.Fatalf("unexpected markers: %q", markers)
+	}
 }
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+)
+
+// LockEntry is the recorded license state of a package.
+type LockEntry struct {
+	Version string `json:"version,omitempty"`
+	// License is the SPDX expression of the license, see License.Expression.
+	License string `json:"license,omitempty"`
+	// Hash is the license hash, see License.Hash.
+	Hash string `json:"hash,omitempty"`
+}
+
+// Lock maps package import paths to their recorded license state, like
+// go.sum does for module contents, so license changes are reviewed.
+type Lock map[string]LockEntry
+
+// loadLock reads a JSON lock file.
+func loadLock(path string) (Lock, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	lock := Lock{}
+	err = json.Unmarshal(data, &lock)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse lock %s: %s", path, err)
+	}
+	return lock, nil
+}
+
+// makeLock returns the lock of licenses.
+func makeLock(licenses []License) Lock {
+	lock := Lock{}
+	for i := range licenses {
+		l := &licenses[i]
+		lock[l.Package] = LockEntry{
+			Version: l.Version,
+			License: l.Expression(),
+			Hash:    l.Hash,
+		}
+	}
+	return lock
+}
+
+// writeLock writes lock as JSON at path, packages being sorted.
+func writeLock(path string, lock Lock) error {
+	data, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// LockMismatch is a package whose license disagrees with its lock entry.
+type LockMismatch struct {
+	Package string
+	Reason  string
+}
+
+// verifyLock returns the packages of licenses missing from lock, or whose
+// license expression or hash differ from it, sorted by package. Version
+// changes alone are not mismatches, the license being what matters.
+func verifyLock(licenses []License, lock Lock) []LockMismatch {
+	mismatches := []LockMismatch{}
+	for i := range licenses {
+		l := &licenses[i]
+		entry, ok := lock[l.Package]
+		reason := ""
+		switch expr := l.Expression(); {
+		case !ok:
+			reason = "new package"
+		case expr != entry.License:
+			reason = fmt.Sprintf("license changed from %q to %q", entry.License, expr)
+		case l.Hash != entry.Hash:
+			reason = "license text changed"
+			if entry.Version != l.Version {
+				reason += fmt.Sprintf(" in %s, was %s", l.Version, entry.Version)
+			}
+		default:
+			continue
+		}
+		mismatches = append(mismatches, LockMismatch{Package: l.Package, Reason: reason})
+	}
+	sort.SliceStable(mismatches, func(i, j int) bool {
+		return mismatches[i].Package < mismatches[j].Package
+	})
+	return mismatches
+}
+
+// printLockMismatches writes mismatches to w, one per line.
+func printLockMismatches(w io.Writer, mismatches []LockMismatch) error {
+	for _, m := range mismatches {
+		_, err := fmt.Fprintf(w, "lock mismatch: %s: %s\n", m.Package, m.Reason)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "licenses")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "licenses.lock")
+
+	mit := &Template{Title: "MIT License", SPDX: "MIT"}
+	gpl := &Template{Title: "GNU General Public License v3.0", SPDX: "GPL-3.0"}
+	licenses := []License{
+		{Package: "colors/red", Version: "v1.0.0", Template: mit, Hash: "aaa"},
+		{Package: "colors/blue", Version: "v1.0.0", Template: mit, Hash: "bbb"},
+		{Package: "colors/green", Template: mit, Hash: "ccc"},
+		{Package: "colors/white", Version: "v1.0.0", Template: mit, Hash: "ddd"},
+	}
+	err = writeLock(path, makeLock(licenses))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lock, err := loadLock(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lock["colors/red"] != (LockEntry{Version: "v1.0.0", License: "MIT", Hash: "aaa"}) {
+		t.Fatalf("unexpected lock entry: %+v", lock["colors/red"])
+	}
+	scanned := []License{
+		{Package: "colors/red", Version: "v1.1.0", Template: mit, Hash: "aaa"},
+		{Package: "colors/blue", Version: "v1.1.0", Template: mit, Hash: "eee"},
+		{Package: "colors/green", Template: gpl, Hash: "fff"},
+		{Package: "colors/yellow", Template: mit, Hash: "ggg"},
+	}
+	mismatches := verifyLock(scanned, lock)
+	expected := []LockMismatch{
+		{Package: "colors/blue", Reason: "license text changed in v1.1.0, was v1.0.0"},
+		{Package: "colors/green", Reason: `license changed from "MIT" to "GPL-3.0"`},
+		{Package: "colors/yellow", Reason: "new package"},
+	}
+	if !reflect.DeepEqual(mismatches, expected) {
+		t.Fatalf("unexpected mismatches: %+v", mismatches)
+	}
+	out := &bytes.Buffer{}
+	err = printLockMismatches(out, mismatches[2:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "lock mismatch: colors/yellow: new package\n" {
+		t.Fatalf("unexpected output: %q", out.String())
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// ManifestModule is a module listed in a dependency manifest, as written by
+// "go mod download -json" or "go list -m -json all".
+type ManifestModule struct {
+	Path    string
+	Version string
+	Dir     string
+	Error   interface{}
+	Replace *ManifestModule
+}
+
+// readManifest reads the stream of JSON module objects of r. Relative module
+// directories are resolved from dir.
+func readManifest(r io.Reader, dir string) ([]ManifestModule, error) {
+	modules := []ManifestModule{}
+	decoder := json.NewDecoder(r)
+	for {
+		m := ManifestModule{}
+		err := decoder.Decode(&m)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not parse manifest: %s", err)
+		}
+		if m.Path == "" {
+			return nil, fmt.Errorf("manifest module without path")
+		}
+		for _, mod := range []*ManifestModule{&m, m.Replace} {
+			if mod != nil && mod.Dir != "" && !filepath.IsAbs(mod.Dir) {
+				mod.Dir = filepath.Join(dir, mod.Dir)
+			}
+		}
+		modules = append(modules, m)
+	}
+	return modules, nil
+}
+
+// manifestError returns the error reported for a manifest module, if any.
+// "go mod download -json" reports errors as strings, "go list -m -json" as
+// objects with an Err field.
+func manifestError(e interface{}) string {
+	switch e := e.(type) {
+	case nil:
+		return ""
+	case string:
+		return e
+	case map[string]interface{}:
+		if msg, ok := e["Err"].(string); ok {
+			return msg
+		}
+	}
+	return fmt.Sprint(e)
+}
+
+// manifestVersions resolves versions of manifest modules by directory.
+type manifestVersions map[string]string
+
+func (v manifestVersions) Version(dir, importPath string) (string, error) {
+	if version, ok := v[dir]; ok {
+		return version, nil
+	}
+	return "", fmt.Errorf("no manifest version for %s", dir)
+}
+
+// manifestInfo returns the package description of manifest module m, the
+// module root standing for all its packages.
+func manifestInfo(m *ManifestModule) *PkgInfo {
+	module := &PkgModule{
+		Path:    m.Path,
+		Version: m.Version,
+		Dir:     m.Dir,
+	}
+	dir := m.Dir
+	if r := m.Replace; r != nil {
+		module.Replace = &PkgModule{
+			Path:    r.Path,
+			Version: r.Version,
+			Dir:     r.Dir,
+		}
+		dir = r.Dir
+	}
+	return &PkgInfo{
+		Name:       filepath.Base(m.Path),
+		Dir:        dir,
+		ImportPath: m.Path,
+		Module:     module,
+	}
+}
+
+// listManifestLicenses returns the licenses of the modules listed in the
+// manifest file at path, see readManifest, without invoking go. Licenses are
+// looked up in the module directories, which must have been downloaded, and
+// versions taken from the manifest.
+func listManifestLicenses(path string, opts Options) ([]License, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	modules, err := readManifest(f, filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+	return listModulesLicenses(modules, opts)
+}
+
+// listModulesLicenses returns the licenses of modules, found in their
+// directories. Modules without directory or with an error are reported as
+// failures.
+func listModulesLicenses(modules []ManifestModule, opts Options) ([]License, error) {
+	versions := manifestVersions{}
+	for _, m := range modules {
+		info := manifestInfo(&m)
+		version := m.Version
+		if r := m.Replace; r != nil && r.Version != "" {
+			version = r.Version
+		}
+		versions[info.Dir] = version
+	}
+	opts.Versions = versions
+	m, err := prepareMatcher(opts)
+	if err != nil {
+		return nil, err
+	}
+	matched := map[string][]LicenseFile{}
+	licenses := []License{}
+	for _, mod := range modules {
+		info := manifestInfo(&mod)
+		if msg := manifestError(mod.Error); msg != "" || info.Dir == "" {
+			if msg == "" {
+				msg = "module directory is unknown, it was not downloaded"
+			}
+			licenses = append(licenses, License{
+				Package:  mod.Path,
+				Version:  mod.Version,
+				Replaced: describeReplace(info.Module),
+				Err:      msg,
+			})
+			continue
+		}
+		license, err := m.license(info, matched)
+		if err != nil {
+			return nil, err
+		}
+		licenses = append(licenses, license)
+	}
+	if opts.State != nil {
+		err = opts.State.Save()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return licenses, nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadManifest(t *testing.T) {
+	manifest := `{"Path": "example.com/a", "Version": "v1.0.0", "Dir": "a"}
+{"Path": "example.com/b", "Version": "v2.0.0", "Dir": "/abs/b",
+ "Replace": {"Path": "example.com/c", "Version": "v2.1.0", "Dir": "c"}}
+{"Path": "example.com/d", "Error": {"Err": "not found"}}`
+	modules, err := readManifest(strings.NewReader(manifest), "/base")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(modules) != 3 || modules[0].Dir != "/base/a" || modules[1].Dir != "/abs/b" ||
+		modules[1].Replace.Dir != "/base/c" || manifestError(modules[2].Error) != "not found" {
+		t.Fatalf("unexpected modules: %+v", modules)
+	}
+	if _, err := readManifest(strings.NewReader(`{"Version": "v1.0.0"}`), "/base"); err == nil {
+		t.Fatal("module without path was accepted")
+	}
+}
+
+func TestManifestLicenses(t *testing.T) {
+	licenses, err := listManifestLicenses("testdata/manifest.json", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(licenses) != 2 {
+		t.Fatalf("unexpected licenses: %+v", licenses)
+	}
+	lib := licenses[0]
+	if lib.Package != "example.com/lib" || lib.Title() != "MIT License" ||
+		lib.Version != "v1.2.0" || lib.Err != "" {
+		t.Fatalf("unexpected module license: %+v", lib)
+	}
+	missing := licenses[1]
+	if missing.Package != "example.com/missing" || missing.Version != "v0.1.0" ||
+		!strings.Contains(missing.Err, "unknown revision") {
+		t.Fatalf("unexpected failed module: %+v", missing)
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"io"
+	"strings"
+)
+
+// escapeMarkdownCell escapes pipes of a markdown table cell, which would
+// otherwise end it, and folds its lines.
+func escapeMarkdownCell(s string) string {
+	s = strings.Replace(s, "|", `\|`, -1)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// reportColumn is a column of report tables, and cell returns its value for
+// a row.
+type reportColumn struct {
+	name string
+	cell func(row Row) string
+}
+
+// reportColumns returns the columns of generateReport pipe tables shown for
+// table: optional columns appear when enabled by ro or holding values.
+func reportColumns(table Rows, ro ReportOptions) []reportColumn {
+	versions, projects, notices := false, false, false
+	for _, row := range table {
+		versions = versions || row.Version != ""
+		projects = projects || row.Projects != ""
+		notices = notices || row.Notice != ""
+	}
+	all := []struct {
+		reportColumn
+		shown bool
+	}{
+		{reportColumn{"Package", func(row Row) string { return row.Package }}, true},
+		{reportColumn{"Version", func(row Row) string { return row.Version }}, versions},
+		{reportColumn{"Projects", func(row Row) string { return row.Projects }}, projects},
+		{reportColumn{"Notice", func(row Row) string { return row.Notice }}, notices},
+		{reportColumn{"Category", func(row Row) string { return row.Category }}, ro.Categories},
+		{reportColumn{"License", func(row Row) string { return row.License }}, true},
+		{reportColumn{"Match", func(row Row) string { return row.Match }}, true},
+		{reportColumn{"Words", func(row Row) string { return row.Words }}, ro.Words},
+		{reportColumn{"Obligations", func(row Row) string { return row.Obligations }},
+			ro.Obligations},
+	}
+	columns := []reportColumn{}
+	for _, c := range all {
+		if c.shown {
+			columns = append(columns, c.reportColumn)
+		}
+	}
+	return columns
+}
+
+// writeMarkdownTable writes report rows as a GitHub flavored markdown table,
+// with the columns of generateReport pipe tables but no alignment padding.
+func writeMarkdownTable(w io.Writer, table Rows, ro ReportOptions) error {
+	columns := reportColumns(table, ro)
+	header, sep := []string{}, []string{}
+	for _, c := range columns {
+		header = append(header, c.name)
+		sep = append(sep, "---")
+	}
+	lines := []string{
+		"| " + strings.Join(header, " | ") + " |",
+		"|" + strings.Join(sep, "|") + "|",
+	}
+	for _, row := range table {
+		cells := []string{}
+		for _, c := range columns {
+			cells = append(cells, escapeMarkdownCell(c.cell(row)))
+		}
+		lines = append(lines, "| "+strings.Join(cells, " | ")+" |")
+	}
+	_, err := io.WriteString(w, strings.Join(lines, "\n")+"\n")
+	return err
+}
+
+// printMarkdownTable writes licenses as a GitHub flavored markdown table to w.
+func printMarkdownTable(w io.Writer, licenses []License, ro ReportOptions) error {
+	table, err := reportRows(licenses, ro)
+	if err != nil {
+		return err
+	}
+	return writeMarkdownTable(w, table, ro)
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMarkdownTable(t *testing.T) {
+	mit := &Template{Title: "MIT License", URL: "https://spdx.org/licenses/MIT.html"}
+	licenses := []License{
+		{Package: "colors/red", Version: "v1.0.0", Template: mit, Score: 1,
+			Status: StatusExact},
+		{Package: "colors/broken", Err: "cannot read:\na | b", Status: StatusError},
+	}
+	buf := &bytes.Buffer{}
+	err := printMarkdownTable(buf, licenses, ReportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `| Package | Version | License | Match |
+|---|---|---|---|
+| colors/red | v1.0.0 | [MIT License](https://spdx.org/licenses/MIT.html) | 100% |
+| colors/broken |  | cannot read: a \| b | 0% |
+`
+	if got := buf.String(); got != expected {
+		t.Fatalf("unexpected markdown table:\n%s", got)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"os/exec"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// version is the tool version, set at build time with:
+//
+//	go build -ldflags "-X main.version=v1.2.3"
+var version = ""
+
+// toolVersion returns the tool version, or "devel" if unknown.
+func toolVersion() string {
+	if version != "" {
+		return version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" &&
+		info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return "devel"
+}
+
+// Metadata describes how a scan was produced, for reproducibility.
+type Metadata struct {
+	ToolVersion string    `json:"tool_version"`
+	Packages    []string  `json:"packages"`
+	Projects    []string  `json:"projects,omitempty"`
+	GOPATH      string    `json:"gopath,omitempty"`
+	Mode        string    `json:"mode"`
+	GoVersion   string    `json:"go_version"`
+	Timestamp   time.Time `json:"timestamp"`
+	Confidence  float64   `json:"confidence"`
+}
+
+// goOutput runs a go subcommand with env settings and returns its trimmed
+// output.
+func goOutput(env *GoEnv, args ...string) (string, error) {
+	cmd := exec.Command(env.binary(), args...)
+	cmd.Env = env.environ()
+	cmd.Dir = env.Dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", env.commandError(cmd, string(out))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// collectMetadata returns the metadata of a scan of pkgs in projects, if any.
+// Mode is "module" when go commands run in a module, "gopath" otherwise.
+func collectMetadata(env *GoEnv, pkgs, projects []string,
+	confidence float64) (*Metadata, error) {
+
+	goVersion, err := goOutput(env, "version")
+	if err != nil {
+		return nil, err
+	}
+	out, err := goOutput(env, "env", "GOPATH", "GOMOD")
+	if err != nil {
+		return nil, err
+	}
+	// Pad output so both lines exist even if the values are empty.
+	lines := strings.Split(out+"\n", "\n")
+	m := &Metadata{
+		ToolVersion: toolVersion(),
+		Packages:    pkgs,
+		Projects:    projects,
+		GOPATH:      lines[0],
+		Mode:        "gopath",
+		GoVersion:   goVersion,
+		Timestamp:   time.Now().UTC(),
+		Confidence:  confidence,
+	}
+	if gomod := lines[1]; gomod != "" && gomod != "/dev/null" && gomod != "NUL" {
+		m.Mode = "module"
+	}
+	return m, nil
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// PkgModule describes the module providing a package, as reported by go list.
+type PkgModule struct {
+	Path    string
+	Version string
+	Dir     string
+	// Main is true for the main module, the one go commands run in.
+	Main bool
+	// Replace is the module replacing this one, per go.mod replace
+	// directives, if any. Its Path is the replacement directory, as written
+	// in go.mod, when it has no Version.
+	Replace *PkgModule
+}
+
+// replacement returns the module replacing m, or nil if it is not replaced.
+func (m *PkgModule) replacement() *PkgModule {
+	if m == nil {
+		return nil
+	}
+	return m.Replace
+}
+
+// describeReplace returns a description of the replacement of module m, like
+// "../fork" or "example.com/fork v1.2.0", or an empty string if m is not
+// replaced.
+func describeReplace(m *PkgModule) string {
+	r := m.replacement()
+	if r == nil {
+		return ""
+	}
+	if r.Version != "" {
+		return r.Path + " " + r.Version
+	}
+	return r.Path
+}
+
+// isMainPackage returns true if package info belongs to the project being
+// analyzed: the main module in module mode, or one of the listed roots
+// otherwise.
+func isMainPackage(info *PkgInfo, roots map[string]bool) bool {
+	if info.Module != nil {
+		return info.Module.Main
+	}
+	return roots[info.ImportPath]
+}
+
+// isLocalImport returns true for packages outside GOPATH and modules, listed
+// with relative import paths in GOPATH mode, whose import paths look like
+// "_/abs/dir".
+func isLocalImport(info *PkgInfo) bool {
+	return strings.HasPrefix(info.ImportPath, "_/")
+}
+
+// moduleCacheDir returns the module cache directory holding dir, named like
+// module@version, and the version. It returns empty strings if dir is not in
+// a module cache.
+func moduleCacheDir(dir string) (string, string) {
+	for d := dir; ; d = filepath.Dir(d) {
+		if i := strings.LastIndex(filepath.Base(d), "@"); i >= 0 {
+			return d, filepath.Base(d)[i+1:]
+		}
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+	return "", ""
+}
+
+// moduleDir returns the root directory of the module providing package info,
+// or an empty string if the package does not belong to a module. The module
+// directory reported by go list is preferred over the module cache layout.
+func moduleDir(info *PkgInfo) string {
+	if info.Goroot || info.Dir == "" {
+		return ""
+	}
+	if m := info.Module; m != nil {
+		dir := m.Dir
+		if r := m.replacement(); r != nil && r.Dir != "" {
+			dir = r.Dir
+		}
+		if dir != "" && isBeneath(info.Dir, dir) {
+			return dir
+		}
+		if dir == "" {
+			if dir = vendoredModuleDir(info); dir != "" {
+				return dir
+			}
+		}
+	}
+	dir, _ := moduleCacheDir(info.Dir)
+	return dir
+}
+
+// vendoredModuleDir returns the directory of the vendored copy of the module
+// providing package info, like <main module>/vendor/<module path>, or an
+// empty string if the package is not vendored. go list reports no module
+// directory for vendored packages.
+func vendoredModuleDir(info *PkgInfo) string {
+	m := info.Module
+	if m == nil || m.Path == "" || !hasImportPrefix(info.ImportPath, m.Path) {
+		return ""
+	}
+	dir := info.Dir
+	sub := strings.TrimPrefix(info.ImportPath, m.Path)
+	for _, elem := range strings.Split(sub, "/") {
+		if elem != "" {
+			dir = filepath.Dir(dir)
+		}
+	}
+	if !strings.HasSuffix(filepath.ToSlash(dir), "/vendor/"+m.Path) {
+		return ""
+	}
+	return dir
+}
+
+// licenseBase returns the directory license paths of package info are
+// relative to. It is $GOPATH/src for GOPATH packages. For module packages, it
+// is the directory the module path is rooted at, like $GOMODCACHE, so license
+// paths read like "github.com/pkg/errors@v0.9.1/LICENSE", and <main>/vendor for
+// vendored modules. Modules whose root directory is not named after their
+// module path use that directory, see licensePrefix, and local imports the
+// filesystem root.
+func licenseBase(info *PkgInfo) string {
+	if isLocalImport(info) {
+		return filesystemRoot(info.Dir)
+	}
+	dir := moduleDir(info)
+	if dir == "" {
+		return filepath.Join(info.Root, "src")
+	}
+	if licensePrefix(info) != "" {
+		return dir
+	}
+	rel, err := filepath.Rel(dir, info.Dir)
+	if err != nil {
+		return filepath.Join(info.Root, "src")
+	}
+	// The module path has as many elements as the import path, minus the
+	// package directory ones below the module root. Modules replaced by
+	// other module versions are rooted at the replacement path instead.
+	n := len(strings.Split(info.ImportPath, "/"))
+	if rel != "." {
+		n -= len(strings.Split(rel, string(filepath.Separator)))
+	}
+	if r := info.Module.replacement(); r != nil && r.Version != "" {
+		n = len(strings.Split(r.Path, "/"))
+	}
+	base := dir
+	for ; n > 0 && filepath.Dir(base) != base; n-- {
+		base = filepath.Dir(base)
+	}
+	return base
+}
+
+// licensePrefix returns the module path license paths of package info are
+// displayed under, when they are relative to its module root directory rather
+// than to a directory the module path is rooted at, or an empty string. It is
+// the case of the main module, go.work members and modules replaced by local
+// directories, unless their root directory is named after the module path, so
+// license paths read like "example.com/app/LICENSE" wherever the module is
+// checked out.
+func licensePrefix(info *PkgInfo) string {
+	m := info.Module
+	if m == nil || isLocalImport(info) {
+		return ""
+	}
+	if r := m.replacement(); !m.Main && (r == nil || r.Version != "") {
+		return ""
+	}
+	dir := moduleDir(info)
+	if dir == "" || isNamedAfter(dir, m.Path) {
+		return ""
+	}
+	return m.Path
+}
+
+// isNamedAfter returns true if module root directory dir ends with the
+// elements of module path modPath, optionally followed by a module cache
+// "@version" suffix.
+func isNamedAfter(dir, modPath string) bool {
+	dir = filepath.ToSlash(dir)
+	if i := strings.LastIndex(dir, "@"); i > strings.LastIndex(dir, "/") {
+		dir = dir[:i]
+	}
+	return strings.HasSuffix(dir, "/"+modPath)
+}
+
+// filesystemRoot returns the root directory of absolute path dir.
+func filesystemRoot(dir string) string {
+	for filepath.Dir(dir) != dir {
+		dir = filepath.Dir(dir)
+	}
+	return dir
+}
@@ -0,0 +1,332 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+type failingResolver struct{}
+
+func (r failingResolver) Version(dir, importPath string) (string, error) {
+	return "", fmt.Errorf("no version for %s", dir)
+}
+
+func TestModuleCacheLicense(t *testing.T) {
+	templates, err := loadTemplates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache, err := filepath.Abs(filepath.Join("testdata", "modcache"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	modDir := filepath.Join(cache, "example.com", "lib@v1.2.0")
+	for _, module := range []*PkgModule{
+		{Path: "example.com/lib", Version: "v1.2.0", Dir: modDir},
+		// Without go list module information, the module cache layout
+		// is used.
+		nil,
+	} {
+		info := &PkgInfo{
+			Name:       "sub",
+			Dir:        filepath.Join(modDir, "sub"),
+			Root:       modDir,
+			ImportPath: "example.com/lib/sub",
+			Module:     module,
+		}
+		if base := licenseBase(info); base != cache {
+			t.Fatalf("unexpected license base: %s", base)
+		}
+		m := newMatcher(templates, nil, Options{Versions: failingResolver{}})
+		l, _, err := m.scanPackage(info, map[string][]LicenseFile{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join("example.com", "lib@v1.2.0", "LICENSE")
+		if l.Path != path || l.Title() != "MIT License" || l.Version != "v1.2.0" {
+			t.Fatalf("unexpected module license: %+v", l)
+		}
+	}
+}
+
+func TestVendoredModule(t *testing.T) {
+	templates, err := loadTemplates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	vendor, err := filepath.Abs(filepath.Join("testdata", "vendored", "vendor"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// go list reports no module directory for vendored packages.
+	info := &PkgInfo{
+		Name:       "sub",
+		Dir:        filepath.Join(vendor, "example.com", "lib", "sub"),
+		ImportPath: "example.com/lib/sub",
+		Module:     &PkgModule{Path: "example.com/lib", Version: "v1.2.0"},
+	}
+	if dir := moduleDir(info); dir != filepath.Join(vendor, "example.com", "lib") {
+		t.Fatalf("unexpected module directory: %s", dir)
+	}
+	if base := licenseBase(info); base != vendor {
+		t.Fatalf("unexpected license base: %s", base)
+	}
+	m := newMatcher(templates, nil, Options{Versions: failingResolver{}})
+	l, _, err := m.scanPackage(info, map[string][]LicenseFile{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join("example.com", "lib", "LICENSE")
+	if l.Path != path || l.Title() != "MIT License" || l.Version != "v1.2.0" {
+		t.Fatalf("unexpected vendored license: %+v", l)
+	}
+}
+
+func TestModuleDir(t *testing.T) {
+	cmd, err := filepath.Abs(filepath.Join("testdata", "src", "colors", "cmd"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := &PkgInfo{
+		Dir:        filepath.Join(cmd, "paint"),
+		ImportPath: "colors/cmd/paint",
+	}
+	for _, test := range []struct {
+		Module *PkgModule
+		Path   string
+	}{
+		{&PkgModule{Path: "colors/cmd", Dir: cmd}, filepath.Join("colors", "cmd", "LICENSE.md")},
+		// The lookup stops at the module root, before colors/cmd/LICENSE.md.
+		{&PkgModule{Path: "colors/cmd/paint", Dir: info.Dir}, ""},
+	} {
+		info.Module = test.Module
+		path, err := findLicense(info, "", 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if path != test.Path {
+			t.Fatalf("unexpected license path for %s: %q", test.Module.Path, path)
+		}
+	}
+	info.Module = nil
+	if dir := moduleDir(info); dir != "" {
+		t.Fatalf("unexpected module directory: %s", dir)
+	}
+}
+
+func TestReplacedModule(t *testing.T) {
+	templates, err := loadTemplates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	colors, err := filepath.Abs(filepath.Join("testdata", "src", "colors"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	red := filepath.Join(colors, "red")
+	modDir, err := filepath.Abs(filepath.Join("testdata", "modcache", "example.com",
+		"lib@v1.2.0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		Info     *PkgInfo
+		Path     string
+		Replaced string
+	}{
+		{
+			Info: &PkgInfo{
+				Dir:        red,
+				ImportPath: "example.com/red",
+				Module: &PkgModule{
+					Path:    "example.com/red",
+					Version: "v1.0.0",
+					Dir:     red,
+					Replace: &PkgModule{Path: "../red", Dir: red},
+				},
+			},
+			Path:     filepath.Join("example.com", "red", "LICENSE"),
+			Replaced: "../red",
+		},
+		{
+			// Replacements by other module versions are rooted at the
+			// replacement path.
+			Info: &PkgInfo{
+				Dir:        filepath.Join(modDir, "sub"),
+				ImportPath: "example.com/x/other/sub",
+				Module: &PkgModule{
+					Path:    "example.com/x/other",
+					Version: "v1.0.0",
+					Replace: &PkgModule{Path: "example.com/lib", Version: "v1.2.0",
+						Dir: modDir},
+				},
+			},
+			Path:     filepath.Join("example.com", "lib@v1.2.0", "LICENSE"),
+			Replaced: "example.com/lib v1.2.0",
+		},
+		{
+			// Relative imports outside GOPATH and modules.
+			Info: &PkgInfo{
+				Dir:        red,
+				ImportPath: "_" + filepath.ToSlash(red),
+			},
+			Path: filepath.Join(red, "LICENSE")[len(filesystemRoot(red)):],
+		},
+	} {
+		m := newMatcher(templates, nil, Options{})
+		l, _, err := m.scanPackage(test.Info, map[string][]LicenseFile{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if l.Path != test.Path || l.Title() != "MIT License" ||
+			l.Replaced != test.Replaced {
+			t.Fatalf("unexpected license for %s: %+v", test.Info.ImportPath, l)
+		}
+	}
+}
+
+// writeModule creates a module at dir, with the MIT license of the module
+// cache fixture and an empty "sub" package directory.
+func writeModule(t *testing.T, dir string) {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "modcache",
+		"example.com", "lib@v1.2.0", "LICENSE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.MkdirAll(filepath.Join(dir, "sub"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(filepath.Join(dir, "LICENSE"), data, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMainModuleLicense(t *testing.T) {
+	templates, err := loadTemplates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmp, err := ioutil.TempDir("", "licenses-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	path := filepath.Join("example.com", "app", "LICENSE")
+	for _, test := range []struct {
+		Dir  string
+		Base string
+	}{
+		// Module roots not named after the module path are the base.
+		{filepath.Join(tmp, "modproj"), filepath.Join(tmp, "modproj")},
+		{filepath.Join(tmp, "src", "example.com", "app"), filepath.Join(tmp, "src")},
+	} {
+		writeModule(t, test.Dir)
+		info := &PkgInfo{
+			Dir:        filepath.Join(test.Dir, "sub"),
+			ImportPath: "example.com/app/sub",
+			Module:     &PkgModule{Path: "example.com/app", Dir: test.Dir, Main: true},
+		}
+		if base := licenseBase(info); base != test.Base {
+			t.Fatalf("unexpected license base for %s: %s", test.Dir, base)
+		}
+		m := newMatcher(templates, nil, Options{})
+		l, _, err := m.scanPackage(info, map[string][]LicenseFile{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if l.Path != path || l.Title() != "MIT License" {
+			t.Fatalf("unexpected license for %s: %+v", test.Dir, l)
+		}
+	}
+}
+
+func TestMovedModule(t *testing.T) {
+	templates, err := loadTemplates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmp, err := ioutil.TempDir("", "licenses-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	for _, module := range []*PkgModule{
+		{Path: "example.com/app", Main: true},
+		{Path: "example.com/red", Version: "v1.0.0", Replace: &PkgModule{Path: "../red"}},
+	} {
+		// License paths, and the files recorded in states, do not depend on
+		// where the module is checked out.
+		dirs := []string{filepath.Join(tmp, "a", "modproj"), filepath.Join(tmp, "b", "other")}
+		writeModule(t, dirs[0])
+		err = os.MkdirAll(filepath.Dir(dirs[1]), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var licenses []License
+		var files [][]LicenseFile
+		for i, dir := range dirs {
+			if i > 0 {
+				err = os.Rename(dirs[i-1], dir)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			module.Dir = dir
+			if module.Replace != nil {
+				module.Replace.Dir = dir
+			}
+			info := &PkgInfo{
+				Dir:        filepath.Join(dir, "sub"),
+				ImportPath: module.Path + "/sub",
+				Module:     module,
+			}
+			m := newMatcher(templates, nil, Options{})
+			l, f, err := m.scanPackage(info, map[string][]LicenseFile{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			l.file = ""
+			licenses = append(licenses, l)
+			files = append(files, f)
+		}
+		if !reflect.DeepEqual(licenses[0], licenses[1]) ||
+			!reflect.DeepEqual(files[0], files[1]) {
+			t.Fatalf("moved %s license differs:\n%+v\n%+v", module.Path,
+				licenses[0], licenses[1])
+		}
+		path := filepath.Join(filepath.FromSlash(module.Path), "LICENSE")
+		if licenses[0].Path != path || files[0][0].Path != "LICENSE" {
+			t.Fatalf("unexpected %s license paths: %s, %s", module.Path,
+				licenses[0].Path, files[0][0].Path)
+		}
+		err = os.RemoveAll(dirs[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestIsMainPackage(t *testing.T) {
+	roots := map[string]bool{"colors/red": true}
+	for _, test := range []struct {
+		Info *PkgInfo
+		Main bool
+	}{
+		{&PkgInfo{ImportPath: "colors/red"}, true},
+		{&PkgInfo{ImportPath: "colors/blue"}, false},
+		// In module mode, roots outside the main module are dependencies.
+		{&PkgInfo{ImportPath: "colors/red", Module: &PkgModule{Path: "colors"}}, false},
+		{&PkgInfo{ImportPath: "example.com/app/sub",
+			Module: &PkgModule{Path: "example.com/app", Main: true}}, true},
+	} {
+		if isMainPackage(test.Info, roots) != test.Main {
+			t.Errorf("unexpected main package status of %s", test.Info.ImportPath)
+		}
+	}
+}
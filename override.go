@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// parseOverride parses a -override value like "example.com/pkg=MIT" into a
+// package pattern and a license name.
+func parseOverride(value string) (string, string, error) {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" ||
+		strings.TrimSpace(parts[1]) == "" {
+		return "", "", fmt.Errorf("invalid override, expected PACKAGE=LICENSE: %s", value)
+	}
+	return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]), nil
+}
+
+// Override forces the license of packages matching Pattern to Template.
+type Override struct {
+	Pattern  string
+	Template *Template
+}
+
+// resolveOverrides returns the overrides of Options.Overrides, with their
+// license names resolved to templates, see findTemplate.
+func resolveOverrides(templates []*Template, values []string) ([]Override, error) {
+	overrides := []Override{}
+	for _, value := range values {
+		pattern, name, err := parseOverride(value)
+		if err != nil {
+			return nil, err
+		}
+		t, err := findTemplate(templates, name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid override %s: %s", value, err)
+		}
+		overrides = append(overrides, Override{Pattern: pattern, Template: t})
+	}
+	return overrides, nil
+}
+
+// matchesOverride returns true if import path pkg matches override pattern,
+// which is either an import path or an import path prefix followed by
+// "/...", like go list patterns.
+func matchesOverride(pattern, pkg string) bool {
+	if prefix := strings.TrimSuffix(pattern, "/..."); prefix != pattern {
+		return hasImportPrefix(pkg, prefix)
+	}
+	return pkg == pattern
+}
+
+// findOverride returns the template forced for pkg by the first matching
+// override, or nil.
+func findOverride(overrides []Override, pkg string) *Template {
+	for _, o := range overrides {
+		if matchesOverride(o.Pattern, pkg) {
+			return o.Template
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestMatchesOverride(t *testing.T) {
+	for _, test := range []struct {
+		Pattern string
+		Pkg     string
+		Matches bool
+	}{
+		{"colors/red", "colors/red", true},
+		{"colors/red", "colors/red/sub", false},
+		{"colors/...", "colors/red", true},
+		{"colors/...", "colors", true},
+		{"colors/...", "colorsx/red", false},
+	} {
+		if matchesOverride(test.Pattern, test.Pkg) != test.Matches {
+			t.Errorf("unexpected match of %s with %s", test.Pkg, test.Pattern)
+		}
+	}
+}
+
+func TestResolveOverrides(t *testing.T) {
+	templates, err := loadTemplates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, value := range []string{"colors/red", "=MIT", "colors/red=", "colors/red=nope"} {
+		if _, err := resolveOverrides(templates, []string{value}); err == nil {
+			t.Errorf("invalid override %q was accepted", value)
+		}
+	}
+}
+
+func TestOverride(t *testing.T) {
+	// colors/green has no license file, colors/red is overridden with
+	// another license than its own.
+	opts := Options{Overrides: []string{"colors/green=MIT", "colors/red = Apache-2.0"}}
+	err := compareTestLicensesWithOptions([]string{"colors/green", "colors/red"}, opts,
+		[]testResult{
+			{Package: "colors/green", License: "MIT License", Score: 100},
+			{Package: "colors/red", License: "Apache License 2.0", Score: 100},
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"strings"
+)
+
+// Policy lists licenses allowed or denied in dependencies. Licenses are
+// identified by template title, nickname or SPDX identifier, ignoring case,
+// or glob patterns matching them like "*GPL*". Deny takes precedence over
+// Allow when a license matches both.
+type Policy struct {
+	// Allow, when not empty, lists the only licenses accepted. Unknown
+	// licenses are then rejected.
+	Allow []string `json:"allow"`
+	// Deny lists rejected licenses.
+	Deny []string `json:"deny"`
+	// Exceptions maps package import path prefixes to their approved license,
+	// which is accepted whatever Allow and Deny say, for the package and its
+	// subpackages.
+	Exceptions map[string]string `json:"exceptions"`
+	// Trusted lists import path prefixes of packages passing the policy
+	// whatever their license, like first-party code.
+	Trusted []string `json:"trusted"`
+}
+
+// loadPolicy reads a JSON policy file like:
+//
+//	{
+//	  "allow": ["MIT", "Apache-2.0"],
+//	  "deny": ["GPL-3.0"],
+//	  "exceptions": {"github.com/foo/bar": "LGPL-2.1"},
+//	  "trusted": ["github.com/mycompany"]
+//	}
+func loadPolicy(path string) (*Policy, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	p := &Policy{}
+	err = json.Unmarshal(data, p)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse policy %s: %s", path, err)
+	}
+	return p, nil
+}
+
+// matchesName returns true if name matches pattern, ignoring case. Patterns
+// are globs like "*GPL*" or "GPL-*", see path.Match.
+func matchesName(pattern, name string) bool {
+	if name == "" {
+		return false
+	}
+	if strings.EqualFold(pattern, name) {
+		return true
+	}
+	ok, err := path.Match(strings.ToLower(pattern), strings.ToLower(name))
+	return err == nil && ok
+}
+
+// matchesLicense returns true if name identifies template t. name may be a
+// glob pattern, see matchesName.
+func matchesLicense(name string, t *Template) bool {
+	return matchesName(name, t.Title) || matchesName(name, t.SPDX) ||
+		matchesName(name, t.Nickname)
+}
+
+func matchesAny(names []string, t *Template) bool {
+	for _, name := range names {
+		if matchesLicense(name, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasImportPrefix returns true if pkg is prefix or one of its subpackages.
+func hasImportPrefix(pkg, prefix string) bool {
+	prefix = strings.TrimSuffix(prefix, "/")
+	return pkg == prefix || strings.HasPrefix(pkg, prefix+"/")
+}
+
+// IsTrusted returns true if pkg has a trusted import path prefix.
+func (p *Policy) IsTrusted(pkg string) bool {
+	for _, prefix := range p.Trusted {
+		if hasImportPrefix(pkg, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// exception returns the approved license of the longest exception import path
+// prefix of pkg, if any.
+func (p *Policy) exception(pkg string) (string, bool) {
+	approved, longest, found := "", "", false
+	for prefix, license := range p.Exceptions {
+		if hasImportPrefix(pkg, prefix) && (!found || len(prefix) > len(longest)) {
+			approved, longest, found = license, prefix, true
+		}
+	}
+	return approved, found
+}
+
+// Check returns a description of the policy violation caused by l, or an
+// empty string if l complies with the policy. Licenses which are not
+// identified, per their Status, are considered unknown.
+func (p *Policy) Check(l *License) string {
+	if p.IsTrusted(l.Package) {
+		return ""
+	}
+	templates := l.Templates()
+	if approved, ok := p.exception(l.Package); ok {
+		for _, t := range templates {
+			if t == nil || !matchesLicense(approved, t) {
+				return fmt.Sprintf("license differs from approved %s", approved)
+			}
+		}
+		if len(templates) > 0 && l.identified() {
+			return ""
+		}
+		return fmt.Sprintf("license differs from approved %s", approved)
+	}
+	if !l.identified() {
+		if len(p.Allow) > 0 {
+			return "unknown license"
+		}
+		return ""
+	}
+	violation := ""
+	for _, t := range templates {
+		v := p.checkTemplate(t)
+		if v == "" && l.Alternatives {
+			// Complying with one of the alternatives is enough.
+			return ""
+		}
+		if violation == "" {
+			violation = v
+		}
+		if v != "" && !l.Alternatives {
+			break
+		}
+	}
+	return violation
+}
+
+// checkTemplate returns a description of the policy violation caused by
+// template t, which is nil for unknown licenses, or an empty string if it
+// complies.
+func (p *Policy) checkTemplate(t *Template) string {
+	if t == nil {
+		if len(p.Allow) > 0 {
+			return "unknown license"
+		}
+		return ""
+	}
+	if matchesAny(p.Deny, t) {
+		return fmt.Sprintf("%s is denied", t.Title)
+	}
+	if len(p.Allow) > 0 && !matchesAny(p.Allow, t) {
+		return fmt.Sprintf("%s is not allowed", t.Title)
+	}
+	return ""
+}
+
+// applyPolicy sets licenses Violation and Trusted fields and returns the
+// number of violations. License statuses must be set, see setStatus.
+func applyPolicy(licenses []License, p *Policy) int {
+	violations := 0
+	for i := range licenses {
+		l := &licenses[i]
+		l.Trusted = p.IsTrusted(l.Package)
+		l.Violation = p.Check(l)
+		if l.Violation != "" {
+			violations++
+		}
+	}
+	return violations
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestPolicyCheck(t *testing.T) {
+	mit := &Template{Title: "MIT License", SPDX: "MIT"}
+	gpl := &Template{Title: "GNU General Public License v3.0", SPDX: "GPL-3.0"}
+	lgpl := &Template{Title: "GNU Lesser General Public License v2.1",
+		SPDX: "LGPL-2.1"}
+	p := &Policy{
+		Allow: []string{"mit", "Apache-2.0"},
+		Deny:  []string{"GPL-3.0"},
+		Exceptions: map[string]string{
+			"colors/couleurs":      "LGPL-2.1",
+			"colors/couleurs/vert": "GPL-3.0",
+		},
+	}
+	tests := []struct {
+		License   License
+		Violation string
+	}{
+		{License{Package: "colors/red", Template: mit, Score: 1}, ""},
+		{License{Package: "colors/red", Template: mit, Score: 0.5}, "unknown license"},
+		{License{Package: "colors/green"}, "unknown license"},
+		{License{Package: "colors/broken", Template: gpl, Score: 1},
+			"GNU General Public License v3.0 is denied"},
+		{License{Package: "colors/yellow", Template: lgpl, Score: 1},
+			"GNU Lesser General Public License v2.1 is not allowed"},
+		{License{Package: "colors/couleurs", Template: lgpl, Score: 1}, ""},
+		{License{Package: "colors/couleurs", Template: gpl, Score: 1},
+			"license differs from approved LGPL-2.1"},
+		{License{Package: "colors/couleurs/rouge", Template: lgpl, Score: 1}, ""},
+		{License{Package: "colors/couleurs/vert", Template: gpl, Score: 1}, ""},
+		{License{Package: "colors/couleursx", Template: lgpl, Score: 1},
+			"GNU Lesser General Public License v2.1 is not allowed"},
+	}
+	for _, test := range tests {
+		test.License.Status = test.License.classify(0.9, exactScore)
+		got := p.Check(&test.License)
+		if got != test.Violation {
+			t.Errorf("%s: unexpected violation %q, wanted %q", test.License.Package,
+				got, test.Violation)
+		}
+	}
+}
+
+func TestPolicyTrusted(t *testing.T) {
+	mit := &Template{Title: "MIT License", SPDX: "MIT"}
+	gpl := &Template{Title: "GNU General Public License v3.0", SPDX: "GPL-3.0"}
+	p := &Policy{
+		Deny:    []string{"GPL-3.0"},
+		Trusted: []string{"colors/cmd/"},
+	}
+	licenses := []License{
+		{Package: "colors/cmd/paint", Template: gpl, Score: 1},
+		{Package: "colors/cmd"},
+		{Package: "colors/cmdline", Template: gpl, Score: 1},
+		{Package: "colors/red", Template: mit, Score: 1},
+	}
+	setStatus(licenses, 0.9, exactScore)
+	violations := applyPolicy(licenses, p)
+	if violations != 1 {
+		t.Fatalf("one violation expected, got %d", violations)
+	}
+	for i, trusted := range []bool{true, true, false, false} {
+		l := licenses[i]
+		if l.Trusted != trusted || (trusted && l.Violation != "") {
+			t.Errorf("%s: unexpected trust %v, violation %q", l.Package, l.Trusted,
+				l.Violation)
+		}
+	}
+}
+
+func TestPolicyPatterns(t *testing.T) {
+	mit := &Template{Title: "MIT License", SPDX: "MIT"}
+	gpl := &Template{Title: "GNU General Public License v3.0", SPDX: "GPL-3.0"}
+	lgpl := &Template{Title: "GNU Lesser General Public License v2.1",
+		SPDX: "LGPL-2.1"}
+	agpl := &Template{Title: "GNU Affero General Public License v3.0",
+		SPDX: "AGPL-3.0"}
+	p := &Policy{
+		Allow: []string{"*", "lgpl-*"},
+		Deny:  []string{"*gpl*"},
+	}
+	tests := []struct {
+		Template  *Template
+		Violation string
+	}{
+		{mit, ""},
+		{gpl, "GNU General Public License v3.0 is denied"},
+		{lgpl, "GNU Lesser General Public License v2.1 is denied"},
+		{agpl, "GNU Affero General Public License v3.0 is denied"},
+	}
+	for _, test := range tests {
+		l := License{Package: "colors/red", Template: test.Template, Score: 1,
+			Status: StatusExact}
+		got := p.Check(&l)
+		if got != test.Violation {
+			t.Errorf("%s: unexpected violation %q, wanted %q", test.Template.Title,
+				got, test.Violation)
+		}
+	}
+	if !matchesLicense("GPL-?.0", gpl) || matchesLicense("GPL-*", lgpl) ||
+		!matchesLicense("gnu*public*", agpl) {
+		t.Fatal("unexpected pattern matches")
+	}
+}
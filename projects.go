@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// addProject appends project to projects unless already there.
+func addProject(projects []string, project string) []string {
+	for _, p := range projects {
+		if p == project {
+			return projects
+		}
+	}
+	return append(projects, project)
+}
+
+// mergeProjectLicenses merges licenses listed for several projects into a
+// single list, sorted by package. Packages used by several projects with the
+// same version and license are reported once, with all their projects.
+// Projects pinning different versions or licenses get a row each.
+func mergeProjectLicenses(projects []string, licenses [][]License) []License {
+	index := map[string]int{}
+	merged := []License{}
+	for i, ls := range licenses {
+		for _, l := range ls {
+			key := l.Package + "\x00" + l.Version + "\x00" + l.Hash
+			if j, ok := index[key]; ok {
+				merged[j].Projects = addProject(merged[j].Projects, projects[i])
+				continue
+			}
+			l.Projects = []string{projects[i]}
+			index[key] = len(merged)
+			merged = append(merged, l)
+		}
+	}
+	sort.Stable(licensesByPackage(merged))
+	return merged
+}
+
+type licensesByPackage []License
+
+func (l licensesByPackage) Len() int {
+	return len(l)
+}
+
+func (l licensesByPackage) Less(i, j int) bool {
+	return l[i].Package < l[j].Package
+}
+
+func (l licensesByPackage) Swap(i, j int) {
+	l[i], l[j] = l[j], l[i]
+}
+
+// listProjectsLicenses runs listLicenses on pkgs in every project directory,
+// each with its own module context, and merges the results.
+func listProjectsLicenses(env *GoEnv, projects []string, pkgs []string,
+	opts Options) ([]License, error) {
+
+	licenses := [][]License{}
+	for _, project := range projects {
+		projectEnv := *env
+		projectEnv.Dir = project
+		ls, err := listLicenses(&projectEnv, pkgs, opts)
+		if err != nil {
+			return nil, fmt.Errorf("could not list %s licenses: %s", project, err)
+		}
+		licenses = append(licenses, ls)
+	}
+	return mergeProjectLicenses(projects, licenses), nil
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestMergeProjectLicenses(t *testing.T) {
+	merged := mergeProjectLicenses([]string{"api", "web", "cli"}, [][]License{
+		{{Package: "colors/red"}, {Package: "colors/blue"}, {Package: "colors/gray",
+			Version: "v1.0.0", Hash: "aaa"}},
+		{{Package: "colors/red"}, {Package: "colors/green"}, {Package: "colors/gray",
+			Version: "v2.0.0", Hash: "bbb"}},
+		{{Package: "colors/gray", Version: "v2.0.0", Hash: "bbb"}},
+	})
+	parts := []string{}
+	for _, l := range merged {
+		parts = append(parts, fmt.Sprintf("%s%s%v", l.Package, l.Version, l.Projects))
+	}
+	got := strings.Join(parts, " ")
+	wanted := "colors/blue[api] colors/grayv1.0.0[api] colors/grayv2.0.0[web cli] " +
+		"colors/green[web] colors/red[api web]"
+	if got != wanted {
+		t.Fatalf("unexpected merged licenses: %s != %s", got, wanted)
+	}
+}
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+// The types below mirror licenses.proto messages and encode to the protocol
+// buffers wire format by hand, so the tool does not depend on a protobuf
+// runtime. Field numbers must be kept in sync with licenses.proto.
+
+// ProtoStatus is the licenses.Status enum.
+type ProtoStatus int32
+
+const (
+	ProtoStatusUnspecified ProtoStatus = iota
+	ProtoStatusExact
+	ProtoStatusApproximate
+	ProtoStatusLowConfidence
+	ProtoStatusUnknown
+	ProtoStatusMissing
+	ProtoStatusError
+)
+
+var protoStatuses = map[Status]ProtoStatus{
+	StatusExact:         ProtoStatusExact,
+	StatusApproximate:   ProtoStatusApproximate,
+	StatusLowConfidence: ProtoStatusLowConfidence,
+	StatusUnknown:       ProtoStatusUnknown,
+	StatusMissing:       ProtoStatusMissing,
+	StatusError:         ProtoStatusError,
+}
+
+// ProtoLicense is the licenses.License message.
+type ProtoLicense struct {
+	Package string
+	Version string
+	SPDX    string
+	Score   float64
+	Path    string
+	Hash    string
+	Status  ProtoStatus
+	Title   string
+	Error   string
+	Members []ProtoLicense
+}
+
+// ProtoLicenseSet is the licenses.LicenseSet message.
+type ProtoLicenseSet struct {
+	Licenses []ProtoLicense
+}
+
+// Wire types of the protocol buffers encoding.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+)
+
+func appendTag(b []byte, field, wire int) []byte {
+	return binary.AppendUvarint(b, uint64(field<<3|wire))
+}
+
+// appendMessage appends an embedded message field with wire encoding data.
+func appendMessage(b []byte, field int, data []byte) []byte {
+	b = appendTag(b, field, wireBytes)
+	b = binary.AppendUvarint(b, uint64(len(data)))
+	return append(b, data...)
+}
+
+// appendString appends a string field, omitted when empty like proto3
+// default values.
+func appendString(b []byte, field int, s string) []byte {
+	if s == "" {
+		return b
+	}
+	b = appendTag(b, field, wireBytes)
+	b = binary.AppendUvarint(b, uint64(len(s)))
+	return append(b, s...)
+}
+
+// Marshal returns the wire encoding of l.
+func (l *ProtoLicense) Marshal() []byte {
+	b := []byte{}
+	b = appendString(b, 1, l.Package)
+	b = appendString(b, 2, l.Version)
+	b = appendString(b, 3, l.SPDX)
+	if l.Score != 0 {
+		b = appendTag(b, 4, wireFixed64)
+		b = binary.LittleEndian.AppendUint64(b, math.Float64bits(l.Score))
+	}
+	b = appendString(b, 5, l.Path)
+	b = appendString(b, 6, l.Hash)
+	if l.Status != 0 {
+		b = appendTag(b, 7, wireVarint)
+		b = binary.AppendUvarint(b, uint64(l.Status))
+	}
+	b = appendString(b, 8, l.Title)
+	b = appendString(b, 9, l.Error)
+	for _, m := range l.Members {
+		b = appendMessage(b, 10, m.Marshal())
+	}
+	return b
+}
+
+// Marshal returns the wire encoding of s.
+func (s *ProtoLicenseSet) Marshal() []byte {
+	b := []byte{}
+	for _, l := range s.Licenses {
+		b = appendMessage(b, 1, l.Marshal())
+	}
+	return b
+}
+
+// protoField is a decoded wire field. Value holds varints and fixed64 bits,
+// Data length delimited payloads.
+type protoField struct {
+	Number int
+	Wire   int
+	Value  uint64
+	Data   []byte
+}
+
+// readProtoFields decodes the fields of a message encoding.
+func readProtoFields(b []byte) ([]protoField, error) {
+	fields := []protoField{}
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		if n <= 0 {
+			return nil, fmt.Errorf("invalid protobuf tag")
+		}
+		b = b[n:]
+		f := protoField{Number: int(tag >> 3), Wire: int(tag & 7)}
+		switch f.Wire {
+		case wireVarint:
+			f.Value, n = binary.Uvarint(b)
+			if n <= 0 {
+				return nil, fmt.Errorf("invalid protobuf varint")
+			}
+			b = b[n:]
+		case wireFixed64:
+			if len(b) < 8 {
+				return nil, fmt.Errorf("truncated protobuf fixed64")
+			}
+			f.Value = binary.LittleEndian.Uint64(b)
+			b = b[8:]
+		case wireBytes:
+			size, n := binary.Uvarint(b)
+			if n <= 0 || uint64(len(b)-n) < size {
+				return nil, fmt.Errorf("truncated protobuf bytes")
+			}
+			f.Data = b[n : n+int(size)]
+			b = b[n+int(size):]
+		default:
+			return nil, fmt.Errorf("unsupported protobuf wire type %d", f.Wire)
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// Unmarshal decodes a licenses.License message into l. Unknown fields are
+// ignored, so newer encodings remain readable.
+func (l *ProtoLicense) Unmarshal(b []byte) error {
+	fields, err := readProtoFields(b)
+	if err != nil {
+		return err
+	}
+	*l = ProtoLicense{}
+	texts := map[int]*string{1: &l.Package, 2: &l.Version, 3: &l.SPDX,
+		5: &l.Path, 6: &l.Hash, 8: &l.Title, 9: &l.Error}
+	for _, f := range fields {
+		switch {
+		case texts[f.Number] != nil && f.Wire == wireBytes:
+			*texts[f.Number] = string(f.Data)
+		case f.Number == 4 && f.Wire == wireFixed64:
+			l.Score = math.Float64frombits(f.Value)
+		case f.Number == 7 && f.Wire == wireVarint:
+			l.Status = ProtoStatus(f.Value)
+		case f.Number == 10 && f.Wire == wireBytes:
+			m := ProtoLicense{}
+			err = m.Unmarshal(f.Data)
+			if err != nil {
+				return err
+			}
+			l.Members = append(l.Members, m)
+		}
+	}
+	return nil
+}
+
+// Unmarshal decodes a licenses.LicenseSet message into s.
+func (s *ProtoLicenseSet) Unmarshal(b []byte) error {
+	fields, err := readProtoFields(b)
+	if err != nil {
+		return err
+	}
+	s.Licenses = nil
+	for _, f := range fields {
+		if f.Number != 1 || f.Wire != wireBytes {
+			continue
+		}
+		l := ProtoLicense{}
+		err = l.Unmarshal(f.Data)
+		if err != nil {
+			return err
+		}
+		s.Licenses = append(s.Licenses, l)
+	}
+	return nil
+}
+
+// makeProtoLicense returns the licenses.License message of l, with the same
+// values as JSON output.
+func makeProtoLicense(l License) ProtoLicense {
+	p := ProtoLicense{
+		Package: l.Package,
+		Version: l.Version,
+		Score:   l.Score,
+		Path:    l.Path,
+		Hash:    l.Hash,
+		Status:  protoStatuses[l.Status],
+		Error:   l.Err,
+	}
+	if l.Template != nil {
+		p.Title = l.Title()
+		p.SPDX = l.Template.SPDX
+		if l.Alternatives {
+			p.SPDX = l.Expression()
+		}
+	}
+	for _, m := range l.Members {
+		p.Members = append(p.Members, makeProtoLicense(m))
+	}
+	return p
+}
+
+// writeProtobuf writes licenses as a serialized licenses.LicenseSet to w.
+func writeProtobuf(w io.Writer, licenses []License) error {
+	set := ProtoLicenseSet{}
+	for _, l := range licenses {
+		set.Licenses = append(set.Licenses, makeProtoLicense(l))
+	}
+	_, err := w.Write(set.Marshal())
+	return err
+}
+
+// writeProtobufReport writes licenses as a serialized licenses.LicenseSet to
+// the report file.
+func writeProtobufReport(report string, licenses []License) error {
+	out, err := os.Create(report)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	err = writeProtobuf(out, licenses)
+	if err != nil {
+		return err
+	}
+	return out.Close()
+}
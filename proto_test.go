@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"math"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+func TestProtoLicenseEncoding(t *testing.T) {
+	l := ProtoLicense{Package: "a", Score: 1, Status: ProtoStatusExact}
+	// Encoding computed by hand from licenses.proto field numbers.
+	expected := []byte{
+		0x0a, 0x01, 'a',
+		0x21, 0, 0, 0, 0, 0, 0, 0xf0, 0x3f,
+		0x38, 0x01,
+	}
+	if got := l.Marshal(); !bytes.Equal(got, expected) {
+		t.Fatalf("unexpected encoding: % x", got)
+	}
+}
+
+func TestWriteProtobuf(t *testing.T) {
+	mit := &Template{Title: "MIT License", SPDX: "MIT"}
+	licenses := []License{
+		{Package: "colors/red", Version: "v1.0.0", Template: mit, Score: 0.98,
+			Path: "colors/red/LICENSE", Hash: "abc", Status: StatusApproximate},
+		{Package: "colors/broken", Err: "cannot read", Status: StatusError},
+	}
+	buf := &bytes.Buffer{}
+	err := writeProtobuf(buf, licenses)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Unknown fields, from newer schemas, are skipped.
+	data := append(buf.Bytes(), 0x10, 0x2a)
+	set := ProtoLicenseSet{}
+	err = set.Unmarshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []ProtoLicense{
+		{Package: "colors/red", Version: "v1.0.0", SPDX: "MIT", Score: 0.98,
+			Path: "colors/red/LICENSE", Hash: "abc", Status: ProtoStatusApproximate,
+			Title: "MIT License"},
+		{Package: "colors/broken", Status: ProtoStatusError, Error: "cannot read"},
+	}
+	if !reflect.DeepEqual(set.Licenses, expected) {
+		t.Fatalf("unexpected decoded licenses: %+v", set.Licenses)
+	}
+	if err := set.Unmarshal([]byte{0x0a, 0x05, 'a'}); err == nil {
+		t.Fatal("truncated message was decoded")
+	}
+}
+
+func TestProtobufMembers(t *testing.T) {
+	mit := &Template{Title: "MIT License", SPDX: "MIT"}
+	members := []License{
+		{Package: "colors/x/a", Version: "v1.0.0", Template: mit, Score: 1,
+			Status: StatusExact},
+		{Package: "colors/x/b", Version: "v1.0.1", Template: mit, Score: 1,
+			Status: StatusExact},
+	}
+	l := License{Package: "colors/x", Template: mit, Score: 1, Status: StatusExact,
+		Members: members}
+	p := makeProtoLicense(l)
+	data := p.Marshal()
+	decoded := ProtoLicense{}
+	err := decoded.Unmarshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Members) != 2 || decoded.Members[1].Package != "colors/x/b" ||
+		decoded.Members[1].Version != "v1.0.1" || decoded.Members[0].Members != nil {
+		t.Fatalf("unexpected decoded members: %+v", decoded.Members)
+	}
+}
+
+// protoSchemaField is a licenses.proto message field or enum value.
+type protoSchemaField struct {
+	Type   string
+	Number int
+}
+
+var (
+	reProtoBlock = regexp.MustCompile(`^(message|enum) (\w+) \{`)
+	reProtoField = regexp.MustCompile(`^\s*(?:repeated )?(?:(\w+) )?(\w+) = (\d+);`)
+)
+
+// readProtoSchema returns licenses.proto message fields, by "Message.field",
+// and enum values, by name.
+func readProtoSchema(t *testing.T) map[string]protoSchemaField {
+	fp, err := os.Open("licenses.proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fp.Close()
+	schema := map[string]protoSchemaField{}
+	block := ""
+	scanner := bufio.NewScanner(fp)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if m := reProtoBlock.FindStringSubmatch(line); m != nil {
+			block = m[2]
+			continue
+		}
+		m := reProtoField.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		number, err := strconv.Atoi(m[3])
+		if err != nil {
+			t.Fatal(err)
+		}
+		name := m[2]
+		if m[1] != "" {
+			name = block + "." + name
+		}
+		schema[name] = protoSchemaField{m[1], number}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return schema
+}
+
+func TestProtoSchema(t *testing.T) {
+	// Hand written encoders must agree with licenses.proto field numbers,
+	// types and enum values. Decoding with the schema, rather than with
+	// Unmarshal, catches them drifting apart.
+	schema := readProtoSchema(t)
+	statuses := map[string]ProtoStatus{
+		"STATUS_UNSPECIFIED":    ProtoStatusUnspecified,
+		"STATUS_EXACT":          ProtoStatusExact,
+		"STATUS_APPROXIMATE":    ProtoStatusApproximate,
+		"STATUS_LOW_CONFIDENCE": ProtoStatusLowConfidence,
+		"STATUS_UNKNOWN":        ProtoStatusUnknown,
+		"STATUS_MISSING":        ProtoStatusMissing,
+		"STATUS_ERROR":          ProtoStatusError,
+	}
+	for name, status := range statuses {
+		if f, ok := schema[name]; !ok || f.Number != int(status) {
+			t.Errorf("%s is %d, not %+v", name, status, f)
+		}
+	}
+	wires := map[string]int{
+		"string":  wireBytes,
+		"double":  wireFixed64,
+		"Status":  wireVarint,
+		"License": wireBytes,
+	}
+	l := ProtoLicense{Package: "p", Version: "v", SPDX: "s", Score: 0.5,
+		Path: "pa", Hash: "h", Status: ProtoStatusError, Title: "t", Error: "e",
+		Members: []ProtoLicense{{Package: "m"}}}
+	set := ProtoLicenseSet{Licenses: []ProtoLicense{l}}
+	decode := func(data []byte, message string) map[string]protoField {
+		fields, err := readProtoFields(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		byName := map[string]protoField{}
+		for _, f := range fields {
+			found := false
+			for name, sf := range schema {
+				if len(name) > len(message) && name[:len(message)+1] == message+"." &&
+					sf.Number == f.Number {
+					if f.Wire != wires[sf.Type] {
+						t.Errorf("%s has wire type %d", name, f.Wire)
+					}
+					byName[name[len(message)+1:]] = f
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("field %d is not in %s schema", f.Number, message)
+			}
+		}
+		return byName
+	}
+	licenses := decode(set.Marshal(), "LicenseSet")["licenses"]
+	fields := decode(licenses.Data, "License")
+	texts := map[string]string{"package": "p", "version": "v", "spdx": "s",
+		"path": "pa", "hash": "h", "title": "t", "error": "e"}
+	for name, value := range texts {
+		if string(fields[name].Data) != value {
+			t.Errorf("unexpected %s: %q", name, fields[name].Data)
+		}
+	}
+	if math.Float64frombits(fields["score"].Value) != 0.5 {
+		t.Errorf("unexpected score: %x", fields["score"].Value)
+	}
+	if fields["status"].Value != uint64(ProtoStatusError) {
+		t.Errorf("unexpected status: %d", fields["status"].Value)
+	}
+	member := decode(fields["members"].Data, "License")
+	if string(member["package"].Data) != "m" {
+		t.Errorf("unexpected member: %+v", member)
+	}
+	if len(fields) != len(texts)+3 {
+		t.Errorf("unexpected fields: %+v", fields)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"text/tabwriter"
+)
+
+// TemplateRank is the score of a template against a license file.
+type TemplateRank struct {
+	Template *Template
+	Score    float64
+	// Common is the number of words shared by the license and the template.
+	Common int
+}
+
+// rankTemplates returns the scores of every template against license data,
+// ordered by decreasing score, then title.
+func rankTemplates(data []byte, templates []*Template, titleWeight float64,
+	algorithm string) []TemplateRank {
+	words := makeWordSet(data)
+	scoreWords := scoreFunc(algorithm)
+	counts := licenseCounts(data, algorithm)
+	ranks := make([]TemplateRank, 0, len(templates))
+	for _, t := range templates {
+		score, common, _, _, _ := compareTemplate(words, counts, t, titleWeight,
+			scoreWords)
+		ranks = append(ranks, TemplateRank{
+			Template: t,
+			Score:    score,
+			Common:   common,
+		})
+	}
+	sort.SliceStable(ranks, func(i, j int) bool {
+		if ranks[i].Score != ranks[j].Score {
+			return ranks[i].Score > ranks[j].Score
+		}
+		return ranks[i].Template.Title < ranks[j].Template.Title
+	})
+	return ranks
+}
+
+// rankFile reads the license file at path and ranks templates against it,
+// see rankTemplates.
+func rankFile(path string, templates []*Template, titleWeight float64,
+	algorithm string) ([]TemplateRank, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	data = decodeLicenseData(data)
+	if msg := checkLicenseData(data); msg != "" {
+		return nil, fmt.Errorf("%s: %s", path, msg)
+	}
+	return rankTemplates(data, templates, titleWeight, algorithm), nil
+}
+
+// printRanking writes ranks to w as a table. Scores have four decimals, so
+// close templates can be told apart.
+func printRanking(w io.Writer, ranks []TemplateRank) error {
+	tw := tabwriter.NewWriter(w, 1, 4, 2, ' ', 0)
+	_, err := tw.Write([]byte("RANK\tSCORE\tCOMMON\tSPDX\tTITLE\n"))
+	if err != nil {
+		return err
+	}
+	for i, r := range ranks {
+		_, err = fmt.Fprintf(tw, "%d\t%.4f\t%d\t%s\t%s\n", i+1, r.Score, r.Common,
+			r.Template.SPDX, r.Template.Title)
+		if err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+// JSONRank is the JSON representation of a TemplateRank.
+type JSONRank struct {
+	Title    string  `json:"title"`
+	SPDX     string  `json:"spdx,omitempty"`
+	Language string  `json:"language,omitempty"`
+	Score    float64 `json:"score"`
+	Common   int     `json:"common"`
+}
+
+// writeRankingJSON writes ranks as a JSON array to w, indented if pretty is
+// true.
+func writeRankingJSON(w io.Writer, ranks []TemplateRank, pretty bool) error {
+	out := make([]JSONRank, 0, len(ranks))
+	for _, r := range ranks {
+		out = append(out, JSONRank{
+			Title:    r.Template.Title,
+			SPDX:     r.Template.SPDX,
+			Language: r.Template.Language,
+			Score:    r.Score,
+			Common:   r.Common,
+		})
+	}
+	return encodeJSON(w, out, pretty)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRankTemplates(t *testing.T) {
+	templates, err := loadTemplates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join("testdata", "src", "colors", "gold", "LICENSE")
+	ranks, err := rankFile(path, templates, 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ranks) != len(templates) {
+		t.Fatalf("expected %d ranks, got %d", len(templates), len(ranks))
+	}
+	if ranks[0].Template.SPDX != "GPL-2.0" || ranks[0].Score != 1 {
+		t.Fatalf("unexpected best rank: %s %f", ranks[0].Template.Title, ranks[0].Score)
+	}
+	for i := 1; i < len(ranks); i++ {
+		if ranks[i].Score > ranks[i-1].Score {
+			t.Fatalf("ranks are not sorted: %f > %f", ranks[i].Score, ranks[i-1].Score)
+		}
+	}
+
+	w := &bytes.Buffer{}
+	if err := printRanking(w, ranks[:2]); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(w.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[1], "1     1.0000") {
+		t.Fatalf("unexpected ranking:\n%s", w.String())
+	}
+
+	w.Reset()
+	if err := writeRankingJSON(w, ranks[:1], false); err != nil {
+		t.Fatal(err)
+	}
+	out := []JSONRank{}
+	if err := json.Unmarshal(w.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 1 || out[0].SPDX != "GPL-2.0" || out[0].Score != 1 {
+		t.Fatalf("unexpected JSON ranking: %s", w.String())
+	}
+}
+
+func TestRankEmptyFile(t *testing.T) {
+	path := filepath.Join("testdata", "src", "colors", "white", "LICENSE")
+	if _, err := rankFile(path, nil, 0, ""); err == nil {
+		t.Fatal("empty license file was ranked")
+	}
+}
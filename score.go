@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// ScoreFunc computes a similarity score between 0 and 1 from the weighted
+// number of words shared by a license and a template, and the weighted sizes
+// of their word sets, or, for frequencyAlgorithms, from the dot product of
+// their term frequency vectors and their squared norms, see compareWords.
+type ScoreFunc func(common, license, template float64) float64
+
+// diceScore is the Sørensen-Dice coefficient, 2*common/(license+template).
+// It is the default, and the one confidence thresholds were tuned for.
+func diceScore(common, license, template float64) float64 {
+	return 2 * common / (license + template)
+}
+
+// jaccardScore is the Jaccard index, common/union. It is stricter than dice
+// on partial matches, a dice score of 0.9 being a jaccard one of about 0.82,
+// so more licenses fall below the confidence threshold.
+func jaccardScore(common, license, template float64) float64 {
+	return common / (license + template - common)
+}
+
+// cosineScore is the cosine similarity of the term frequency vectors,
+// dot/sqrt(license*template), see termProducts. Repeated words weigh more,
+// so it tells apart texts with the same words in different proportions. It
+// penalizes size differences less than dice, which favors short licenses
+// embedded in longer files, at the cost of blurring templates of different
+// lengths.
+func cosineScore(common, license, template float64) float64 {
+	if license*template == 0 {
+		return 0
+	}
+	return common / math.Sqrt(license*template)
+}
+
+// scoreFuncs maps -algorithm names to score functions.
+var scoreFuncs = map[string]ScoreFunc{
+	"dice":    diceScore,
+	"jaccard": jaccardScore,
+	"cosine":  cosineScore,
+}
+
+// frequencyAlgorithms lists the algorithms scoring term frequency vectors
+// rather than word sets, see compareWords.
+var frequencyAlgorithms = map[string]bool{
+	"cosine": true,
+}
+
+// licenseCounts returns the word counts of license data, see makeWordCounts,
+// if algorithm scores term frequencies, and nil otherwise.
+func licenseCounts(data []byte, algorithm string) map[string]int {
+	if !frequencyAlgorithms[algorithm] {
+		return nil
+	}
+	return makeWordCounts(data)
+}
+
+// scaleConfidence returns the algorithm score threshold equivalent to the dice
+// confidence threshold, the one thresholds were tuned for. Where dice scores
+// D, jaccard scores D/(2-D), and cosine scores D as well on word sets of the
+// same size.
+func scaleConfidence(algorithm string, confidence float64) float64 {
+	if algorithm == "jaccard" {
+		return confidence / (2 - confidence)
+	}
+	return confidence
+}
+
+// getScoreFunc returns the score function named algorithm, dice if it is
+// empty.
+func getScoreFunc(algorithm string) (ScoreFunc, error) {
+	if algorithm == "" {
+		return diceScore, nil
+	}
+	score, ok := scoreFuncs[algorithm]
+	if !ok {
+		names := []string{}
+		for name := range scoreFuncs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown algorithm %q, expected one of %s",
+			algorithm, strings.Join(names, ", "))
+	}
+	return score, nil
+}
+
+// scoreFunc is getScoreFunc for validated algorithm names. Unknown ones fall
+// back to dice.
+func scoreFunc(algorithm string) ScoreFunc {
+	score, err := getScoreFunc(algorithm)
+	if err != nil {
+		return diceScore
+	}
+	return score
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+func TestScoreFuncs(t *testing.T) {
+	tests := []struct {
+		Algorithm string
+		Score     float64
+	}{
+		{"", 0.8},
+		{"dice", 0.8},
+		{"jaccard", 2.0 / 3},
+		{"cosine", 8 / math.Sqrt(96)},
+	}
+	for _, test := range tests {
+		score, err := getScoreFunc(test.Algorithm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// 8 common words out of 8 and 12.
+		if s := score(8, 8, 12); math.Abs(s-test.Score) > 1e-9 {
+			t.Errorf("%q: expected %f, got %f", test.Algorithm, test.Score, s)
+		}
+		if s := score(10, 10, 10); s != 1 {
+			t.Errorf("%q: identical sets score %f", test.Algorithm, s)
+		}
+		// Empty texts share nothing.
+		if s := score(0, 0, 10); s != 0 {
+			t.Errorf("%q: empty license scores %f", test.Algorithm, s)
+		}
+	}
+	if _, err := getScoreFunc("levenshtein"); err == nil {
+		t.Fatal("unknown algorithm was accepted")
+	}
+}
+
+func TestScoreAlgorithms(t *testing.T) {
+	templates, err := loadTemplates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Jaccard never exceeds dice and cosine is never below it, for the same
+	// word counts, but exact and clearly identified licenses keep their
+	// template.
+	for _, pkg := range []string{"red", "gold", "plum", "orange", "amber"} {
+		data, err := ioutil.ReadFile(filepath.Join("testdata", "src", "colors",
+			pkg, "LICENSE"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		dice := matchTemplates(data, templates, 0, "dice")
+		jaccard := matchTemplates(data, templates, 0, "jaccard")
+		cosine := matchTemplates(data, templates, 0, "cosine")
+		if jaccard.Template != dice.Template || cosine.Template != dice.Template {
+			t.Errorf("%s: templates differ: dice %s, jaccard %s, cosine %s", pkg,
+				dice.Template.SPDX, jaccard.Template.SPDX, cosine.Template.SPDX)
+			continue
+		}
+		if jaccard.Score > dice.Score || cosine.Score < dice.Score {
+			t.Errorf("%s: unexpected scores: jaccard %f, dice %f, cosine %f", pkg,
+				jaccard.Score, dice.Score, cosine.Score)
+		}
+		if dice.Score == 1 && (jaccard.Score != 1 || cosine.Score != 1) {
+			t.Errorf("%s: exact match scores jaccard %f, cosine %f", pkg,
+				jaccard.Score, cosine.Score)
+		}
+	}
+}
+
+func TestAlgorithmOption(t *testing.T) {
+	err := compareTestLicensesWithOptions([]string{"colors/red"},
+		Options{Algorithm: "jaccard"}, []testResult{
+			{Package: "colors/red", License: "MIT License", Score: 97, Missing: 2},
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCosineFrequencies(t *testing.T) {
+	tpl := &Template{Title: "T", text: []byte("free free free software license")}
+	same := []byte("free software license")
+	repeated := []byte("free free free software license")
+	// Word sets are identical, term frequencies are not.
+	set, _, _, _ := compareWords(makeWordSet(same), nil, tpl, 0, cosineScore)
+	if set != 1 {
+		t.Fatalf("unexpected word set score: %f", set)
+	}
+	s, _, _, _ := compareWords(makeWordSet(same), makeWordCounts(same), tpl, 0,
+		cosineScore)
+	if expected := 5 / math.Sqrt(3*11); math.Abs(s-expected) > 1e-9 {
+		t.Fatalf("expected %f, got %f", expected, s)
+	}
+	s, _, _, _ = compareWords(makeWordSet(repeated), makeWordCounts(repeated), tpl, 0,
+		cosineScore)
+	if math.Abs(s-1) > 1e-9 {
+		t.Fatalf("identical texts score %f", s)
+	}
+}
+
+func TestScaleConfidence(t *testing.T) {
+	if c := scaleConfidence("dice", 0.9); c != 0.9 {
+		t.Fatalf("unexpected dice confidence: %f", c)
+	}
+	if c := scaleConfidence("cosine", 0.9); c != 0.9 {
+		t.Fatalf("unexpected cosine confidence: %f", c)
+	}
+	// A dice score of 0.9 is a jaccard one of 0.9/1.1 for the same words.
+	if c := scaleConfidence("jaccard", 0.9); math.Abs(c-jaccardScore(9, 10, 10)) > 1e-9 {
+		t.Fatalf("unexpected jaccard confidence: %f", c)
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Titles of the report sections of licenses without reliable template.
+const (
+	unknownSection = "Unknown licenses"
+	missingSection = "Missing licenses"
+)
+
+// LicenseSection lists the packages sharing a license.
+type LicenseSection struct {
+	Title string
+	// URL is the canonical license address, if known.
+	URL      string
+	Licenses []License
+}
+
+// sectionTitle returns the title of the section listing l.
+func sectionTitle(l *License) string {
+	switch l.Status {
+	case StatusExact, StatusApproximate:
+		return l.Title()
+	case StatusMissing:
+		return missingSection
+	}
+	return unknownSection
+}
+
+// groupByLicense returns licenses grouped in sections by license title,
+// ordered by title, followed by the unknown and missing licenses sections.
+// Packages are ordered by import path in every section.
+func groupByLicense(licenses []License) []LicenseSection {
+	byTitle := map[string][]License{}
+	titles := []string{}
+	for _, l := range licenses {
+		title := sectionTitle(&l)
+		if _, ok := byTitle[title]; !ok && title != unknownSection &&
+			title != missingSection {
+			titles = append(titles, title)
+		}
+		byTitle[title] = append(byTitle[title], l)
+	}
+	sort.Strings(titles)
+	titles = append(titles, unknownSection, missingSection)
+	sections := []LicenseSection{}
+	for _, title := range titles {
+		section := byTitle[title]
+		if len(section) == 0 {
+			continue
+		}
+		sort.SliceStable(section, func(i, j int) bool {
+			return section[i].Package < section[j].Package
+		})
+		url := ""
+		if title != unknownSection && title != missingSection {
+			url = section[0].URL()
+		}
+		sections = append(sections, LicenseSection{
+			Title:    title,
+			URL:      url,
+			Licenses: section,
+		})
+	}
+	return sections
+}
+
+// writeLicenseSections writes licenses to w as markdown, with a heading per
+// license followed by its packages.
+func writeLicenseSections(w io.Writer, licenses []License) error {
+	for i, section := range groupByLicense(licenses) {
+		if i > 0 {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+		title := section.Title
+		if section.URL != "" {
+			title = markdownLink(title, section.URL)
+		}
+		_, err := fmt.Fprintf(w, "## %s\n\n", title)
+		if err != nil {
+			return err
+		}
+		for _, l := range section.Licenses {
+			line := "- " + l.packages()
+			if l.Version != "" {
+				line += " " + l.Version
+			}
+			switch l.Status {
+			case StatusApproximate:
+				line += fmt.Sprintf(" (%d%%)", int(100*l.Score+.5))
+			case StatusLowConfidence:
+				line += fmt.Sprintf(" (%s?, %d%%)", l.Title(), int(100*l.Score+.5))
+			case StatusError:
+				line += " (" + strings.Replace(l.Err, "\n", " ", -1) + ")"
+			}
+			if _, err := io.WriteString(w, line+"\n"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestLicenseSections(t *testing.T) {
+	mit := &Template{Title: "MIT License"}
+	apache := &Template{Title: "Apache License 2.0"}
+	licenses := []License{
+		{Package: "d", Status: StatusMissing},
+		{Package: "c", Template: mit, Status: StatusExact, Score: 1, Version: "v1.0.0"},
+		{Package: "b", Template: apache, Status: StatusApproximate, Score: 0.95},
+		{Package: "a", Template: mit, Status: StatusExact, Score: 1},
+		{Package: "e", Template: mit, Status: StatusLowConfidence, Score: 0.5},
+		{Package: "f", Path: "LICENSE", Status: StatusUnknown},
+		{Package: "g", Path: "LICENSE", Status: StatusError, Err: "binary\nlicense"},
+	}
+	buf := &bytes.Buffer{}
+	err := writeLicenseSections(buf, licenses)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `## Apache License 2.0
+
+- b (95%)
+
+## MIT License
+
+- a
+- c v1.0.0
+
+## Unknown licenses
+
+- e (MIT License?, 50%)
+- f
+- g (binary license)
+
+## Missing licenses
+
+- d
+`
+	if buf.String() != expected {
+		t.Fatalf("unexpected sections:\n%s", buf.String())
+	}
+}
+
+func TestPrintLicenseSections(t *testing.T) {
+	mit := &Template{Title: "MIT License"}
+	licenses := []License{
+		{Package: "b", Template: mit, Status: StatusExact, Score: 1},
+		{Package: "a", Status: StatusMissing},
+	}
+	buf := &bytes.Buffer{}
+	err := printLicenseSections(buf, licenses, ReportOptions{
+		ByLicense: true,
+		SortBy:    "package",
+		Top:       1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "## Missing licenses\n\n- a\n"; buf.String() != expected {
+		t.Fatalf("unexpected sections:\n%s", buf.String())
+	}
+}
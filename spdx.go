@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// spdxTemplateExt is the file name extension of SPDX license templates, like
+// "MIT.template.txt" in the template directory of the SPDX license list data.
+const spdxTemplateExt = ".template.txt"
+
+// spdxTag returns the length of the SPDX template markup tag starting data,
+// like `<<var;name="copyright";original="...";match=".+">>`, including its
+// delimiters, or -1 if it is not terminated. Quoted attribute values may hold
+// ">>".
+func spdxTag(data []byte) int {
+	quoted := false
+	for i := 2; i < len(data); i++ {
+		switch {
+		case data[i] == '"':
+			quoted = !quoted
+		case !quoted && data[i] == '>' && i+1 < len(data) && data[i+1] == '>':
+			return i + 2
+		}
+	}
+	return -1
+}
+
+// spdxAttr returns the value of attribute name of SPDX template tag, like
+// original in `<<var;name="x";original="text">>`, or an empty string.
+func spdxAttr(tag, name string) string {
+	prefix := name + "=\""
+	i := strings.Index(tag, prefix)
+	if i < 0 {
+		return ""
+	}
+	value := tag[i+len(prefix):]
+	if j := strings.Index(value, "\""); j >= 0 {
+		value = value[:j]
+	}
+	return value
+}
+
+// parseSPDXTemplate splits the text of an SPDX license template into its
+// required and optional parts. Text between <<beginOptional>> and
+// <<endOptional>> tags, possibly nested, and the original text of <<var>>
+// replaceable fields, like copyright lines, are optional.
+func parseSPDXTemplate(data []byte) ([]byte, []byte, error) {
+	required, optional := []byte{}, []byte{}
+	depth := 0
+	for len(data) > 0 {
+		i := bytes.Index(data, []byte("<<"))
+		if i < 0 {
+			i = len(data)
+		}
+		if depth > 0 {
+			optional = append(optional, data[:i]...)
+		} else {
+			required = append(required, data[:i]...)
+		}
+		data = data[i:]
+		if len(data) == 0 {
+			break
+		}
+		n := spdxTag(data)
+		if n < 0 {
+			return nil, nil, fmt.Errorf("unterminated tag: %.40q", data)
+		}
+		tag := string(data[2 : n-2])
+		data = data[n:]
+		switch name := strings.TrimSpace(strings.SplitN(tag, ";", 2)[0]); name {
+		case "beginOptional":
+			depth++
+		case "endOptional":
+			if depth == 0 {
+				return nil, nil, fmt.Errorf("unbalanced endOptional tag")
+			}
+			depth--
+		case "var":
+			optional = append(optional, ' ')
+			optional = append(optional, spdxAttr(tag, "original")...)
+			optional = append(optional, ' ')
+		default:
+			return nil, nil, fmt.Errorf("unknown tag: %s", name)
+		}
+		// Tags separate words.
+		required = append(required, ' ')
+	}
+	if depth != 0 {
+		return nil, nil, fmt.Errorf("unbalanced beginOptional tag")
+	}
+	return required, optional, nil
+}
+
+// newSPDXTemplate returns the template of SPDX license id, with the text of
+// an SPDX license template, whose optional words are ignored when matching,
+// see Template.Optional.
+func newSPDXTemplate(id string, data []byte) (*Template, error) {
+	required, optional, err := parseSPDXTemplate(data)
+	if err != nil {
+		return nil, err
+	}
+	t := &Template{
+		Title:        id,
+		SPDX:         id,
+		URL:          spdxURL + id + ".html",
+		text:         required,
+		optionalText: optional,
+	}
+	for _, line := range strings.Split(string(required), "\n") {
+		if n := len(reWords.FindAll(cleanLicenseData([]byte(line)), -1)); n > 0 {
+			t.TitleLen = n
+			break
+		}
+	}
+	return t, nil
+}
+
+// loadSPDXTemplates reads the SPDX license templates of dir, see
+// spdxTemplateExt. Templates of licenses already in templates become their
+// official variant, matched along them, see compareTemplate, so they are
+// reported under the same title. The others are returned appended to
+// templates, titled with their SPDX identifier.
+func loadSPDXTemplates(dir string, templates []*Template) ([]*Template, error) {
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	added := false
+	for _, fi := range fis {
+		name := fi.Name()
+		if !fi.Mode().IsRegular() || !strings.HasSuffix(name, spdxTemplateExt) {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		id := strings.TrimSuffix(name, spdxTemplateExt)
+		official, err := newSPDXTemplate(id, decodeLicenseData(data))
+		if err != nil {
+			return nil, fmt.Errorf("invalid SPDX template %s: %s", name, err)
+		}
+		known := false
+		for _, t := range templates {
+			if t.Language == "" && strings.EqualFold(t.SPDX, id) {
+				official.Title, official.SPDX, official.URL = t.Title, t.SPDX, t.URL
+				t.official = official
+				known = true
+				break
+			}
+		}
+		if !known {
+			templates = append(templates, official)
+			added = true
+		}
+	}
+	if added {
+		sort.Stable(templatesByTitle(templates))
+	}
+	return templates, nil
+}
+
+// compareTemplate compares license words with t like compareWords, and with
+// its official SPDX variant, if any, returning the best result and the
+// template it was computed with.
+func compareTemplate(words, counts map[string]int, t *Template, titleWeight float64,
+	score ScoreFunc) (float64, int, []Word, []Word, *Template) {
+
+	s, common, extra, missing := compareWords(words, counts, t, titleWeight, score)
+	if t.official == nil {
+		return s, common, extra, missing, t
+	}
+	s2, common2, extra2, missing2 := compareWords(words, counts, t.official, titleWeight,
+		score)
+	if s2 > s {
+		return s2, common2, extra2, missing2, t.official
+	}
+	return s, common, extra, missing, t
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseSPDXTemplate(t *testing.T) {
+	tests := []struct {
+		Template string
+		Required string
+		Optional string
+		Err      bool
+	}{
+		{Template: "plain text", Required: "plain text"},
+		{
+			Template: "<<beginOptional>>Title<<endOptional>>\nbody",
+			Required: "  \nbody",
+			Optional: "Title",
+		},
+		{
+			Template: `a <<var;name="x";original="Copyright <year>";match=".+">> b`,
+			Required: "a   b",
+			Optional: " Copyright <year> ",
+		},
+		{
+			Template: "<<beginOptional>>x<<beginOptional>>y<<endOptional>>z<<endOptional>>",
+			Required: "    ",
+			Optional: "xyz",
+		},
+		{
+			Template: `<<var;name="x";original="a >> b";match=".+">>`,
+			Required: " ",
+			Optional: " a >> b ",
+		},
+		{Template: "a <<beginOptional>> b", Err: true},
+		{Template: "a <<endOptional>>", Err: true},
+		{Template: "a <<var;name=\"x\"", Err: true},
+		{Template: "a <<unknown>>", Err: true},
+	}
+	for _, test := range tests {
+		required, optional, err := parseSPDXTemplate([]byte(test.Template))
+		if test.Err {
+			if err == nil {
+				t.Errorf("%q: error expected", test.Template)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %s", test.Template, err)
+			continue
+		}
+		if string(required) != test.Required || string(optional) != test.Optional {
+			t.Errorf("%q: expected %q and %q, got %q and %q", test.Template,
+				test.Required, test.Optional, required, optional)
+		}
+	}
+}
+
+func TestOptionalWords(t *testing.T) {
+	official, err := newSPDXTemplate("X", []byte(
+		"the licensed work <<beginOptional>>the extra words<<endOptional>>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(official.Optional()) != 2 {
+		t.Fatalf("unexpected optional words: %v", official.Optional())
+	}
+	for _, text := range []string{"the licensed work", "the licensed work, extra words"} {
+		score, _, extra, missing := compareWords(makeWordSet([]byte(text)), nil, official, 0,
+			diceScore)
+		if score != 1 || len(extra) != 0 || len(missing) != 0 {
+			t.Errorf("%q: unexpected match: %f +%v -%v", text, score, extra, missing)
+		}
+	}
+}
+
+func TestLoadSPDXTemplates(t *testing.T) {
+	dir, err := ioutil.TempDir("", "licenses-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "spdx", "MIT.template.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"MIT.template.txt":        string(data),
+		"Custom-1.0.template.txt": strings.Repeat("custom license words ", 5),
+		"README.md":               "not a template",
+	}
+	for name, content := range files {
+		err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	templates, err := loadTemplates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	count := len(templates)
+	templates, err = loadSPDXTemplates(dir, templates)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(templates) != count+1 {
+		t.Fatalf("expected %d templates, got %d", count+1, len(templates))
+	}
+	mit, err := findTemplate(templates, "MIT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mit.official == nil || mit.official.Title != mit.Title {
+		t.Fatalf("MIT official template expected, got %+v", mit.official)
+	}
+	custom, err := findTemplate(templates, "Custom-1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if custom.Title != "Custom-1.0" || custom.URL != spdxURL+"Custom-1.0.html" {
+		t.Fatalf("unexpected custom template: %+v", custom)
+	}
+
+	err = ioutil.WriteFile(filepath.Join(dir, "Broken.template.txt"),
+		[]byte("<<beginOptional>>"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadSPDXTemplates(dir, templates); err == nil {
+		t.Fatal("invalid SPDX template was loaded")
+	}
+}
+
+func TestSPDXTemplatesOption(t *testing.T) {
+	// The choosealicense MIT text title is optional in the SPDX template.
+	err := compareTestLicensesWithOptions([]string{"colors/red"},
+		Options{SPDXTemplates: filepath.Join("testdata", "spdx")}, []testResult{
+			{Package: "colors/red", License: "MIT License", Score: 100},
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// stateSaveInterval is the number of newly matched packages after which the
+// state file is written again.
+const stateSaveInterval = 50
+
+// StateMatch is the persisted form of a MatchResult, templates being
+// identified by title.
+type StateMatch struct {
+	Template      string   `json:"template,omitempty"`
+	Score         float64  `json:"score"`
+	ExtraWords    []string `json:"extra_words,omitempty"`
+	MissingWords  []string `json:"missing_words,omitempty"`
+	Trimmed       bool     `json:"trimmed,omitempty"`
+	Preamble      bool     `json:"preamble,omitempty"`
+	Common        int      `json:"common"`
+	LicenseWords  int      `json:"license_words"`
+	TemplateWords int      `json:"template_words"`
+	Second        string   `json:"second,omitempty"`
+	SecondScore   float64  `json:"second_score,omitempty"`
+	Diff          []string `json:"diff,omitempty"`
+}
+
+// StateFile is the persisted form of a LicenseFile.
+type StateFile struct {
+	Path      string      `json:"path"`
+	Hash      string      `json:"hash"`
+	Err       string      `json:"error,omitempty"`
+	Match     StateMatch  `json:"match"`
+	Explained *StateMatch `json:"explained,omitempty"`
+}
+
+// StateEntry holds the license files matched for a package. It is reused
+// only if the package version, license hash and matching options are
+// unchanged.
+type StateEntry struct {
+	Version string      `json:"version,omitempty"`
+	Hash    string      `json:"hash"`
+	Options string      `json:"options"`
+	Files   []StateFile `json:"files"`
+}
+
+// State persists matched licenses by package import path, so interrupted or
+// repeated scans do not match unchanged packages again.
+type State struct {
+	path    string
+	Entries map[string]StateEntry `json:"entries"`
+	pending int
+}
+
+// loadState reads the state file at path. A missing file yields an empty
+// state, saved at path.
+func loadState(path string) (*State, error) {
+	s := &State{
+		path:    path,
+		Entries: map[string]StateEntry{},
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, err
+	}
+	err = json.Unmarshal(data, s)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse state %s: %s", path, err)
+	}
+	if s.Entries == nil {
+		s.Entries = map[string]StateEntry{}
+	}
+	return s, nil
+}
+
+// Save writes the state file, replacing it atomically.
+func (s *State) Save() error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	err = ioutil.WriteFile(tmp, data, 0644)
+	if err != nil {
+		return err
+	}
+	err = os.Rename(tmp, s.path)
+	if err != nil {
+		return err
+	}
+	s.pending = 0
+	return nil
+}
+
+// optionsKey identifies the options affecting match results.
+func optionsKey(opts Options) string {
+	key := fmt.Sprintf("explain=%s trim=%t title=%g", opts.Explain,
+		opts.TrimTrailing, opts.TitleWeight)
+	if opts.Diff {
+		// Appended only when set, so existing states remain valid.
+		key += " diff=true"
+	}
+	if opts.NormalizeYears {
+		key += " years=true"
+	}
+	if opts.SPDXTemplates != "" {
+		key += " spdx=" + opts.SPDXTemplates
+	}
+	if opts.Algorithm != "" && opts.Algorithm != "dice" {
+		key += " algorithm=" + opts.Algorithm
+	}
+	return key
+}
+
+// hashPaths returns the hash hashFiles would return for the license files or
+// directories at paths, relative to root, without matching them.
+func hashPaths(root string, paths []string) (string, error) {
+	names := []string{}
+	for _, path := range paths {
+		fpath := filepath.Join(root, path)
+		fi, err := os.Stat(fpath)
+		if err != nil {
+			return "", err
+		}
+		if !fi.IsDir() {
+			names = append(names, fpath)
+			continue
+		}
+		fis, err := ioutil.ReadDir(fpath)
+		if err != nil {
+			return "", err
+		}
+		for _, fi := range fis {
+			if fi.Mode().IsRegular() {
+				names = append(names, filepath.Join(fpath, fi.Name()))
+			}
+		}
+	}
+	hashes := []string{}
+	for _, name := range names {
+		data, err := ioutil.ReadFile(name)
+		if err != nil {
+			return "", err
+		}
+		hashes = append(hashes, hashLicense(data))
+	}
+	return combineHashes(hashes), nil
+}
+
+func toStateMatch(r *MatchResult) StateMatch {
+	m := StateMatch{
+		Score:         r.Score,
+		ExtraWords:    r.ExtraWords,
+		MissingWords:  r.MissingWords,
+		Trimmed:       r.Trimmed,
+		Preamble:      r.Preamble,
+		Common:        r.Common,
+		LicenseWords:  r.LicenseWords,
+		TemplateWords: r.TemplateWords,
+		SecondScore:   r.SecondScore,
+		Diff:          r.Diff,
+	}
+	if r.Template != nil {
+		m.Template = r.Template.Title
+	}
+	if r.Second != nil {
+		m.Second = r.Second.Title
+	}
+	return m
+}
+
+// fromStateMatch converts m back to a MatchResult. It returns false if a
+// template no longer exists.
+func fromStateMatch(m StateMatch, templates map[string]*Template) (MatchResult, bool) {
+	r := MatchResult{
+		Score:         m.Score,
+		ExtraWords:    m.ExtraWords,
+		MissingWords:  m.MissingWords,
+		Trimmed:       m.Trimmed,
+		Preamble:      m.Preamble,
+		Common:        m.Common,
+		LicenseWords:  m.LicenseWords,
+		TemplateWords: m.TemplateWords,
+		SecondScore:   m.SecondScore,
+		Diff:          m.Diff,
+	}
+	if m.Template != "" {
+		r.Template = templates[m.Template]
+		if r.Template == nil {
+			return r, false
+		}
+	}
+	if m.Second != "" {
+		r.Second = templates[m.Second]
+		if r.Second == nil {
+			return r, false
+		}
+	}
+	return r, true
+}
+
+// Lookup returns the license files recorded for pkg if its version, license
+// hash and options did not change.
+func (s *State) Lookup(pkg, version, hash string, opts Options,
+	templates []*Template) ([]LicenseFile, bool) {
+
+	e, ok := s.Entries[pkg]
+	if !ok || e.Version != version || e.Hash != hash || e.Options != optionsKey(opts) {
+		return nil, false
+	}
+	byTitle := map[string]*Template{}
+	for _, t := range templates {
+		byTitle[t.Title] = t
+	}
+	files := []LicenseFile{}
+	for _, sf := range e.Files {
+		r, ok := fromStateMatch(sf.Match, byTitle)
+		if !ok {
+			return nil, false
+		}
+		f := LicenseFile{
+			Path:        sf.Path,
+			Hash:        sf.Hash,
+			Err:         sf.Err,
+			MatchResult: r,
+		}
+		if sf.Explained != nil {
+			e, ok := fromStateMatch(*sf.Explained, byTitle)
+			if !ok {
+				return nil, false
+			}
+			f.Explained = &e
+		}
+		files = append(files, f)
+	}
+	return files, true
+}
+
+// Record stores the license files matched for pkg, and saves the state
+// every stateSaveInterval new records.
+func (s *State) Record(pkg, version, hash string, opts Options,
+	files []LicenseFile) error {
+
+	e := StateEntry{
+		Version: version,
+		Hash:    hash,
+		Options: optionsKey(opts),
+	}
+	if old, ok := s.Entries[pkg]; ok && old.Version == e.Version &&
+		old.Hash == e.Hash && old.Options == e.Options {
+		return nil
+	}
+	for _, f := range files {
+		sf := StateFile{
+			Path:  f.Path,
+			Hash:  f.Hash,
+			Err:   f.Err,
+			Match: toStateMatch(&f.MatchResult),
+		}
+		if f.Explained != nil {
+			m := toStateMatch(f.Explained)
+			sf.Explained = &m
+		}
+		e.Files = append(e.Files, sf)
+	}
+	s.Entries[pkg] = e
+	s.pending++
+	if s.pending >= stateSaveInterval {
+		return s.Save()
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestState(t *testing.T) {
+	gopath, err := filepath.Abs("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "licenses")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "state.json")
+
+	scan := func() []License {
+		state, err := loadState(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		licenses, err := listLicenses(&GoEnv{GOPATH: gopath}, []string{"colors/red"},
+			Options{State: state})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(licenses) != 1 {
+			t.Fatalf("one license expected, got %d", len(licenses))
+		}
+		return licenses
+	}
+	first := scan()
+	state, err := loadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, ok := state.Entries["colors/red"]
+	if !ok || e.Hash != first[0].Hash || len(e.Files) != 1 ||
+		e.Files[0].Match.Template != "MIT License" {
+		t.Fatalf("unexpected state entry: %+v", e)
+	}
+
+	// Unchanged packages are not matched again, tamper with the state to
+	// detect it.
+	e.Files[0].Match.Score = 0.5
+	state.Entries["colors/red"] = e
+	err = state.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l := scan()[0]; l.Score != 0.5 || l.Template == nil ||
+		l.Template.Title != "MIT License" {
+		t.Fatalf("state entry not reused: %+v", l)
+	}
+
+	// Changed licenses are matched again.
+	e.Hash = "changed"
+	state.Entries["colors/red"] = e
+	err = state.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l := scan()[0]; l.Score != first[0].Score {
+		t.Fatalf("stale state entry reused: %+v", l)
+	}
+}
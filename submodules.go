@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// errNotCheckedOut reports submodules whose working tree is missing or empty.
+const errNotCheckedOut = "submodule is not checked out"
+
+// Submodule is a git submodule declared in a .gitmodules file.
+type Submodule struct {
+	Name string
+	// Path is the slash separated submodule directory, relative to the
+	// repository top directory.
+	Path string
+	URL  string
+}
+
+// parseGitmodules parses the submodule sections of a .gitmodules file, in
+// git config syntax, like:
+//
+//	[submodule "libfoo"]
+//		path = third_party/libfoo
+//		url = https://example.com/libfoo.git
+//
+// Other sections and keys are ignored. Submodules without path are rejected.
+func parseGitmodules(r io.Reader) ([]Submodule, error) {
+	submodules := []Submodule{}
+	var current *Submodule
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if line[0] == '[' {
+			end := strings.Index(line, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated section header", n)
+			}
+			current = nil
+			fields := strings.SplitN(strings.TrimSpace(line[1:end]), " ", 2)
+			if strings.EqualFold(fields[0], "submodule") && len(fields) == 2 {
+				submodules = append(submodules, Submodule{
+					Name: strings.Trim(strings.TrimSpace(fields[1]), `"`),
+				})
+				current = &submodules[len(submodules)-1]
+			}
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		value := strings.Trim(strings.TrimSpace(parts[1]), `"`)
+		switch strings.ToLower(strings.TrimSpace(parts[0])) {
+		case "path":
+			current.Path = strings.Trim(path.Clean(value), "/")
+		case "url":
+			current.URL = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	for _, s := range submodules {
+		if s.Path == "" || s.Path == "." {
+			return nil, fmt.Errorf("submodule %q has no path", s.Name)
+		}
+	}
+	return submodules, nil
+}
+
+// gitTopDir returns the closest directory above or at dir holding a
+// .gitmodules file, without going past the repository top directory, the
+// first one holding a .git entry.
+func gitTopDir(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".gitmodules")); err == nil {
+			return dir, nil
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	return "", fmt.Errorf("no .gitmodules file found")
+}
+
+// isCheckedOut returns true if dir exists and is not empty, uninitialized
+// submodules being left as empty directories.
+func isCheckedOut(dir string) bool {
+	fis, err := ioutil.ReadDir(dir)
+	return err == nil && len(fis) > 0
+}
+
+// submoduleModules returns the submodules of the repository at top, and of
+// their own nested submodules, as modules named after their path relative to
+// top. Submodules not checked out are reported with errNotCheckedOut.
+func submoduleModules(top, prefix string) ([]ManifestModule, error) {
+	f, err := os.Open(filepath.Join(top, ".gitmodules"))
+	if err != nil {
+		if os.IsNotExist(err) && prefix != "" {
+			return nil, nil
+		}
+		return nil, err
+	}
+	submodules, err := parseGitmodules(f)
+	f.Close()
+	if err != nil {
+		return nil, fmt.Errorf("could not parse %s: %s",
+			filepath.Join(top, ".gitmodules"), err)
+	}
+	modules := []ManifestModule{}
+	for _, s := range submodules {
+		dir := filepath.Join(top, filepath.FromSlash(s.Path))
+		m := ManifestModule{
+			Path: path.Join(prefix, s.Path),
+			Dir:  dir,
+		}
+		if !isCheckedOut(dir) {
+			m.Dir = ""
+			m.Error = errNotCheckedOut
+			modules = append(modules, m)
+			continue
+		}
+		modules = append(modules, m)
+		nested, err := submoduleModules(dir, m.Path)
+		if err != nil {
+			return nil, err
+		}
+		modules = append(modules, nested...)
+	}
+	return modules, nil
+}
+
+// listSubmodulesLicenses returns the licenses of the git submodules of the
+// repository holding dir, see submoduleModules, as pseudo-packages named
+// after their path, without invoking go. Versions are resolved in the
+// submodule directories by opts.Versions, if any.
+func listSubmodulesLicenses(dir string, opts Options) ([]License, error) {
+	top, err := gitTopDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	modules, err := submoduleModules(top, "")
+	if err != nil {
+		return nil, err
+	}
+	if opts.Versions != nil {
+		for i, m := range modules {
+			if m.Dir == "" {
+				continue
+			}
+			version, err := opts.Versions.Version(m.Dir, m.Path)
+			if err == nil {
+				modules[i].Version = version
+			}
+		}
+	}
+	return listModulesLicenses(modules, opts)
+}
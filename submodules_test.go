@@ -0,0 +1,88 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseGitmodules(t *testing.T) {
+	gitmodules := `# comment
+[submodule "libfoo"]
+	path = third_party/libfoo/
+	url = https://example.com/libfoo.git
+[core]
+	path = ignored
+[submodule "bar"]
+	; comment
+	Path = "bar"
+`
+	submodules, err := parseGitmodules(strings.NewReader(gitmodules))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Submodule{
+		{Name: "libfoo", Path: "third_party/libfoo", URL: "https://example.com/libfoo.git"},
+		{Name: "bar", Path: "bar"},
+	}
+	if !reflect.DeepEqual(submodules, expected) {
+		t.Fatalf("unexpected submodules: %+v", submodules)
+	}
+	for _, invalid := range []string{"[submodule \"x\"\n", "[submodule \"x\"]\nurl = u\n"} {
+		if _, err := parseGitmodules(strings.NewReader(invalid)); err == nil {
+			t.Errorf("%q was accepted", invalid)
+		}
+	}
+}
+
+func TestSubmodulesLicenses(t *testing.T) {
+	dir, err := ioutil.TempDir("", "licenses-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	mit, err := ioutil.ReadFile(filepath.Join("testdata", "src", "colors", "red", "LICENSE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		".gitmodules": "[submodule \"foo\"]\n\tpath = vendor/foo\n" +
+			"[submodule \"bar\"]\n\tpath = vendor/bar\n",
+		"vendor/foo/LICENSE":         string(mit),
+		"vendor/foo/.gitmodules":     "[submodule \"baz\"]\n\tpath = deps/baz\n",
+		"vendor/foo/deps/baz/README": "no license",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "vendor", "bar"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	licenses, err := listSubmodulesLicenses(filepath.Join(dir, "vendor"), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(licenses) != 3 {
+		t.Fatalf("unexpected licenses: %+v", licenses)
+	}
+	foo, baz, bar := licenses[0], licenses[1], licenses[2]
+	if foo.Package != "vendor/foo" || foo.Title() != "MIT License" || foo.Err != "" {
+		t.Fatalf("unexpected foo license: %+v", foo)
+	}
+	// Nested submodules do not inherit their parent license.
+	if baz.Package != "vendor/foo/deps/baz" || baz.Template != nil || baz.Err != "" {
+		t.Fatalf("unexpected baz license: %+v", baz)
+	}
+	if bar.Package != "vendor/bar" || bar.Err != errNotCheckedOut {
+		t.Fatalf("unexpected bar license: %+v", bar)
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	// suggestMinScore is the dice score above which low confidence matches
+	// are taken as customized variants of their template, see
+	// scaleConfidence for other algorithms.
+	suggestMinScore = 0.7
+	// suggestMaxWords is the maximum number of extra and missing words of
+	// suggested variants. Beyond it, texts differ in more than a few
+	// customized sentences.
+	suggestMaxWords = 20
+)
+
+// Suggestion is a near-miss match whose license text would deserve its own
+// template, as a variant of an existing one.
+type Suggestion struct {
+	// Title is the title of the would-be template.
+	Title string
+	// Template is the template the license text is a variant of.
+	Template *Template
+	// Packages are the packages using the variant.
+	Packages []string
+	Score    float64
+	Extra    []string
+	Missing  []string
+}
+
+// suggestTemplates returns the license files of licenses scoring between
+// minScore, like suggestMinScore, and confidence, with at most
+// suggestMaxWords differing words, as template suggestions. Packages with the
+// same differences are merged into a single suggestion.
+func suggestTemplates(licenses []License, minScore, confidence float64) []Suggestion {
+	suggestions := []Suggestion{}
+	index := map[string]int{}
+	for _, l := range licenses {
+		// Header matches compare a few lines only, not whole texts.
+		if l.Err != "" || l.Template == nil || l.Override || l.Header ||
+			l.Score < minScore || l.Score >= confidence ||
+			len(l.ExtraWords)+len(l.MissingWords) > suggestMaxWords {
+			continue
+		}
+		packages := append([]string{l.Package}, l.Similar...)
+		key := l.Template.Title + "\x00" + strings.Join(l.ExtraWords, " ") + "\x00" +
+			strings.Join(l.MissingWords, " ")
+		if i, ok := index[key]; ok {
+			suggestions[i].Packages = append(suggestions[i].Packages, packages...)
+			continue
+		}
+		index[key] = len(suggestions)
+		suggestions = append(suggestions, Suggestion{
+			Title:    fmt.Sprintf("%s (%s variant)", l.Template.Title, l.Package),
+			Template: l.Template,
+			Packages: packages,
+			Score:    l.Score,
+			Extra:    l.ExtraWords,
+			Missing:  l.MissingWords,
+		})
+	}
+	return suggestions
+}
+
+// printSuggestions writes suggestions to w, with their differing words.
+func printSuggestions(w io.Writer, suggestions []Suggestion) error {
+	for _, s := range suggestions {
+		_, err := fmt.Fprintf(w, "suggested template: %q, %s scoring %d%% (%s)\n",
+			s.Title, s.Template.Title, int(100*s.Score), strings.Join(s.Packages, ", "))
+		if err != nil {
+			return err
+		}
+		if len(s.Extra) > 0 {
+			_, err = fmt.Fprintf(w, "\t+words: %s\n", strings.Join(s.Extra, ", "))
+			if err != nil {
+				return err
+			}
+		}
+		if len(s.Missing) > 0 {
+			_, err = fmt.Fprintf(w, "\t-words: %s\n", strings.Join(s.Missing, ", "))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestSuggestTemplates(t *testing.T) {
+	mit := &Template{Title: "MIT License"}
+	many := make([]string, suggestMaxWords+1)
+	licenses := []License{
+		{Package: "a", Template: mit, Score: 0.85, ExtraWords: []string{"pink"},
+			MissingWords: []string{"software"}, Similar: []string{"b"}},
+		{Package: "c", Template: mit, Score: 0.95},
+		{Package: "d", Template: mit, Score: 0.6},
+		{Package: "e", Template: mit, Score: 0.8, ExtraWords: many},
+		{Package: "f", Template: mit, Score: 0.8, Header: true},
+		{Package: "g", Template: mit, Score: 0.75, ExtraWords: []string{"pink"},
+			MissingWords: []string{"software"}},
+		{Package: "h", Template: mit, Score: 0.8, MissingWords: []string{"copyright"}},
+	}
+	suggestions := suggestTemplates(licenses, suggestMinScore, 0.9)
+	expected := []Suggestion{
+		{
+			Title:    "MIT License (a variant)",
+			Template: mit,
+			Packages: []string{"a", "b", "g"},
+			Score:    0.85,
+			Extra:    []string{"pink"},
+			Missing:  []string{"software"},
+		},
+		{
+			Title:    "MIT License (h variant)",
+			Template: mit,
+			Packages: []string{"h"},
+			Score:    0.8,
+			Missing:  []string{"copyright"},
+		},
+	}
+	if !reflect.DeepEqual(suggestions, expected) {
+		t.Fatalf("unexpected suggestions:\n%+v\nexpected:\n%+v", suggestions, expected)
+	}
+
+	out := &bytes.Buffer{}
+	err := printSuggestions(out, suggestions[:1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	wanted := "suggested template: \"MIT License (a variant)\", MIT License scoring 85% (a, b, g)\n" +
+		"\t+words: pink\n\t-words: software\n"
+	if out.String() != wanted {
+		t.Fatalf("unexpected output: %q", out.String())
+	}
+}
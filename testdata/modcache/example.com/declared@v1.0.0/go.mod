@@ -0,0 +1,3 @@
+// license: Apache-2.0
+
+module example.com/declared
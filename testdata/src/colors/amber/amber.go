@@ -0,0 +1,5 @@
+package amber
+
+func amber() string {
+	return "amber"
+}
@@ -0,0 +1,5 @@
+package azure
+
+func azure() string {
+	return "azure"
+}
@@ -0,0 +1,5 @@
+package beige
+
+func beige() string {
+	return "beige"
+}
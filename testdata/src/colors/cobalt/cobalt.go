@@ -0,0 +1,5 @@
+package cobalt
+
+func cobalt() string {
+	return "cobalt"
+}
@@ -0,0 +1,5 @@
+package gray
+
+func gray() string {
+	return "gray"
+}
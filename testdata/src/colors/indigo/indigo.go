@@ -0,0 +1,5 @@
+package indigo
+
+func indigo() string {
+	return "indigo"
+}
@@ -0,0 +1,5 @@
+package ivory
+
+func ivory() string {
+	return "ivory"
+}
@@ -0,0 +1,5 @@
+package linen
+
+func linen() string {
+	return "linen"
+}
@@ -0,0 +1,5 @@
+package maroon
+
+func maroon() string {
+	return "maroon"
+}
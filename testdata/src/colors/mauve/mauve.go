@@ -0,0 +1,5 @@
+package mauve
+
+func mauve() string {
+	return "mauve"
+}
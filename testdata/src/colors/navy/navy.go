@@ -0,0 +1,5 @@
+package navy
+
+func navy() string {
+	return "navy"
+}
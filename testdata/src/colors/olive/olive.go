@@ -0,0 +1,5 @@
+package olive
+
+func olive() string {
+	return "olive"
+}
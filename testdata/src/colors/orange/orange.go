@@ -0,0 +1,5 @@
+package orange
+
+func orange() string {
+	return "orange"
+}
@@ -0,0 +1,5 @@
+package pink
+
+func pink() string {
+	return "pink"
+}
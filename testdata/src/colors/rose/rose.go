@@ -0,0 +1,5 @@
+package rose
+
+func rose() string {
+	return "rose"
+}
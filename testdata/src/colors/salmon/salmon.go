@@ -0,0 +1,12 @@
+package salmon
+
+import (
+	_ "embed"
+)
+
+//go:embed LICENSE
+var license string
+
+func salmon() string {
+	return "salmon"
+}
@@ -0,0 +1,5 @@
+package teal
+
+func teal() string {
+	return "teal"
+}
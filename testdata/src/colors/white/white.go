@@ -0,0 +1,5 @@
+package white
+
+func white() string {
+	return "white"
+}
@@ -0,0 +1,5 @@
+module example.com/app
+
+go 1.19
+
+require example.com/lib v1.2.0
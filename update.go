@@ -0,0 +1,347 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pmezard/licenses/assets"
+)
+
+// TemplateSource is a license text read by -update-templates. Name is the
+// SPDX identifier for plain texts, like "GPL-2.0.txt" in SPDX license list
+// data, and Data either a plain text or a template with front matter.
+type TemplateSource struct {
+	Name string
+	Data []byte
+}
+
+// TemplateUpdate describes an added or changed template asset.
+type TemplateUpdate struct {
+	// File is the asset file name, like "gpl_2.0.txt".
+	File string
+	// Variable is the assets package variable holding the asset.
+	Variable string
+	Content  string
+	// Added is true if no asset has this file name yet.
+	Added bool
+}
+
+// readTemplateSources reads license texts from src. If src is a directory,
+// every .txt file in it is read. Otherwise src is the base URL of SPDX
+// license texts, fetched as src/<SPDX>.txt for every English template of
+// known.
+func readTemplateSources(src string, known []*Template) ([]TemplateSource, error) {
+	if fi, err := os.Stat(src); err == nil && fi.IsDir() {
+		fis, err := ioutil.ReadDir(src)
+		if err != nil {
+			return nil, err
+		}
+		sources := []TemplateSource{}
+		for _, fi := range fis {
+			name := fi.Name()
+			if !fi.Mode().IsRegular() || filepath.Ext(name) != ".txt" {
+				continue
+			}
+			data, err := ioutil.ReadFile(filepath.Join(src, name))
+			if err != nil {
+				return nil, err
+			}
+			sources = append(sources, TemplateSource{
+				Name: strings.TrimSuffix(name, ".txt"),
+				Data: data,
+			})
+		}
+		return sources, nil
+	}
+	if !strings.HasPrefix(src, "http://") && !strings.HasPrefix(src, "https://") {
+		return nil, fmt.Errorf("%s is neither a directory nor an URL", src)
+	}
+	sources := []TemplateSource{}
+	for _, t := range known {
+		if t.SPDX == "" || t.Language != "" {
+			continue
+		}
+		url := strings.TrimSuffix(src, "/") + "/" + t.SPDX + ".txt"
+		data, err := fetch(url)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, TemplateSource{Name: t.SPDX, Data: data})
+	}
+	return sources, nil
+}
+
+func fetch(url string) ([]byte, error) {
+	rsp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not fetch %s: %s", url, rsp.Status)
+	}
+	return ioutil.ReadAll(rsp.Body)
+}
+
+// templateKey identifies templates across updates.
+func templateKey(spdx, language string) string {
+	return strings.ToLower(spdx) + "/" + language
+}
+
+var reAssetFile = regexp.MustCompile(`[^a-z0-9.]+`)
+
+// assetFileName returns the asset file name of a new template, like
+// "gpl_2.0.txt" for GPL-2.0.
+func assetFileName(spdx string) string {
+	return reAssetFile.ReplaceAllString(strings.ToLower(spdx), "_") + ".txt"
+}
+
+// assetVariable returns the variable name of asset file, like "gpl_2_0" for
+// "gpl_2.0.txt". Names starting with a digit are prefixed with "license_".
+func assetVariable(file string) string {
+	name := strings.TrimSuffix(file, ".txt")
+	name = strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, name)
+	if name == "" || name[0] >= '0' && name[0] <= '9' {
+		name = "license_" + name
+	}
+	return name
+}
+
+// frontMatter returns the front matter of template content, up to and
+// including its closing "---" line, or an empty string if there is none.
+func frontMatter(content string) string {
+	lines := strings.SplitAfter(content, "\n")
+	delimiters, size := 0, 0
+	for _, line := range lines {
+		size += len(line)
+		if strings.TrimSpace(line) == "---" {
+			delimiters++
+			if delimiters == 2 {
+				return content[:size]
+			}
+		}
+	}
+	return ""
+}
+
+// convertTemplate returns src as a template. Templates with front matter are
+// kept as is. Plain texts get the front matter of the template with the same
+// SPDX identifier in fronts, if any, or a minimal one.
+func convertTemplate(src TemplateSource, fronts map[string]string) (string, *Template, error) {
+	content := string(normalizeEOL(src.Data))
+	if !strings.HasPrefix(strings.TrimSpace(content), "---") {
+		front, ok := fronts[templateKey(src.Name, "")]
+		if !ok {
+			front = "---\ntitle: " + src.Name + "\nspdx-id: " + src.Name + "\n---\n"
+		}
+		content = front + "\n" + content
+	}
+	t, err := parseTemplate(content)
+	if err == nil && t.SPDX == "" && !shortTemplates[t.Title] {
+		err = fmt.Errorf("missing spdx-id")
+	}
+	if err == nil {
+		err = validateTemplate(t)
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid template %s: %s", src.Name, err)
+	}
+	return content, t, nil
+}
+
+// planTemplateUpdates returns the assets to add or change so embedded
+// templates match sources.
+func planTemplateUpdates(sources []TemplateSource) ([]TemplateUpdate, error) {
+	fronts := map[string]string{}
+	files := map[string]string{}
+	contents := map[string]string{}
+	for _, a := range assets.Assets {
+		t, err := parseTemplate(a.Content)
+		if err != nil {
+			return nil, err
+		}
+		key := templateKey(t.SPDX, t.Language)
+		fronts[key] = frontMatter(a.Content)
+		files[key] = a.Name
+		contents[a.Name] = a.Content
+	}
+	updates := []TemplateUpdate{}
+	for _, src := range sources {
+		content, t, err := convertTemplate(src, fronts)
+		if err != nil {
+			return nil, err
+		}
+		key := templateKey(t.SPDX, t.Language)
+		file, ok := files[key]
+		if !ok {
+			file = assetFileName(t.SPDX)
+			if t.Language != "" {
+				file = strings.TrimSuffix(file, ".txt") + "_" + t.Language + ".txt"
+			}
+			files[key] = file
+		}
+		old, exists := contents[file]
+		if exists && old == content {
+			continue
+		}
+		updates = append(updates, TemplateUpdate{
+			File:     file,
+			Variable: assetVariable(file),
+			Content:  content,
+			Added:    !exists,
+		})
+	}
+	sort.Slice(updates, func(i, j int) bool {
+		return updates[i].File < updates[j].File
+	})
+	return updates, nil
+}
+
+// writeAsset writes content as the generated source of an assets package
+// variable, like assets/asset.go does.
+func writeAsset(w io.Writer, variable, file, content string) error {
+	h := fnv.New64a()
+	h.Write([]byte(content))
+	etag := `"` + base64.StdEncoding.EncodeToString(h.Sum(nil)) + `"`
+	_, err := fmt.Fprintf(w, "// AUTOMATICALLY "+"GENERATED FILE. DO NOT EDIT.\n\n"+
+		"package assets\n\n"+
+		"var %s = txt(asset{Name: %q, Content: \"\" +\n"+
+		"\t%s +\n"+
+		"\t\"\", etag: %#q})\n", variable, file, strconv.QuoteToASCII(content), etag)
+	return err
+}
+
+const generatePrefix = "//go:generate asset "
+
+// generateVariable returns the file and variable names of an asset
+// go:generate directive, like "//go:generate asset -var bsd_0 0bsd.txt". It
+// returns empty strings for other lines.
+func generateVariable(line string) (string, string) {
+	if !strings.HasPrefix(line, generatePrefix) {
+		return "", ""
+	}
+	fields := strings.Fields(strings.TrimPrefix(line, generatePrefix))
+	if len(fields) == 0 {
+		return "", ""
+	}
+	file := fields[len(fields)-1]
+	variable := strings.SplitN(file, ".", 2)[0]
+	if len(fields) == 3 && fields[0] == "-var" {
+		variable = fields[1]
+	}
+	return file, variable
+}
+
+// addGenerateLines adds go:generate directives for updates to the assets.go
+// file at path, unless they are already listed, in which case the listed
+// variable names are set in updates.
+func addGenerateLines(path string, updates []TemplateUpdate) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	lines := strings.Split(string(data), "\n")
+	generates := []string{}
+	variables := map[string]string{}
+	first, last := -1, -1
+	for i, line := range lines {
+		if file, variable := generateVariable(line); file != "" {
+			if first < 0 {
+				first = i
+			}
+			last = i
+			generates = append(generates, line)
+			variables[file] = variable
+		}
+	}
+	if first < 0 {
+		return fmt.Errorf("no go:generate directive found in %s", path)
+	}
+	for i, u := range updates {
+		if variable, ok := variables[u.File]; ok {
+			updates[i].Variable = variable
+			continue
+		}
+		line := generatePrefix + u.File
+		if u.Variable != strings.SplitN(u.File, ".", 2)[0] {
+			line = generatePrefix + "-var " + u.Variable + " " + u.File
+		}
+		generates = append(generates, line)
+	}
+	sort.Slice(generates, func(i, j int) bool {
+		fi, _ := generateVariable(generates[i])
+		fj, _ := generateVariable(generates[j])
+		return fi < fj
+	})
+	out := append([]string{}, lines[:first]...)
+	out = append(out, generates...)
+	out = append(out, lines[last+1:]...)
+	return ioutil.WriteFile(path, []byte(strings.Join(out, "\n")), 0644)
+}
+
+// updateTemplates reads license texts from src, see readTemplateSources, and
+// writes added or changed templates and their generated sources in the
+// assets directory dir. Updates are reported to w. With dryRun, nothing is
+// written.
+func updateTemplates(w io.Writer, src, dir string, dryRun bool) error {
+	known, err := loadTemplates()
+	if err != nil {
+		return err
+	}
+	sources, err := readTemplateSources(src, known)
+	if err != nil {
+		return err
+	}
+	updates, err := planTemplateUpdates(sources)
+	if err != nil {
+		return err
+	}
+	if !dryRun && len(updates) > 0 {
+		// Existing assets keep their variable names.
+		err = addGenerateLines(filepath.Join(dir, "assets.go"), updates)
+		if err != nil {
+			return err
+		}
+	}
+	for _, u := range updates {
+		action := "change"
+		if u.Added {
+			action = "add"
+		}
+		fmt.Fprintf(w, "%s %s\n", action, u.File)
+		if dryRun {
+			continue
+		}
+		path := filepath.Join(dir, u.File)
+		err = ioutil.WriteFile(path, []byte(u.Content), 0644)
+		if err != nil {
+			return err
+		}
+		buf := &bytes.Buffer{}
+		err = writeAsset(buf, u.Variable, u.File, u.Content)
+		if err != nil {
+			return err
+		}
+		err = ioutil.WriteFile(path+".gen.go", buf.Bytes(), 0644)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pmezard/licenses/assets"
+)
+
+func TestPlanTemplateUpdates(t *testing.T) {
+	sources := []TemplateSource{}
+	var mit string
+	for _, a := range assets.Assets {
+		sources = append(sources, TemplateSource{Name: a.Name, Data: []byte(a.Content)})
+		if a.Name == "mit.txt" {
+			mit = a.Content
+		}
+	}
+	updates, err := planTemplateUpdates(sources)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updates) != 0 {
+		t.Fatalf("embedded templates should be unchanged: %+v", updates)
+	}
+	body := "Permission is hereby granted to any person obtaining a copy of this " +
+		"software and associated documentation files, to deal in the software " +
+		"without restriction, subject to the following conditions.\n"
+	updates, err = planTemplateUpdates([]TemplateSource{
+		{Name: "MIT", Data: []byte(mit[len(frontMatter(mit)):] + "extra words\n")},
+		{Name: "Foo-1.0", Data: []byte("Foo License 1.0\r\n\r\n" + body)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updates) != 2 {
+		t.Fatalf("two updates expected: %+v", updates)
+	}
+	foo, changed := updates[0], updates[1]
+	if foo.File != "foo_1.0.txt" || foo.Variable != "foo_1_0" || !foo.Added ||
+		!strings.HasPrefix(foo.Content, "---\ntitle: Foo-1.0\nspdx-id: Foo-1.0\n---\n") ||
+		strings.Contains(foo.Content, "\r") {
+		t.Fatalf("unexpected added template: %+v", foo)
+	}
+	if changed.File != "mit.txt" || changed.Added ||
+		!strings.HasPrefix(changed.Content, frontMatter(mit)) {
+		t.Fatalf("unexpected changed template: %+v", changed)
+	}
+	_, err = planTemplateUpdates([]TemplateSource{{Name: "Short", Data: []byte("short")}})
+	if err == nil {
+		t.Fatal("invalid template should fail")
+	}
+}
+
+func TestWriteAsset(t *testing.T) {
+	expected, err := ioutil.ReadFile(filepath.Join("assets", "mit.txt.gen.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := ioutil.ReadFile(filepath.Join("assets", "mit.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := &bytes.Buffer{}
+	err = writeAsset(buf, "mit", "mit.txt", string(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != string(expected) {
+		t.Fatalf("generated asset differs from assets/asset.go output:\n%s", buf.String())
+	}
+}
+
+func TestUpdateTemplates(t *testing.T) {
+	src, err := ioutil.TempDir("", "licenses")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(src)
+	body := "Permission is hereby granted to any person obtaining a copy of this " +
+		"software and associated documentation files, to deal in the software " +
+		"without restriction, subject to the following conditions.\n"
+	err = ioutil.WriteFile(filepath.Join(src, "0BSD-2.txt"), []byte(body), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "licenses")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	data, err := ioutil.ReadFile(filepath.Join("assets", "assets.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(filepath.Join(dir, "assets.go"), data, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := &bytes.Buffer{}
+	err = updateTemplates(out, src, dir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "add 0bsd_2.txt\n" {
+		t.Fatalf("unexpected dry run output: %q", out.String())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "0bsd_2.txt")); !os.IsNotExist(err) {
+		t.Fatal("dry run should not write templates")
+	}
+
+	err = updateTemplates(out, src, dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gen := filepath.Join(dir, "0bsd_2.txt.gen.go")
+	_, err = parser.ParseFile(token.NewFileSet(), gen, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err = ioutil.ReadFile(filepath.Join(dir, "assets.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data),
+		"//go:generate asset -var bsd_0 0bsd.txt\n"+
+			"//go:generate asset -var license_0bsd_2 0bsd_2.txt\n"+
+			"//go:generate asset afl_3.0.txt\n") {
+		t.Fatalf("go:generate directive not added:\n%s", data)
+	}
+}
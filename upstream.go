@@ -0,0 +1,219 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// parseUpstream parses a -compare-upstream value like
+// "example.com/fork=https://example.com/upstream/LICENSE" into a package and
+// the location of its upstream license.
+func parseUpstream(value string) (string, string, error) {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" ||
+		strings.TrimSpace(parts[1]) == "" {
+		return "", "", fmt.Errorf("invalid upstream, expected PACKAGE=URL: %s", value)
+	}
+	return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]), nil
+}
+
+// gitForges lists the hosts whose https://HOST/OWNER/REPO addresses are git
+// repositories, rather than web pages.
+var gitForges = map[string]bool{
+	"bitbucket.org": true,
+	"codeberg.org":  true,
+	"github.com":    true,
+	"gitlab.com":    true,
+}
+
+// isGitURL returns true if src looks like a git repository address rather
+// than the address of a license file, like https://github.com/owner/repo.
+func isGitURL(src string) bool {
+	if strings.HasSuffix(src, ".git") || strings.HasPrefix(src, "git@") ||
+		strings.HasPrefix(src, "git://") || strings.HasPrefix(src, "ssh://") {
+		return true
+	}
+	u, err := url.Parse(src)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") ||
+		!gitForges[strings.ToLower(u.Host)] || u.RawQuery != "" {
+		return false
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	return len(parts) == 2 && parts[0] != "" && parts[1] != ""
+}
+
+// readLicenseDir returns the text of the license file of directory dir, see
+// findLicenseIn and readLicenseText.
+func readLicenseDir(dir string) ([]byte, error) {
+	path, err := findLicenseIn(dir, ".", 0)
+	if err != nil {
+		return nil, err
+	}
+	if path == "" {
+		return nil, fmt.Errorf("no license found in %s", dir)
+	}
+	return readLicenseText(filepath.Join(dir, path))
+}
+
+// readUpstreamLicense returns the license text at src, which is either a git
+// repository address, cloned to find its license file, the URL of a license
+// file, or a local license file or directory.
+func readUpstreamLicense(src string) ([]byte, error) {
+	if isGitURL(src) {
+		if _, err := exec.LookPath("git"); err != nil {
+			return nil, fmt.Errorf("could not find git executable in PATH")
+		}
+		dir, err := ioutil.TempDir("", "licenses-upstream-")
+		if err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(dir)
+		_, err = runIn(dir, "git", "clone", "--quiet", "--depth", "1", "--", src,
+			"repo")
+		if err != nil {
+			return nil, err
+		}
+		return readLicenseDir(filepath.Join(dir, "repo"))
+	}
+	if strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://") {
+		return fetch(src)
+	}
+	fi, err := os.Stat(src)
+	if err != nil {
+		return nil, err
+	}
+	if fi.IsDir() {
+		return readLicenseDir(src)
+	}
+	return readLicenseText(src)
+}
+
+// UpstreamComparison is the comparison of a package license with the one of
+// its upstream.
+type UpstreamComparison struct {
+	Package  string
+	Upstream string
+	// License and UpstreamLicense are the best matching templates of both
+	// texts, nil if unknown.
+	License         *Template
+	UpstreamLicense *Template
+	// Score is the similarity of both texts, see compareWords.
+	Score float64
+	// Extra and Missing are the words of the package license absent from the
+	// upstream one, and conversely.
+	Extra   []string
+	Missing []string
+	// Same is true if both texts are similar above the confidence threshold
+	// and match the same template, if any.
+	Same bool
+}
+
+// compareLicenseTexts compares license text with upstream text, with the
+// opts.Algorithm score, the upstream text standing for the template. Both
+// texts are matched against templates as well, so relicensing to another
+// known license is reported even if both texts are alike, like for sibling
+// BSD licenses.
+func compareLicenseTexts(templates []*Template, text, upstream []byte,
+	confidence float64, opts Options) UpstreamComparison {
+
+	text, upstream = decodeLicenseData(text), decodeLicenseData(upstream)
+	reference := &Template{text: upstream}
+	score, _, extra, missing := compareWords(makeWordSet(text),
+		licenseCounts(text, opts.Algorithm), reference, 0, scoreFunc(opts.Algorithm))
+	c := UpstreamComparison{
+		Score:   score,
+		Extra:   sortAndReturnWords(extra),
+		Missing: sortAndReturnWords(missing),
+	}
+	if r := MatchLicense(templates, text, opts); r.Score >= confidence {
+		c.License = r.Template
+	}
+	if r := MatchLicense(templates, upstream, opts); r.Score >= confidence {
+		c.UpstreamLicense = r.Template
+	}
+	c.Same = score >= confidence && c.License == c.UpstreamLicense
+	return c
+}
+
+// compareUpstreams compares the license of every package of values, see
+// parseUpstream, with its upstream license, see readUpstreamLicense.
+func compareUpstreams(env *GoEnv, values []string, confidence float64,
+	opts Options) ([]UpstreamComparison, error) {
+
+	m, err := prepareMatcher(opts)
+	if err != nil {
+		return nil, err
+	}
+	comparisons := []UpstreamComparison{}
+	for _, value := range values {
+		pkg, src, err := parseUpstream(value)
+		if err != nil {
+			return nil, err
+		}
+		fpath, err := locateLicense(env, pkg)
+		if err != nil {
+			return nil, err
+		}
+		text, err := readLicenseText(fpath)
+		if err != nil {
+			return nil, err
+		}
+		upstream, err := readUpstreamLicense(src)
+		if err != nil {
+			return nil, fmt.Errorf("could not read %s upstream license: %s", pkg, err)
+		}
+		c := compareLicenseTexts(m.templates, text, upstream, confidence, opts)
+		c.Package, c.Upstream = pkg, src
+		comparisons = append(comparisons, c)
+	}
+	return comparisons, nil
+}
+
+// templateTitle returns the title of t, or "?" if t is nil.
+func templateTitle(t *Template) string {
+	if t == nil {
+		return "?"
+	}
+	return t.Title
+}
+
+// printUpstreamComparisons writes comparisons to w, with the differing words
+// of licenses unlike their upstream ones.
+func printUpstreamComparisons(w io.Writer, comparisons []UpstreamComparison,
+	maxWords int) error {
+
+	for _, c := range comparisons {
+		verdict := "matches"
+		if !c.Same {
+			verdict = "differs from"
+		}
+		_, err := fmt.Fprintf(w, "%s: license %s upstream %s (%d%%): %s, upstream %s\n",
+			c.Package, verdict, c.Upstream, int(100*c.Score), templateTitle(c.License),
+			templateTitle(c.UpstreamLicense))
+		if err != nil {
+			return err
+		}
+		if c.Same {
+			continue
+		}
+		if len(c.Extra) > 0 {
+			_, err = fmt.Fprintf(w, "\t+words: %s\n", joinWords(c.Extra, maxWords))
+			if err != nil {
+				return err
+			}
+		}
+		if len(c.Missing) > 0 {
+			_, err = fmt.Fprintf(w, "\t-words: %s\n", joinWords(c.Missing, maxWords))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseUpstream(t *testing.T) {
+	pkg, src, err := parseUpstream(" example.com/fork = https://example.com/LICENSE?a=b ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pkg != "example.com/fork" || src != "https://example.com/LICENSE?a=b" {
+		t.Fatalf("unexpected upstream: %q %q", pkg, src)
+	}
+	for _, value := range []string{"example.com/fork", "=url", "pkg="} {
+		if _, _, err := parseUpstream(value); err == nil {
+			t.Errorf("%q: error expected", value)
+		}
+	}
+	gitURLs := map[string]bool{
+		"https://example.com/upstream.git":                   true,
+		"git@example.com:a/b":                                true,
+		"https://github.com/org/repo":                        true,
+		"https://GitLab.com/org/repo/":                       true,
+		"https://example.com/LICENSE":                        false,
+		"https://example.com/org/repo":                       false,
+		"https://github.com/org":                             false,
+		"https://github.com/org/repo/blob/master/LICENSE":    false,
+		"https://raw.githubusercontent.com/org/repo/LICENSE": false,
+	}
+	for src, expected := range gitURLs {
+		if isGitURL(src) != expected {
+			t.Errorf("%s: expected git URL %v", src, expected)
+		}
+	}
+}
+
+func TestCompareUpstreams(t *testing.T) {
+	gopath, err := filepath.Abs("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	colors := filepath.Join(gopath, "src", "colors")
+	_, err = compareUpstreams(&GoEnv{GOPATH: gopath}, []string{
+		"colors/red=" + filepath.Join(colors, "lime"),
+	}, 0.9, Options{})
+	if err == nil {
+		t.Fatal("upstream without license was accepted")
+	}
+	comparisons, err := compareUpstreams(&GoEnv{GOPATH: gopath}, []string{
+		"colors/red=" + filepath.Join(colors, "mauve", "LICENSE"),
+		"colors/red=" + filepath.Join(colors, "pink"),
+		"colors/pink=" + filepath.Join(colors, "red"),
+	}, 0.9, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	same, extended, forked := comparisons[0], comparisons[1], comparisons[2]
+	if !same.Same || same.License == nil || same.License != same.UpstreamLicense {
+		t.Fatalf("licenses should match: %+v", same)
+	}
+	// Upstream added terms to its MIT license, the fork is the original text.
+	if extended.Same || extended.License == nil || extended.UpstreamLicense != nil ||
+		len(extended.Missing) == 0 || len(extended.Extra) != 0 {
+		t.Fatalf("licenses should differ: %+v", extended)
+	}
+	if forked.Same || len(forked.Extra) == 0 {
+		t.Fatalf("licenses should differ: %+v", forked)
+	}
+
+	out := &bytes.Buffer{}
+	err = printUpstreamComparisons(out, comparisons[:2], 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(out.String(), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[0], "colors/red: license matches upstream") ||
+		!strings.Contains(lines[1], "differs from upstream") ||
+		!strings.HasPrefix(lines[2], "\t-words: ") || !strings.Contains(lines[2], " more)") {
+		t.Fatalf("unexpected output: %q", out.String())
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// VersionResolver returns the version of the package with supplied directory
+// and import path.
+type VersionResolver interface {
+	Version(dir, importPath string) (string, error)
+}
+
+// runIn runs name with args in dir and returns its trimmed standard output.
+func runIn(dir, name string, args ...string) (string, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("'%s %s' failed in %s: %s", name,
+			strings.Join(args, " "), dir, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// GitResolver returns the commit checked out in the package git repository.
+type GitResolver struct{}
+
+func (r GitResolver) Version(dir, importPath string) (string, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return "", fmt.Errorf("could not find git executable in PATH")
+	}
+	return runIn(dir, "git", "rev-parse", "HEAD")
+}
+
+// DescribeResolver returns a human friendly name of the commit checked out in
+// the package git repository, like v1.2.0-3-g1a2b3c4, its closest tag, or an
+// abbreviated hash. It falls back to the full commit hash.
+type DescribeResolver struct{}
+
+func (r DescribeResolver) Version(dir, importPath string) (string, error) {
+	head, err := GitResolver{}.Version(dir, importPath)
+	if err != nil {
+		return "", err
+	}
+	version, err := runIn(dir, "git", "describe", "--tags", "--always", head)
+	if err != nil || version == "" {
+		return head, nil
+	}
+	return version, nil
+}
+
+// vcsCommands maps version control metadata directories to the command
+// printing the working copy revision.
+var vcsCommands = []struct {
+	Dir  string
+	Args []string
+}{
+	{Dir: ".git", Args: []string{"git", "rev-parse", "HEAD"}},
+	{Dir: ".hg", Args: []string{"hg", "id", "-i"}},
+	{Dir: ".bzr", Args: []string{"bzr", "revno"}},
+	{Dir: ".svn", Args: []string{"svnversion"}},
+}
+
+// VCSResolver looks for the version control system managing the package
+// directory and returns its working copy revision.
+type VCSResolver struct{}
+
+func (r VCSResolver) Version(dir, importPath string) (string, error) {
+	for d := dir; ; d = filepath.Dir(d) {
+		for _, vcs := range vcsCommands {
+			if _, err := os.Stat(filepath.Join(d, vcs.Dir)); err == nil {
+				if _, err := exec.LookPath(vcs.Args[0]); err != nil {
+					return "", fmt.Errorf("could not find %s executable in PATH",
+						vcs.Args[0])
+				}
+				return runIn(dir, vcs.Args[0], vcs.Args[1:]...)
+			}
+		}
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+	return "", fmt.Errorf("no version control system found for %s", dir)
+}
+
+// ModuleResolver extracts the version from module cache directories, named
+// like module@version.
+type ModuleResolver struct{}
+
+func (r ModuleResolver) Version(dir, importPath string) (string, error) {
+	if _, version := moduleCacheDir(dir); version != "" {
+		return version, nil
+	}
+	return "", fmt.Errorf("%s is not in a module cache", dir)
+}
+
+// DateResolver returns the most recent modification date of the package
+// files.
+type DateResolver struct{}
+
+func (r DateResolver) Version(dir, importPath string) (string, error) {
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	last := time.Time{}
+	for _, fi := range fis {
+		if fi.Mode().IsRegular() && fi.ModTime().After(last) {
+			last = fi.ModTime()
+		}
+	}
+	if last.IsZero() {
+		return "", fmt.Errorf("no file found in %s", dir)
+	}
+	return last.UTC().Format("2006-01-02"), nil
+}
+
+var versionResolvers = map[string]VersionResolver{
+	"git":      GitResolver{},
+	"describe": DescribeResolver{},
+	"vcs":      VCSResolver{},
+	"module":   ModuleResolver{},
+	"date":     DateResolver{},
+}
+
+// getVersionResolver returns the resolver registered under name, or nil if
+// name is empty.
+func getVersionResolver(name string) (VersionResolver, error) {
+	if name == "" {
+		return nil, nil
+	}
+	r, ok := versionResolvers[name]
+	if !ok {
+		names := []string{}
+		for n := range versionResolvers {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown version resolver %s, expected one of: %s",
+			name, strings.Join(names, ", "))
+	}
+	return r, nil
+}
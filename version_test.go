@@ -0,0 +1,102 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestModuleResolver(t *testing.T) {
+	dir := filepath.Join("mod", "github.com", "pmezard", "colors@v1.2.3", "red")
+	version, err := ModuleResolver{}.Version(dir, "github.com/pmezard/colors/red")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != "v1.2.3" {
+		t.Fatalf("unexpected version: %s", version)
+	}
+	_, err = ModuleResolver{}.Version(filepath.Join("src", "colors", "red"), "colors/red")
+	if err == nil {
+		t.Fatal("versionless directory should fail")
+	}
+}
+
+func TestDateResolver(t *testing.T) {
+	dir, err := ioutil.TempDir("", "licenses")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "red.go")
+	err = ioutil.WriteFile(path, []byte("package red\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	date := time.Date(2016, 3, 4, 12, 0, 0, 0, time.UTC)
+	err = os.Chtimes(path, date, date)
+	if err != nil {
+		t.Fatal(err)
+	}
+	version, err := DateResolver{}.Version(dir, "colors/red")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != "2016-03-04" {
+		t.Fatalf("unexpected version: %s", version)
+	}
+}
+
+func TestUnknownVersionResolver(t *testing.T) {
+	r, err := getVersionResolver("")
+	if r != nil || err != nil {
+		t.Fatalf("no resolver expected, got %v, %v", r, err)
+	}
+	_, err = getVersionResolver("cvs")
+	if err == nil {
+		t.Fatal("unknown resolver should fail")
+	}
+}
+
+func TestDescribeResolver(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+	dir, err := ioutil.TempDir("", "licenses")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	git := func(args ...string) {
+		args = append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"},
+			args...)
+		_, err := runIn(dir, "git", args...)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	git("init", "-q")
+	git("commit", "-q", "--allow-empty", "-m", "initial")
+	head, err := GitResolver{}.Version(dir, "colors/red")
+	if err != nil {
+		t.Fatal(err)
+	}
+	version, err := DescribeResolver{}.Version(dir, "colors/red")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version == head || !strings.HasPrefix(head, version) {
+		t.Fatalf("abbreviated hash of %s expected, got %s", head, version)
+	}
+	git("tag", "v1.2.0")
+	version, err = DescribeResolver{}.Version(dir, "colors/red")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != "v1.2.0" {
+		t.Fatalf("unexpected version: %s", version)
+	}
+}
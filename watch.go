@@ -0,0 +1,101 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// watchInterval is the delay between -watch polls, and watchQuiet the time
+// files must stay unchanged before rescanning.
+const (
+	watchInterval = 500 * time.Millisecond
+	watchQuiet    = time.Second
+)
+
+// watchedNames are the module files whose changes trigger -watch rescans.
+// go mod vendor rewrites vendor/modules.txt, so vendored modules changes are
+// noticed without walking the vendor directory.
+var watchedNames = []string{"go.mod", "go.sum", "go.work", "go.work.sum",
+	filepath.Join("vendor", "modules.txt")}
+
+// fileState identifies a file version, by modification time and size.
+type fileState struct {
+	ModTime time.Time
+	Size    int64
+}
+
+// moduleRoot returns the root directory of the module containing dir, from
+// go env GOMOD, or dir itself outside modules.
+func moduleRoot(env *GoEnv, dir string) (string, error) {
+	dirEnv := *env
+	dirEnv.Dir = dir
+	gomod, err := goOutput(&dirEnv, "env", "GOMOD")
+	if err != nil {
+		return "", err
+	}
+	if gomod == "" || gomod == os.DevNull {
+		return dir, nil
+	}
+	return filepath.Dir(gomod), nil
+}
+
+// snapshotFiles returns the state of the module files of dirs, by path.
+// Missing files are left out, so their creation or removal is a change as
+// well. Neither the vendor directory nor the module cache are walked.
+func snapshotFiles(dirs []string) (map[string]fileState, error) {
+	states := map[string]fileState{}
+	for _, dir := range dirs {
+		for _, name := range watchedNames {
+			path := filepath.Join(dir, name)
+			fi, err := os.Stat(path)
+			if err == nil {
+				states[path] = fileState{fi.ModTime(), fi.Size()}
+			} else if !os.IsNotExist(err) {
+				return nil, err
+			}
+		}
+	}
+	return states, nil
+}
+
+// sameFiles returns true if both snapshots hold the same file states.
+func sameFiles(a, b map[string]fileState) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for path, state := range a {
+		if other, ok := b[path]; !ok || !other.ModTime.Equal(state.ModTime) ||
+			other.Size != state.Size {
+			return false
+		}
+	}
+	return true
+}
+
+// waitForChanges polls the files of dirs, see snapshotFiles, every interval
+// and returns once they differ from snapshot last and then stayed unchanged
+// for quiet, so bursts of changes, like go mod vendor runs, trigger a single
+// rescan. Taking last before scanning catches changes made during the scan.
+// Polling keeps the tool free of platform specific notification dependencies,
+// at a small latency cost.
+func waitForChanges(dirs []string, last map[string]fileState, interval,
+	quiet time.Duration) error {
+
+	var changedAt time.Time
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		current, err := snapshotFiles(dirs)
+		if err != nil {
+			return err
+		}
+		if !sameFiles(last, current) {
+			last = current
+			changedAt = now
+		} else if !changedAt.IsZero() && now.Sub(changedAt) >= quiet {
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSnapshotFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "licenses")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	before, err := snapshotFiles([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vendored := filepath.Join(dir, "vendor", "example.com", "lib")
+	err = os.MkdirAll(vendored, 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{
+		filepath.Join(dir, "go.mod"),
+		filepath.Join(dir, "main.go"),
+		filepath.Join(dir, "vendor", "modules.txt"),
+		// Vendored files are not walked, modules.txt tells their changes.
+		filepath.Join(vendored, "LICENSE"),
+	} {
+		err = ioutil.WriteFile(path, []byte("content"), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	after, err := snapshotFiles([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sameFiles(before, after) || len(after) != 2 {
+		t.Fatalf("unexpected snapshots: %v, %v", before, after)
+	}
+}
+
+func TestWaitForChanges(t *testing.T) {
+	dir, err := ioutil.TempDir("", "licenses")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	last, err := snapshotFiles([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error)
+	go func() {
+		done <- waitForChanges([]string{dir}, last, 5*time.Millisecond,
+			50*time.Millisecond)
+	}()
+	time.Sleep(20 * time.Millisecond)
+	written := time.Now()
+	for i := 0; i < 3; i++ {
+		err = ioutil.WriteFile(filepath.Join(dir, "go.sum"), make([]byte, i), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+		if time.Since(written) < 50*time.Millisecond {
+			t.Fatal("changes were not debounced")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("changes were not detected")
+	}
+}
+
+func TestChangesBeforeWaiting(t *testing.T) {
+	dir, err := ioutil.TempDir("", "licenses")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	last, err := snapshotFiles([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Files changed during the scan, after the snapshot, trigger a rescan.
+	err = ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte("module m\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error)
+	go func() {
+		done <- waitForChanges([]string{dir}, last, 5*time.Millisecond,
+			20*time.Millisecond)
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("changes before waiting were not detected")
+	}
+}
+
+func TestModuleRoot(t *testing.T) {
+	t.Setenv("GO111MODULE", "on")
+	dir, err := ioutil.TempDir("", "licenses")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	sub := filepath.Join(dir, "cmd", "tool")
+	err = os.MkdirAll(sub, 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/m\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := moduleRoot(&GoEnv{}, sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected, _ := filepath.EvalSymlinks(dir); root != dir && root != expected {
+		t.Fatalf("unexpected module root: %s", root)
+	}
+	outside, err := ioutil.TempDir("", "licenses")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(outside)
+	root, err = moduleRoot(&GoEnv{}, outside)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root != outside {
+		t.Fatalf("unexpected root outside modules: %s", root)
+	}
+}
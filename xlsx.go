@@ -0,0 +1,240 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// The xlsx writer below produces the minimal set of Office Open XML parts
+// spreadsheet applications require: content types, package and workbook
+// relationships, a workbook, a stylesheet and a single worksheet using inline
+// strings, so no shared strings table is needed.
+
+const xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>
+</Types>
+`
+
+const xlsxRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>
+`
+
+const xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>
+</Relationships>
+`
+
+// xlsxStyles defines the cell formats referenced by the xlsx* style indexes
+// below. Fills use the light green, yellow and red of spreadsheet conditional
+// formatting presets.
+const xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>
+<fills count="6">
+<fill><patternFill patternType="none"/></fill>
+<fill><patternFill patternType="gray125"/></fill>
+<fill><patternFill patternType="solid"><fgColor rgb="FFD9D9D9"/><bgColor indexed="64"/></patternFill></fill>
+<fill><patternFill patternType="solid"><fgColor rgb="FFC6EFCE"/><bgColor indexed="64"/></patternFill></fill>
+<fill><patternFill patternType="solid"><fgColor rgb="FFFFEB9C"/><bgColor indexed="64"/></patternFill></fill>
+<fill><patternFill patternType="solid"><fgColor rgb="FFFFC7CE"/><bgColor indexed="64"/></patternFill></fill>
+</fills>
+<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>
+<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>
+<cellXfs count="6">
+<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>
+<xf numFmtId="0" fontId="1" fillId="2" borderId="0" xfId="0" applyFont="1" applyFill="1"/>
+<xf numFmtId="0" fontId="0" fillId="3" borderId="0" xfId="0" applyFill="1"/>
+<xf numFmtId="0" fontId="0" fillId="4" borderId="0" xfId="0" applyFill="1"/>
+<xf numFmtId="0" fontId="0" fillId="5" borderId="0" xfId="0" applyFill="1"/>
+<xf numFmtId="9" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>
+</cellXfs>
+<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>
+</styleSheet>
+`
+
+// Cell format indexes of xlsxStyles.
+const (
+	xlsxDefault = iota
+	xlsxHeader
+	xlsxGreen
+	xlsxYellow
+	xlsxRed
+	xlsxPercent
+)
+
+// xlsxColumn returns the letters of the zero based column index, like "A" or
+// "AB".
+func xlsxColumn(index int) string {
+	name := ""
+	for index++; index > 0; index = (index - 1) / 26 {
+		name = string(rune('A'+(index-1)%26)) + name
+	}
+	return name
+}
+
+// xlsxEscape escapes s for XML content. Characters invalid in XML are
+// replaced.
+func xlsxEscape(s string) string {
+	b := bytes.Buffer{}
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// xlsxLicenseStyle returns the license cell format of row, colored like
+// -color terminal output: green for exact matches, yellow for approximate
+// ones and red for the others and policy violations.
+func xlsxLicenseStyle(row Row) int {
+	if row.violation {
+		return xlsxRed
+	}
+	switch row.Status {
+	case StatusExact:
+		return xlsxGreen
+	case StatusApproximate:
+		return xlsxYellow
+	}
+	return xlsxRed
+}
+
+// plainLicense returns the license cell of row without markdown links.
+func plainLicense(row Row) string {
+	if row.URL == "" {
+		return row.License
+	}
+	return row.title + strings.TrimPrefix(row.License, markdownLink(row.title, row.URL))
+}
+
+// writeXLSXSheet writes the worksheet XML of table, with the columns of
+// markdown tables, a frozen header row and an autofilter on every column.
+func writeXLSXSheet(w io.Writer, table Rows, ro ReportOptions) error {
+	type column struct {
+		name  string
+		width int
+		cell  func(row Row) string
+	}
+	columns := []column{}
+	for _, c := range reportColumns(table, ro) {
+		width := 12
+		switch c.name {
+		case "Package", "License", "Words", "Obligations":
+			width = 40
+		}
+		cell := c.cell
+		if c.name == "License" {
+			cell = plainLicense
+		}
+		columns = append(columns, column{c.name, width, cell})
+	}
+	b := &bytes.Buffer{}
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	b.WriteString(`<sheetViews><sheetView workbookViewId="0">`)
+	b.WriteString(`<pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/>`)
+	b.WriteString(`</sheetView></sheetViews><cols>`)
+	for i, c := range columns {
+		fmt.Fprintf(b, `<col min="%d" max="%d" width="%d" customWidth="1"/>`, i+1, i+1, c.width)
+	}
+	b.WriteString(`</cols><sheetData><row r="1">`)
+	for i, c := range columns {
+		fmt.Fprintf(b, `<c r="%s1" t="inlineStr" s="%d"><is><t>%s</t></is></c>`,
+			xlsxColumn(i), xlsxHeader, xlsxEscape(c.name))
+	}
+	b.WriteString(`</row>`)
+	for i, row := range table {
+		r := i + 2
+		fmt.Fprintf(b, `<row r="%d">`, r)
+		for j, c := range columns {
+			ref := fmt.Sprintf("%s%d", xlsxColumn(j), r)
+			switch c.name {
+			case "Match":
+				fmt.Fprintf(b, `<c r="%s" s="%d"><v>%g</v></c>`, ref, xlsxPercent, row.Score)
+				continue
+			case "License":
+				fmt.Fprintf(b, `<c r="%s" t="inlineStr" s="%d">`, ref, xlsxLicenseStyle(row))
+			default:
+				fmt.Fprintf(b, `<c r="%s" t="inlineStr">`, ref)
+			}
+			fmt.Fprintf(b, `<is><t xml:space="preserve">%s</t></is></c>`,
+				xlsxEscape(strings.TrimSpace(c.cell(row))))
+		}
+		b.WriteString(`</row>`)
+	}
+	fmt.Fprintf(b, `</sheetData><autoFilter ref="A1:%s%d"/></worksheet>`,
+		xlsxColumn(len(columns)-1), len(table)+1)
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// writeXLSX writes report rows as an xlsx workbook with a single "Licenses"
+// worksheet, see writeXLSXSheet.
+func writeXLSX(w io.Writer, table Rows, ro ReportOptions) error {
+	sheet := &bytes.Buffer{}
+	err := writeXLSXSheet(sheet, table, ro)
+	if err != nil {
+		return err
+	}
+	columns := len(reportColumns(table, ro))
+	workbook := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Licenses" sheetId="1" r:id="rId1"/></sheets>
+<definedNames><definedName name="_xlnm._FilterDatabase" localSheetId="0" hidden="1">` +
+		fmt.Sprintf("Licenses!$A$1:$%s$%d", xlsxColumn(columns-1), len(table)+1) +
+		`</definedName></definedNames>
+</workbook>
+`
+	parts := []struct {
+		name string
+		data []byte
+	}{
+		{"[Content_Types].xml", []byte(xlsxContentTypes)},
+		{"_rels/.rels", []byte(xlsxRels)},
+		{"xl/workbook.xml", []byte(workbook)},
+		{"xl/_rels/workbook.xml.rels", []byte(xlsxWorkbookRels)},
+		{"xl/styles.xml", []byte(xlsxStyles)},
+		{"xl/worksheets/sheet1.xml", sheet.Bytes()},
+	}
+	zw := zip.NewWriter(w)
+	for _, p := range parts {
+		f, err := zw.CreateHeader(&zip.FileHeader{Name: p.name, Method: zip.Deflate})
+		if err != nil {
+			return err
+		}
+		_, err = f.Write(p.data)
+		if err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// generateXLSXReport writes licenses as an xlsx workbook in the report file.
+func generateXLSXReport(report string, licenses []License, ro ReportOptions) error {
+	table, err := reportRows(licenses, ro)
+	if err != nil {
+		return err
+	}
+	out, err := os.Create(report)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	err = writeXLSX(out, table, ro)
+	if err != nil {
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestXLSXColumn(t *testing.T) {
+	tests := map[int]string{0: "A", 1: "B", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"}
+	for index, name := range tests {
+		if got := xlsxColumn(index); got != name {
+			t.Errorf("%d: expected %s, got %s", index, name, got)
+		}
+	}
+}
+
+func TestWriteXLSX(t *testing.T) {
+	mit := &Template{Title: "MIT License", SPDX: "MIT"}
+	licenses := []License{
+		{Package: "a", Template: mit, Score: 1, Status: StatusExact},
+		{Package: "b", Template: mit, Score: 0.95, Status: StatusApproximate},
+		{Package: "c", Template: mit, Score: 1, Status: StatusExact, Violation: "denied"},
+		{Package: "d <&>", Status: StatusMissing},
+	}
+	table, err := reportRows(licenses, ReportOptions{SortBy: "package"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := &bytes.Buffer{}
+	err = writeXLSX(out, table, ReportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := zip.NewReader(bytes.NewReader(out.Bytes()), int64(out.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := map[string][]byte{}
+	for _, f := range r.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var v interface{}
+		if err := xml.Unmarshal(data, &v); err != nil {
+			t.Fatalf("%s is not valid XML: %s", f.Name, err)
+		}
+		parts[f.Name] = data
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml",
+		"xl/_rels/workbook.xml.rels", "xl/styles.xml", "xl/worksheets/sheet1.xml"} {
+		if parts[name] == nil {
+			t.Fatalf("missing %s part", name)
+		}
+	}
+
+	var sheet struct {
+		Pane struct {
+			YSplit string `xml:"ySplit,attr"`
+			State  string `xml:"state,attr"`
+		} `xml:"sheetViews>sheetView>pane"`
+		Rows []struct {
+			Cells []struct {
+				Ref   string `xml:"r,attr"`
+				Style int    `xml:"s,attr"`
+				Text  string `xml:"is>t"`
+				Value string `xml:"v"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+		AutoFilter struct {
+			Ref string `xml:"ref,attr"`
+		} `xml:"autoFilter"`
+	}
+	err = xml.Unmarshal(parts["xl/worksheets/sheet1.xml"], &sheet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sheet.Pane.YSplit != "1" || sheet.Pane.State != "frozen" {
+		t.Fatalf("header row is not frozen: %+v", sheet.Pane)
+	}
+	if sheet.AutoFilter.Ref != "A1:C5" {
+		t.Fatalf("unexpected autofilter range: %s", sheet.AutoFilter.Ref)
+	}
+	if len(sheet.Rows) != 5 {
+		t.Fatalf("expected 5 rows, got %d", len(sheet.Rows))
+	}
+	header := []string{}
+	for _, c := range sheet.Rows[0].Cells {
+		header = append(header, c.Text)
+		if c.Style != xlsxHeader {
+			t.Fatalf("header cell %s has style %d", c.Ref, c.Style)
+		}
+	}
+	if strings.Join(header, ",") != "Package,License,Match" {
+		t.Fatalf("unexpected header: %v", header)
+	}
+	styles := []int{xlsxGreen, xlsxYellow, xlsxRed, xlsxRed}
+	for i, row := range sheet.Rows[1:] {
+		license, match := row.Cells[1], row.Cells[2]
+		if license.Style != styles[i] {
+			t.Errorf("%s: expected style %d, got %d", row.Cells[0].Text, styles[i],
+				license.Style)
+		}
+		if strings.Contains(license.Text, "](") {
+			t.Errorf("%s: license has a markdown link: %s", row.Cells[0].Text, license.Text)
+		}
+		if match.Style != xlsxPercent || match.Value == "" {
+			t.Errorf("%s: unexpected match cell: %+v", row.Cells[0].Text, match)
+		}
+	}
+	if sheet.Rows[1].Cells[1].Text != "MIT License" || sheet.Rows[4].Cells[0].Text != "d <&>" {
+		t.Fatalf("unexpected cells: %+v", sheet.Rows)
+	}
+}
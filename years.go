@@ -0,0 +1,32 @@
+package main
+
+import (
+	"regexp"
+)
+
+// reYear matches standalone years, like "2007" in "as of 2007". Years are
+// also dropped with copyright lines, see reCopyright, this covers the ones
+// mentioned elsewhere.
+var reYear = regexp.MustCompile(`\b(?:19|20)\d\d\b`)
+
+// yearWord replaces years when Options.NormalizeYears is set. It is unlikely
+// to appear in license texts.
+var yearWord = []byte("yyyy")
+
+// normalizeYears returns data with standalone years replaced by yearWord, so
+// licenses and templates mentioning different years have the same words.
+func normalizeYears(data []byte) []byte {
+	return reYear.ReplaceAll(data, yearWord)
+}
+
+// normalizeTemplateYears applies normalizeYears to templates texts. It must
+// be called before their words are computed, right after loadTemplates.
+func normalizeTemplateYears(templates []*Template) {
+	for _, t := range templates {
+		for _, v := range []*Template{t, t.official} {
+			if v != nil && v.text != nil {
+				v.text = normalizeYears(v.text)
+			}
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestNormalizeYears(t *testing.T) {
+	got := string(normalizeYears([]byte("as of 2007, 1999-2021 but not 12007, 2007a or 1887")))
+	if got != "as of yyyy, yyyy-yyyy but not 12007, 2007a or 1887" {
+		t.Fatalf("unexpected normalized text: %s", got)
+	}
+}
+
+func TestYearEdits(t *testing.T) {
+	// colors/amber is colors/silver Apache license dated 2014 instead of
+	// 2004.
+	err := compareTestLicenses([]string{"colors/amber"}, []testResult{
+		{Package: "colors/amber", License: "Apache License 2.0", Score: 99, Extra: 1, Missing: 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := Options{NormalizeYears: true}
+	err = compareTestLicensesWithOptions([]string{"colors/amber", "colors/silver"}, opts,
+		[]testResult{
+			{Package: "colors/amber", License: "Apache License 2.0", Score: 100},
+			{Package: "colors/silver", License: "Apache License 2.0", Score: 100},
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+}